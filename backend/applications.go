@@ -0,0 +1,768 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// MODELS - RENTAL APPLICATIONS
+// ============================================================================
+
+type RentalApplication struct {
+	ID                  int        `json:"id"`
+	PropertyID          int        `json:"propertyId"`
+	Status              string     `json:"status"`
+	FirstName           string     `json:"firstName"`
+	LastName            string     `json:"lastName"`
+	Email               string     `json:"email"`
+	Phone               string     `json:"phone"`
+	DateOfBirth         *string    `json:"dateOfBirth,omitempty"`
+	CurrentAddress      *string    `json:"currentAddress,omitempty"`
+	MonthlyIncome       float64    `json:"monthlyIncome"`
+	EmployerName        *string    `json:"employerName,omitempty"`
+	JobTitle            *string    `json:"jobTitle,omitempty"`
+	EmploymentStartDate *string    `json:"employmentStartDate,omitempty"`
+	DesiredMoveIn       string     `json:"desiredMoveIn"`
+	Message             *string    `json:"message,omitempty"`
+	AdminNotes          *string    `json:"adminNotes,omitempty"`
+	TenantID            *int       `json:"tenantId,omitempty"`
+	LeaseID             *int       `json:"leaseId,omitempty"`
+	ReviewedAt          *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	UpdatedAt           time.Time  `json:"updatedAt"`
+
+	ApplicationFee float64          `json:"applicationFee"`
+	FeePaidAt      *time.Time       `json:"feePaidAt,omitempty"`
+	FeeReference   *string          `json:"feeReference,omitempty"`
+	Screening      *ScreeningResult `json:"screening,omitempty"`
+
+	CoApplicants []CoApplicant          `json:"coApplicants"`
+	References   []ApplicationReference `json:"references"`
+	// Joined fields
+	PropertyName *string `json:"propertyName,omitempty"`
+}
+
+type CoApplicant struct {
+	ID            int     `json:"id"`
+	FirstName     string  `json:"firstName"`
+	LastName      string  `json:"lastName"`
+	Email         *string `json:"email,omitempty"`
+	Phone         *string `json:"phone,omitempty"`
+	Relationship  *string `json:"relationship,omitempty"`
+	MonthlyIncome float64 `json:"monthlyIncome"`
+}
+
+type ApplicationReference struct {
+	ID           int     `json:"id"`
+	Name         string  `json:"name"`
+	Relationship *string `json:"relationship,omitempty"`
+	Phone        *string `json:"phone,omitempty"`
+	Email        *string `json:"email,omitempty"`
+}
+
+// ConvertApplicationResponse is returned when an approved application
+// becomes a tenant and a draft lease.
+type ConvertApplicationResponse struct {
+	Application RentalApplication `json:"application"`
+	Tenant      Tenant            `json:"tenant"`
+	Lease       Lease             `json:"lease"`
+}
+
+var applicationStatuses = map[string]bool{
+	"submitted": true,
+	"screening": true,
+	"approved":  true,
+	"denied":    true,
+}
+
+// draftLeaseMonths is the default term of a lease created from an application.
+const draftLeaseMonths = 12
+
+// maxMonthlyIncome bounds each stated income. Anything larger is a typo or a
+// probe, and would overflow the DECIMAL(10,2) income columns.
+const maxMonthlyIncome = 1_000_000
+
+// applicationLimiter throttles public application submissions per client
+// IP, sharing the contact form's limits.
+var applicationLimiter = newRateLimiter()
+
+// tooLong reports whether s has more characters than a VARCHAR(max) column
+// holds; MySQL strict mode rejects the insert rather than truncating.
+func tooLong(s *string, max int) bool {
+	return s != nil && utf8.RuneCountInString(*s) > max
+}
+
+// validDate accepts an optional YYYY-MM-DD.
+func validDate(s *string) bool {
+	if s == nil || *s == "" {
+		return true
+	}
+	_, err := time.Parse("2006-01-02", *s)
+	return err == nil
+}
+
+// validateApplication checks a submitted application against the columns it
+// is stored in and returns a client-facing message, or "" when it is valid.
+func validateApplication(app *RentalApplication) string {
+	if app.FirstName == "" || app.LastName == "" || app.Email == "" || app.Phone == "" || app.DesiredMoveIn == "" {
+		return "First name, last name, email, phone, and desired move-in date are required"
+	}
+	if _, err := mail.ParseAddress(app.Email); err != nil {
+		return "Invalid email address"
+	}
+	if _, err := time.Parse("2006-01-02", app.DesiredMoveIn); err != nil || !validDate(app.DateOfBirth) || !validDate(app.EmploymentStartDate) {
+		return "Invalid date format (use YYYY-MM-DD)"
+	}
+	if app.MonthlyIncome < 0 || app.MonthlyIncome > maxMonthlyIncome {
+		return fmt.Sprintf("Monthly income must be between 0 and %d", maxMonthlyIncome)
+	}
+	if tooLong(&app.FirstName, 100) || tooLong(&app.LastName, 100) || tooLong(&app.Email, 255) ||
+		tooLong(app.CurrentAddress, 500) || tooLong(app.EmployerName, 255) || tooLong(app.JobTitle, 255) ||
+		tooLong(app.Message, 5000) {
+		return "Names may be at most 100 characters, email, employer and job title 255, address 500 and message 5000"
+	}
+	if tooLong(&app.Phone, 20) {
+		return "Phone numbers may be at most 20 characters"
+	}
+	for _, c := range app.CoApplicants {
+		if strings.TrimSpace(c.FirstName) == "" || strings.TrimSpace(c.LastName) == "" {
+			return "Co-applicants need a first and last name"
+		}
+		if c.MonthlyIncome < 0 || c.MonthlyIncome > maxMonthlyIncome {
+			return fmt.Sprintf("Monthly income must be between 0 and %d", maxMonthlyIncome)
+		}
+		if tooLong(&c.FirstName, 100) || tooLong(&c.LastName, 100) || tooLong(c.Email, 255) ||
+			tooLong(c.Phone, 20) || tooLong(c.Relationship, 100) {
+			return "Co-applicant names and relationships may be at most 100 characters, email 255 and phone 20"
+		}
+	}
+	for _, ref := range app.References {
+		if strings.TrimSpace(ref.Name) == "" {
+			return "References need a name"
+		}
+		if tooLong(&ref.Name, 200) || tooLong(ref.Relationship, 100) || tooLong(ref.Email, 255) || tooLong(ref.Phone, 20) {
+			return "Reference names may be at most 200 characters, relationship 100, email 255 and phone 20"
+		}
+	}
+	return ""
+}
+
+// ============================================================================
+// HANDLERS - PUBLIC APPLICATIONS
+// ============================================================================
+
+func submitApplication(w http.ResponseWriter, r *http.Request, propertyID int) {
+	if r.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ip := clientIP(r)
+	if ok, retry := applicationLimiter.allow(ip, cfg.Contact.RateLimit, cfg.Contact.RateWindow, time.Now()); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+		jsonError(w, "Too many applications, please try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	// Website is a honeypot, as on the contact form.
+	var body struct {
+		RentalApplication
+		Website string `json:"website"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	app := body.RentalApplication
+	// Bots get the same answer as people so they have nothing to tune against.
+	if body.Website != "" {
+		log.Printf("Application form honeypot tripped from %s", ip)
+		jsonResponse(w, submittedApplication(app, propertyID), http.StatusCreated)
+		return
+	}
+
+	app.FirstName = strings.TrimSpace(app.FirstName)
+	app.LastName = strings.TrimSpace(app.LastName)
+	app.Email = strings.TrimSpace(app.Email)
+	app.Phone = strings.TrimSpace(app.Phone)
+	if msg := validateApplication(&app); msg != "" {
+		jsonError(w, msg, http.StatusBadRequest)
+		return
+	}
+	var available bool
+	err := db.QueryRow("SELECT available FROM properties WHERE id = ?", propertyID).Scan(&available)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Property not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error checking property for application: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !available {
+		jsonError(w, "This property is not accepting applications", http.StatusConflict)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO rental_applications (property_id, status, first_name, last_name, email, phone,
+			date_of_birth, current_address, monthly_income, employer_name, job_title,
+			employment_start_date, desired_move_in, message, application_fee)
+		VALUES (?, 'submitted', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, propertyID, app.FirstName, app.LastName, app.Email, app.Phone,
+		app.DateOfBirth, app.CurrentAddress, app.MonthlyIncome, app.EmployerName, app.JobTitle,
+		app.EmploymentStartDate, app.DesiredMoveIn, app.Message, cfg.Screening.ApplicationFee)
+	if err != nil {
+		log.Printf("Error creating application: %v", err)
+		jsonError(w, "Failed to submit application", http.StatusInternalServerError)
+		return
+	}
+	id, _ := result.LastInsertId()
+	app.ID = int(id)
+
+	for i, c := range app.CoApplicants {
+		result, err := tx.Exec(`
+			INSERT INTO application_co_applicants (application_id, first_name, last_name, email,
+				phone, relationship, monthly_income)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, app.ID, c.FirstName, c.LastName, c.Email, c.Phone, c.Relationship, c.MonthlyIncome)
+		if err != nil {
+			log.Printf("Error creating co-applicant: %v", err)
+			jsonError(w, "Failed to submit application", http.StatusInternalServerError)
+			return
+		}
+		cid, _ := result.LastInsertId()
+		app.CoApplicants[i].ID = int(cid)
+	}
+	for i, ref := range app.References {
+		result, err := tx.Exec(`
+			INSERT INTO application_references (application_id, name, relationship, phone, email)
+			VALUES (?, ?, ?, ?, ?)
+		`, app.ID, ref.Name, ref.Relationship, ref.Phone, ref.Email)
+		if err != nil {
+			log.Printf("Error creating application reference: %v", err)
+			jsonError(w, "Failed to submit application", http.StatusInternalServerError)
+			return
+		}
+		rid, _ := result.LastInsertId()
+		app.References[i].ID = int(rid)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing application: %v", err)
+		jsonError(w, "Failed to submit application", http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, submittedApplication(app, propertyID), http.StatusCreated)
+}
+
+// submittedApplication is the applicant's copy of what they sent. Admin-only
+// fields are never echoed back.
+func submittedApplication(app RentalApplication, propertyID int) RentalApplication {
+	app.PropertyID = propertyID
+	app.Status = "submitted"
+	app.AdminNotes = nil
+	app.TenantID = nil
+	app.LeaseID = nil
+	app.ReviewedAt = nil
+	app.ApplicationFee = cfg.Screening.ApplicationFee
+	app.FeePaidAt = nil
+	app.FeeReference = nil
+	app.Screening = nil
+	if app.CoApplicants == nil {
+		app.CoApplicants = []CoApplicant{}
+	}
+	if app.References == nil {
+		app.References = []ApplicationReference{}
+	}
+	app.CreatedAt = time.Now()
+	app.UpdatedAt = time.Now()
+	return app
+}
+
+// ============================================================================
+// HANDLERS - ADMIN APPLICATIONS
+// ============================================================================
+
+const applicationColumns = `
+	a.id, a.property_id, a.status, a.first_name, a.last_name, a.email, a.phone,
+	a.date_of_birth, a.current_address, a.monthly_income, a.employer_name, a.job_title,
+	a.employment_start_date, a.desired_move_in, a.message, a.admin_notes,
+	a.tenant_id, a.lease_id, a.reviewed_at, a.created_at, a.updated_at,
+	a.application_fee, a.fee_paid_at, a.fee_reference,
+	a.screening_provider, a.screening_report_id, a.credit_score, a.eviction_records,
+	a.criminal_records, a.income_to_rent, a.recommendation, a.recommendation_reasons, a.screened_at,
+	p.name as property_name`
+
+func adminApplicationsHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := `SELECT ` + applicationColumns + `
+		FROM rental_applications a
+		JOIN properties p ON a.property_id = p.id
+		WHERE 1=1
+	`
+	args := []interface{}{}
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		query += " AND a.status = ?"
+		args = append(args, status)
+	}
+	if propertyID := r.URL.Query().Get("propertyId"); propertyID != "" {
+		if id, err := strconv.Atoi(propertyID); err == nil {
+			query += " AND a.property_id = ?"
+			args = append(args, id)
+		}
+	}
+
+	clause, ownerArgs, err := ownerFilter(r.URL.Query().Get("ownerId"), "a.property_id")
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query += clause
+	args = append(args, ownerArgs...)
+
+	// Oldest first so the queue is worked in the order applications arrived.
+	query += " ORDER BY a.created_at ASC, a.id ASC"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("Error querying applications: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	apps := []RentalApplication{}
+	for rows.Next() {
+		app, err := scanApplication(rows)
+		if err != nil {
+			log.Printf("Error scanning application: %v", err)
+			continue
+		}
+		apps = append(apps, app)
+	}
+
+	jsonResponse(w, apps, http.StatusOK)
+}
+
+func adminApplicationByIDHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+
+	id, action, err := extractIDAndAction(r.URL.Path, "/api/admin/applications/")
+	if err != nil {
+		jsonError(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+
+	switch action {
+	case "":
+	case "convert", "fee", "screen":
+		if r.Method != http.MethodPost {
+			jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		switch action {
+		case "convert":
+			convertApplication(w, r, id)
+		case "fee":
+			recordApplicationFee(w, r, id)
+		case "screen":
+			screenApplication(w, r, id)
+		}
+		return
+	default:
+		jsonError(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		getApplicationByID(w, id)
+	case http.MethodPut:
+		updateApplication(w, r, id)
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func getApplicationByID(w http.ResponseWriter, id int) {
+	app, err := loadApplication(id)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Application not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting application: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, app, http.StatusOK)
+}
+
+func updateApplication(w http.ResponseWriter, r *http.Request, id int) {
+	var body struct {
+		Status     string  `json:"status"`
+		AdminNotes *string `json:"adminNotes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if !applicationStatuses[body.Status] {
+		jsonError(w, "Status must be one of submitted, screening, approved, denied", http.StatusBadRequest)
+		return
+	}
+
+	var current string
+	var leaseID sql.NullInt64
+	err := db.QueryRow("SELECT status, lease_id FROM rental_applications WHERE id = ?", id).Scan(&current, &leaseID)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Application not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if leaseID.Valid && body.Status != current {
+		jsonError(w, "Application has already been converted to a lease", http.StatusConflict)
+		return
+	}
+
+	// reviewed_at records the first decision; later note edits keep it.
+	_, err = db.Exec(`
+		UPDATE rental_applications
+		SET status = ?, admin_notes = ?,
+			reviewed_at = CASE WHEN ? IN ('approved', 'denied') AND reviewed_at IS NULL THEN ? ELSE reviewed_at END
+		WHERE id = ?
+	`, body.Status, body.AdminNotes, body.Status, time.Now(), id)
+	if err != nil {
+		log.Printf("Error updating application: %v", err)
+		jsonError(w, "Failed to update application", http.StatusInternalServerError)
+		return
+	}
+
+	getApplicationByID(w, id)
+}
+
+// convertApplication turns an approved application into a tenant and a draft
+// lease for the applied-for property. An existing tenant with the same email
+// is reused so returning renters keep their history and portal login. The
+// optional body {"unitId": n} picks the unit in a multi-unit building.
+func convertApplication(w http.ResponseWriter, r *http.Request, id int) {
+	var body struct {
+		UnitID *int `json:"unitId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	app, err := loadApplication(id)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Application not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading application: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if app.Status != "approved" {
+		jsonError(w, "Only approved applications can be converted", http.StatusConflict)
+		return
+	}
+	if app.LeaseID != nil {
+		jsonError(w, "Application has already been converted to a lease", http.StatusConflict)
+		return
+	}
+
+	start, err := time.Parse("2006-01-02", dateOnly(app.DesiredMoveIn))
+	if err != nil {
+		jsonError(w, "Application has an invalid move-in date", http.StatusConflict)
+		return
+	}
+
+	unit, err := resolveUnit(app.PropertyID, body.UnitID)
+	if err != nil {
+		writeUnitError(w, err)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// A returning applicant may be in the trash; approving them brings the
+	// tenant record back rather than tripping over the unique email.
+	var tenant Tenant
+	var deletedAt sql.NullTime
+	err = tx.QueryRow("SELECT id, deleted_at FROM tenants WHERE email = ?", app.Email).Scan(&tenant.ID, &deletedAt)
+	if err == nil && deletedAt.Valid {
+		_, err = tx.Exec("UPDATE tenants SET deleted_at = NULL WHERE id = ?", tenant.ID)
+	}
+	if err == sql.ErrNoRows {
+		result, err := tx.Exec(`
+			INSERT INTO tenants (first_name, last_name, email, phone, date_of_birth, notes)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, app.FirstName, app.LastName, app.Email, app.Phone, app.DateOfBirth,
+			"Created from rental application #"+strconv.Itoa(app.ID))
+		if err != nil {
+			log.Printf("Error creating tenant from application: %v", err)
+			jsonError(w, "Failed to create tenant", http.StatusInternalServerError)
+			return
+		}
+		tid, _ := result.LastInsertId()
+		tenant.ID = int(tid)
+	} else if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	l := Lease{
+		PropertyID:    app.PropertyID,
+		UnitID:        &unit.ID,
+		TenantID:      tenant.ID,
+		StartDate:     start.Format("2006-01-02"),
+		EndDate:       start.AddDate(0, draftLeaseMonths, -1).Format("2006-01-02"),
+		MonthlyRent:   unit.MonthlyRent,
+		DepositAmount: unit.DepositAmount,
+		Status:        "draft",
+		PaymentDueDay: 1,
+	}
+	l.UnitNumber = &unit.UnitNumber
+	notes := "Draft created from rental application #" + strconv.Itoa(app.ID)
+	l.Notes = &notes
+
+	result, err := tx.Exec(`
+		INSERT INTO leases (property_id, unit_id, tenant_id, start_date, end_date, monthly_rent,
+			deposit_amount, status, payment_due_day, notes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, l.PropertyID, l.UnitID, l.TenantID, l.StartDate, l.EndDate, l.MonthlyRent,
+		l.DepositAmount, l.Status, l.PaymentDueDay, l.Notes)
+	if err != nil {
+		log.Printf("Error creating draft lease: %v", err)
+		jsonError(w, "Failed to create lease", http.StatusInternalServerError)
+		return
+	}
+	lid, _ := result.LastInsertId()
+	l.ID = int(lid)
+
+	if _, err := tx.Exec("UPDATE rental_applications SET tenant_id = ?, lease_id = ? WHERE id = ?", tenant.ID, l.ID, app.ID); err != nil {
+		log.Printf("Error linking application: %v", err)
+		jsonError(w, "Failed to convert application", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing conversion: %v", err)
+		jsonError(w, "Failed to convert application", http.StatusInternalServerError)
+		return
+	}
+
+	tenant, err = scanTenantRow(db.QueryRow(`
+		SELECT id, first_name, last_name, email, phone, date_of_birth,
+			   emergency_contact_name, emergency_contact_phone, notes,
+			   created_at, updated_at
+		FROM tenants WHERE id = ?
+	`, tenant.ID))
+	if err != nil {
+		log.Printf("Error reloading tenant: %v", err)
+	}
+	app.TenantID = &tenant.ID
+	app.LeaseID = &l.ID
+	l.CreatedAt = time.Now()
+	l.UpdatedAt = time.Now()
+
+	jsonResponse(w, ConvertApplicationResponse{Application: app, Tenant: tenant, Lease: l}, http.StatusCreated)
+}
+
+// ============================================================================
+// SCAN HELPERS - APPLICATIONS
+// ============================================================================
+
+// loadApplication reads one application with its co-applicants and references.
+func loadApplication(id int) (RentalApplication, error) {
+	rows, err := db.Query(`SELECT `+applicationColumns+`
+		FROM rental_applications a
+		JOIN properties p ON a.property_id = p.id
+		WHERE a.id = ?
+	`, id)
+	if err != nil {
+		return RentalApplication{}, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return RentalApplication{}, err
+		}
+		return RentalApplication{}, sql.ErrNoRows
+	}
+	app, err := scanApplication(rows)
+	if err != nil {
+		return app, err
+	}
+	rows.Close()
+
+	coRows, err := db.Query(`
+		SELECT id, first_name, last_name, email, phone, relationship, monthly_income
+		FROM application_co_applicants WHERE application_id = ? ORDER BY id
+	`, id)
+	if err != nil {
+		return app, err
+	}
+	defer coRows.Close()
+	for coRows.Next() {
+		var c CoApplicant
+		var email, phone, relationship sql.NullString
+		if err := coRows.Scan(&c.ID, &c.FirstName, &c.LastName, &email, &phone, &relationship, &c.MonthlyIncome); err != nil {
+			return app, err
+		}
+		c.Email = nullStringPtr(email)
+		c.Phone = nullStringPtr(phone)
+		c.Relationship = nullStringPtr(relationship)
+		app.CoApplicants = append(app.CoApplicants, c)
+	}
+
+	refRows, err := db.Query(`
+		SELECT id, name, relationship, phone, email
+		FROM application_references WHERE application_id = ? ORDER BY id
+	`, id)
+	if err != nil {
+		return app, err
+	}
+	defer refRows.Close()
+	for refRows.Next() {
+		var ref ApplicationReference
+		var relationship, phone, email sql.NullString
+		if err := refRows.Scan(&ref.ID, &ref.Name, &relationship, &phone, &email); err != nil {
+			return app, err
+		}
+		ref.Relationship = nullStringPtr(relationship)
+		ref.Phone = nullStringPtr(phone)
+		ref.Email = nullStringPtr(email)
+		app.References = append(app.References, ref)
+	}
+
+	return app, nil
+}
+
+// scanApplication reads the applicationColumns projection. Co-applicants and
+// references are left empty; loadApplication fills them for detail views.
+func scanApplication(rows *sql.Rows) (RentalApplication, error) {
+	var a RentalApplication
+	var dob, address, employer, jobTitle, employmentStart, message, adminNotes, propertyName sql.NullString
+	var tenantID, leaseID sql.NullInt64
+	var reviewedAt, feePaidAt sql.NullTime
+	var feeReference, provider, reportID, recommendation, reasonsJSON sql.NullString
+	var creditScore, evictions, criminal sql.NullInt64
+	var incomeToRent sql.NullFloat64
+	var screenedAt sql.NullTime
+
+	err := rows.Scan(&a.ID, &a.PropertyID, &a.Status, &a.FirstName, &a.LastName, &a.Email, &a.Phone,
+		&dob, &address, &a.MonthlyIncome, &employer, &jobTitle,
+		&employmentStart, &a.DesiredMoveIn, &message, &adminNotes,
+		&tenantID, &leaseID, &reviewedAt, &a.CreatedAt, &a.UpdatedAt,
+		&a.ApplicationFee, &feePaidAt, &feeReference,
+		&provider, &reportID, &creditScore, &evictions,
+		&criminal, &incomeToRent, &recommendation, &reasonsJSON, &screenedAt,
+		&propertyName)
+	if err != nil {
+		return a, err
+	}
+
+	a.DateOfBirth = nullStringPtr(dob)
+	a.CurrentAddress = nullStringPtr(address)
+	a.EmployerName = nullStringPtr(employer)
+	a.JobTitle = nullStringPtr(jobTitle)
+	a.EmploymentStartDate = nullStringPtr(employmentStart)
+	a.Message = nullStringPtr(message)
+	a.AdminNotes = nullStringPtr(adminNotes)
+	a.PropertyName = nullStringPtr(propertyName)
+	if tenantID.Valid {
+		id := int(tenantID.Int64)
+		a.TenantID = &id
+	}
+	if leaseID.Valid {
+		id := int(leaseID.Int64)
+		a.LeaseID = &id
+	}
+	if reviewedAt.Valid {
+		a.ReviewedAt = &reviewedAt.Time
+	}
+	if feePaidAt.Valid {
+		a.FeePaidAt = &feePaidAt.Time
+	}
+	a.FeeReference = nullStringPtr(feeReference)
+	if screenedAt.Valid {
+		a.Screening = &ScreeningResult{
+			Provider:        provider.String,
+			ReportID:        reportID.String,
+			CreditScore:     int(creditScore.Int64),
+			EvictionRecords: int(evictions.Int64),
+			CriminalRecords: int(criminal.Int64),
+			IncomeToRent:    incomeToRent.Float64,
+			Recommendation:  recommendation.String,
+			Reasons:         []string{},
+			ScreenedAt:      screenedAt.Time,
+		}
+		if reasonsJSON.Valid && reasonsJSON.String != "" {
+			json.Unmarshal([]byte(reasonsJSON.String), &a.Screening.Reasons)
+		}
+	}
+	a.CoApplicants = []CoApplicant{}
+	a.References = []ApplicationReference{}
+
+	return a, nil
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func nullIntPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func validApplication(email string) map[string]interface{} {
+	return map[string]interface{}{
+		"firstName":     "Avery",
+		"lastName":      "Applicant",
+		"email":         email,
+		"phone":         "555-0100",
+		"monthlyIncome": 6000,
+		"employerName":  "Acme Co",
+		"desiredMoveIn": day(30),
+		"coApplicants": []map[string]interface{}{
+			{"firstName": "Sam", "lastName": "Applicant", "relationship": "spouse", "monthlyIncome": 2500},
+		},
+		"references": []map[string]interface{}{
+			{"name": "Pat Landlord", "relationship": "previous landlord", "phone": "555-0199"},
+		},
+	}
+}
+
+func (e *testEnv) submitApplication(propertyID int, email string) RentalApplication {
+	e.t.Helper()
+	rec := e.do(http.MethodPost, fmt.Sprintf("/api/properties/%d/applications", propertyID), "", validApplication(email))
+	e.expect(rec, http.StatusCreated)
+	return decode[RentalApplication](e.t, rec)
+}
+
+func TestSubmitApplicationValidation(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Apply Here", 1800)
+	path := fmt.Sprintf("/api/properties/%d/applications", pid)
+	cfg.Contact.RateLimit = 100 // every bad submission counts against it
+
+	e.expect(e.do(http.MethodPost, path, "", `{`), http.StatusBadRequest)
+	e.expect(e.do(http.MethodPost, path, "", map[string]string{"firstName": "A"}), http.StatusBadRequest)
+
+	bad := validApplication("not-an-email")
+	e.expect(e.do(http.MethodPost, path, "", bad), http.StatusBadRequest)
+
+	bad = validApplication("date@example.com")
+	bad["desiredMoveIn"] = "next week"
+	e.expect(e.do(http.MethodPost, path, "", bad), http.StatusBadRequest)
+
+	// Values that would overflow their columns are a 400, not a 500 from
+	// MySQL strict mode.
+	for field, value := range map[string]interface{}{
+		"phone":         strings.Repeat("5", 21),
+		"firstName":     strings.Repeat("a", 101),
+		"message":       strings.Repeat("m", 5001),
+		"monthlyIncome": 5e9,
+		"dateOfBirth":   "1990-13-45",
+	} {
+		bad = validApplication("long@example.com")
+		bad[field] = value
+		e.expect(e.do(http.MethodPost, path, "", bad), http.StatusBadRequest)
+	}
+	bad = validApplication("co@example.com")
+	bad["coApplicants"] = []map[string]interface{}{{"firstName": "Sam", "lastName": "A", "phone": strings.Repeat("5", 21)}}
+	e.expect(e.do(http.MethodPost, path, "", bad), http.StatusBadRequest)
+	bad["coApplicants"] = []map[string]interface{}{{"firstName": "Sam", "lastName": "A", "monthlyIncome": 5e9}}
+	e.expect(e.do(http.MethodPost, path, "", bad), http.StatusBadRequest)
+
+	e.expect(e.do(http.MethodPost, "/api/properties/999/applications", "", validApplication("x@example.com")), http.StatusNotFound)
+	e.expect(e.do(http.MethodGet, path, "", nil), http.StatusMethodNotAllowed)
+	e.expect(e.do(http.MethodGet, fmt.Sprintf("/api/properties/%d/unknown", pid), "", nil), http.StatusNotFound)
+
+	// Unavailable properties do not take applications.
+	db.Exec("UPDATE properties SET available = FALSE WHERE id = ?", pid)
+	e.expect(e.do(http.MethodPost, path, "", validApplication("late@example.com")), http.StatusConflict)
+}
+
+func TestApplicationHoneypotAndRateLimit(t *testing.T) {
+	e := newTestEnv(t)
+	pid := e.createProperty(e.adminToken(), "Apply Here", 1800)
+	path := fmt.Sprintf("/api/properties/%d/applications", pid)
+
+	bot := validApplication("bot@example.com")
+	bot["website"] = "http://spam.example"
+	// Indistinguishable from success, but nothing is stored.
+	e.expect(e.do(http.MethodPost, path, "", bot), http.StatusCreated)
+	var n int
+	db.QueryRow("SELECT COUNT(*) FROM rental_applications").Scan(&n)
+	if n != 0 {
+		t.Fatalf("honeypot submission was stored")
+	}
+
+	cfg.Contact.RateLimit = 2
+	applicationLimiter = newRateLimiter()
+	e.submitApplication(pid, "a@example.com")
+	e.submitApplication(pid, "b@example.com")
+	rec := e.do(http.MethodPost, path, "", validApplication("c@example.com"))
+	e.expect(rec, http.StatusTooManyRequests)
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("expected Retry-After on 429")
+	}
+}
+
+func TestApplicationReviewAndConvert(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Convert Place", 1800)
+
+	app := e.submitApplication(pid, "avery@example.com")
+	if app.Status != "submitted" || len(app.CoApplicants) != 1 || len(app.References) != 1 {
+		t.Fatalf("unexpected submitted application: %+v", app)
+	}
+
+	queue := decode[[]RentalApplication](t, e.do(http.MethodGet, "/api/admin/applications?status=submitted", token, nil))
+	if len(queue) != 1 || queue[0].ID != app.ID {
+		t.Fatalf("expected application in submitted queue, got %+v", queue)
+	}
+
+	path := fmt.Sprintf("/api/admin/applications/%d", app.ID)
+	detail := decode[RentalApplication](t, e.do(http.MethodGet, path, token, nil))
+	if len(detail.CoApplicants) != 1 || detail.References[0].Name != "Pat Landlord" {
+		t.Fatalf("detail missing co-applicants or references: %+v", detail)
+	}
+
+	// Only approved applications convert.
+	e.expect(e.do(http.MethodPost, path+"/convert", token, nil), http.StatusConflict)
+
+	e.expect(e.do(http.MethodPut, path, token, map[string]string{"status": "pending"}), http.StatusBadRequest)
+	e.expect(e.do(http.MethodPut, path, token, map[string]string{"status": "screening"}), http.StatusOK)
+	rec := e.do(http.MethodPut, path, token, map[string]string{"status": "approved", "adminNotes": "Strong income"})
+	e.expect(rec, http.StatusOK)
+	if approved := decode[RentalApplication](t, rec); approved.ReviewedAt == nil || *approved.AdminNotes != "Strong income" {
+		t.Fatalf("approval not recorded: %+v", approved)
+	}
+
+	rec = e.do(http.MethodPost, path+"/convert", token, nil)
+	e.expect(rec, http.StatusCreated)
+	converted := decode[ConvertApplicationResponse](t, rec)
+	if converted.Tenant.Email != "avery@example.com" || converted.Lease.Status != "draft" {
+		t.Fatalf("unexpected conversion: %+v", converted)
+	}
+	if converted.Lease.MonthlyRent != 1800 || converted.Lease.StartDate != day(30) {
+		t.Fatalf("draft lease should use property rent and move-in date: %+v", converted.Lease)
+	}
+
+	// Converted applications cannot be converted again or change decision.
+	e.expect(e.do(http.MethodPost, path+"/convert", token, nil), http.StatusConflict)
+	e.expect(e.do(http.MethodPut, path, token, map[string]string{"status": "denied"}), http.StatusConflict)
+
+	// Drafts are left alone by the lease status job.
+	updateLeaseStatuses(context.Background())
+	rec = e.do(http.MethodGet, fmt.Sprintf("/api/admin/leases/%d", converted.Lease.ID), token, nil)
+	e.expect(rec, http.StatusOK)
+	if decode[Lease](t, rec).Status != "draft" {
+		t.Fatal("expected stored lease to be a draft")
+	}
+}
+
+func TestConvertReusesExistingTenant(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Returning Renter", 1500)
+	tid := e.createTenant(token, "returning@example.com", "")
+
+	app := e.submitApplication(pid, "returning@example.com")
+	path := fmt.Sprintf("/api/admin/applications/%d", app.ID)
+	e.expect(e.do(http.MethodPut, path, token, map[string]string{"status": "approved"}), http.StatusOK)
+
+	rec := e.do(http.MethodPost, path+"/convert", token, nil)
+	e.expect(rec, http.StatusCreated)
+	if got := decode[ConvertApplicationResponse](t, rec); got.Tenant.ID != tid || got.Lease.TenantID != tid {
+		t.Fatalf("expected existing tenant %d to be reused, got %+v", tid, got)
+	}
+}
@@ -0,0 +1,579 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ============================================================================
+// CONFIGURATION
+// ============================================================================
+
+// cfg is the effective configuration, loaded once at startup by loadConfig.
+var cfg = defaultConfig()
+
+// Config is layered: built-in defaults, then an optional YAML file
+// (CONFIG_FILE, or config.yaml when present), then .env and the process
+// environment, which always win.
+type Config struct {
+	// Env is "development" or "production". Development relaxes the admin
+	// password and CORS checks in validate.
+	Env  string `yaml:"env"`
+	Port string `yaml:"port"`
+
+	DB   DBConfig   `yaml:"db"`
+	HTTP HTTPConfig `yaml:"http"`
+	Jobs JobsConfig `yaml:"jobs"`
+
+	AdminPassword  string        `yaml:"admin_password"`
+	AdminTokenTTL  time.Duration `yaml:"admin_token_ttl"`
+	TenantTokenTTL time.Duration `yaml:"tenant_token_ttl"`
+	OwnerTokenTTL  time.Duration `yaml:"owner_token_ttl"`
+
+	AllowedOrigins []string `yaml:"allowed_origins"`
+
+	// HealthTimeout bounds the database ping in /api/health/ready.
+	HealthTimeout time.Duration `yaml:"health_timeout"`
+
+	// MetricsToken must be sent as a bearer token to scrape /metrics. It is
+	// required outside development; left empty there, /metrics is open.
+	MetricsToken string `yaml:"metrics_token"`
+
+	// PayoutEncryptionKey is a base64-encoded 32-byte AES key for owners'
+	// bank details. Development falls back to a fixed key when it is unset.
+	PayoutEncryptionKey string `yaml:"payout_encryption_key"`
+
+	Screening ScreeningConfig `yaml:"screening"`
+	Contact   ContactConfig   `yaml:"contact"`
+	Media     MediaConfig     `yaml:"media"`
+	Geocoder  GeocoderConfig  `yaml:"geocoder"`
+	Mail      MailConfig      `yaml:"mail"`
+
+	// TrustProxy takes the client IP from X-Forwarded-For. Only enable it
+	// behind a proxy that overwrites the header, or clients can spoof it.
+	TrustProxy bool `yaml:"trust_proxy"`
+}
+
+type DBConfig struct {
+	Host            string        `yaml:"host"`
+	Port            string        `yaml:"port"`
+	User            string        `yaml:"user"`
+	Password        string        `yaml:"password"`
+	Name            string        `yaml:"name"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnectTimeout  time.Duration `yaml:"connect_timeout"`
+}
+
+type HTTPConfig struct {
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout bounds how long SIGTERM waits for in-flight requests
+	// and background jobs before the process exits.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	MaxBodyBytes    int64         `yaml:"max_body_bytes"`
+}
+
+type JobsConfig struct {
+	LeaseStatusInterval time.Duration `yaml:"lease_status_interval"`
+	// SearchIndexInterval is how often properties missing from the search
+	// index or without coordinates are picked up.
+	SearchIndexInterval time.Duration `yaml:"search_index_interval"`
+	// SavedSearchInterval is how often saved searches are checked for
+	// newly available listings.
+	SavedSearchInterval time.Duration `yaml:"saved_search_interval"`
+	// TrashPurgeInterval is how often deleted tenants, leases and payments
+	// older than TrashRetention are removed for good.
+	TrashPurgeInterval time.Duration `yaml:"trash_purge_interval"`
+	TrashRetention     time.Duration `yaml:"trash_retention"`
+	// RentChangeInterval is how often scheduled rent changes that have
+	// come due are applied.
+	RentChangeInterval time.Duration `yaml:"rent_change_interval"`
+}
+
+// ScreeningConfig controls rental application fees and the pass/fail
+// recommendation computed from a screening report.
+type ScreeningConfig struct {
+	// Provider names the ScreeningProvider implementation; only "fake" exists.
+	Provider       string  `yaml:"provider"`
+	ApplicationFee float64 `yaml:"application_fee"`
+	// MinIncomeRatio is combined applicant income over monthly rent.
+	MinIncomeRatio float64 `yaml:"min_income_ratio"`
+	MinCreditScore int     `yaml:"min_credit_score"`
+}
+
+// ContactConfig rate limits the public contact form per client IP.
+type ContactConfig struct {
+	RateLimit  int           `yaml:"rate_limit"`
+	RateWindow time.Duration `yaml:"rate_window"`
+}
+
+// MediaConfig controls where uploaded property photos are stored.
+type MediaConfig struct {
+	// Dir is the local directory served under /media/.
+	Dir string `yaml:"dir"`
+	// BaseURL prefixes stored keys in API responses; point it at a CDN or
+	// the proxy in front of Dir to serve files from elsewhere.
+	BaseURL        string `yaml:"base_url"`
+	MaxUploadBytes int64  `yaml:"max_upload_bytes"`
+	// MaxImagePixels caps width×height of uploaded photos, which are
+	// decoded in memory at four bytes a pixel.
+	MaxImagePixels int64 `yaml:"max_image_pixels"`
+	// PrivateDir holds uploads that are only served through authenticated
+	// API routes, like expense receipts. It must not be inside Dir.
+	PrivateDir string `yaml:"private_dir"`
+}
+
+// GeocoderConfig selects how property addresses become coordinates for
+// radius search.
+type GeocoderConfig struct {
+	// Provider names the Geocoder implementation: "offline" (a built-in
+	// table of Portland-area ZIP centroids) or "nominatim".
+	Provider string `yaml:"provider"`
+	// URL is the Nominatim server, the public one or a self-hosted one.
+	URL string `yaml:"url"`
+	// UserAgent identifies us to Nominatim, as its usage policy requires;
+	// include a contact address.
+	UserAgent string        `yaml:"user_agent"`
+	Timeout   time.Duration `yaml:"timeout"`
+}
+
+// MailConfig controls outgoing email and the links put in it.
+type MailConfig struct {
+	// Provider is "log" (write to the server log) or "file" (.eml files in Dir).
+	Provider string `yaml:"provider"`
+	From     string `yaml:"from"`
+	Dir      string `yaml:"dir"`
+	// SiteURL is the public website, for listing links; APIURL is this
+	// server as visitors reach it, for verification and unsubscribe links.
+	SiteURL string `yaml:"site_url"`
+	APIURL  string `yaml:"api_url"`
+}
+
+const (
+	envDevelopment = "development"
+	envProduction  = "production"
+
+	// defaultAdminPassword is only accepted in development mode.
+	defaultAdminPassword = "admin123"
+	// defaultPayoutEncryptionKey is only accepted in development mode.
+	defaultPayoutEncryptionKey = "ZGV2ZWxvcG1lbnQtb25seS1wYXlvdXQta2V5LTAwMDA="
+)
+
+func defaultConfig() Config {
+	return Config{
+		Env:  envProduction,
+		Port: "8080",
+		DB: DBConfig{
+			Host:            "127.0.0.1",
+			Port:            "3306",
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 5 * time.Minute,
+			ConnectTimeout:  5 * time.Second,
+		},
+		HTTP: HTTPConfig{
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   20 * time.Second,
+			MaxBodyBytes:      1 << 20,
+		},
+		Jobs: JobsConfig{
+			LeaseStatusInterval: time.Hour,
+			SearchIndexInterval: time.Hour,
+			SavedSearchInterval: 15 * time.Minute,
+			TrashPurgeInterval:  24 * time.Hour,
+			TrashRetention:      90 * 24 * time.Hour,
+			RentChangeInterval:  time.Hour,
+		},
+		AdminTokenTTL:  24 * time.Hour,
+		TenantTokenTTL: 24 * time.Hour,
+		OwnerTokenTTL:  24 * time.Hour,
+		HealthTimeout:  2 * time.Second,
+		Screening: ScreeningConfig{
+			Provider:       screeningProviderFake,
+			ApplicationFee: 45,
+			MinIncomeRatio: 3,
+			MinCreditScore: 620,
+		},
+		Contact: ContactConfig{
+			RateLimit:  5,
+			RateWindow: time.Hour,
+		},
+		Media: MediaConfig{
+			Dir:            "uploads",
+			BaseURL:        "/media",
+			MaxUploadBytes: 10 << 20,
+			MaxImagePixels: 40_000_000,
+			PrivateDir:     "private",
+		},
+		Geocoder: GeocoderConfig{
+			Provider: geocoderOffline,
+			URL:      "https://nominatim.openstreetmap.org",
+			Timeout:  5 * time.Second,
+		},
+		Mail: MailConfig{
+			Provider: mailerLog,
+			From:     "listings@localhost",
+			Dir:      "mail",
+			SiteURL:  "http://localhost:3000",
+			APIURL:   "http://localhost:8080",
+		},
+	}
+}
+
+// loadConfig builds the effective configuration. It only fails on values that
+// cannot be parsed; semantic checks live in validate so `config print` can
+// still show a broken configuration.
+func loadConfig() (Config, error) {
+	c := defaultConfig()
+
+	path := os.Getenv("CONFIG_FILE")
+	explicit := path != ""
+	if !explicit {
+		path = "config.yaml"
+	}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &c); err != nil {
+			return c, fmt.Errorf("parse %s: %w", path, err)
+		}
+	case explicit || !errors.Is(err, os.ErrNotExist):
+		return c, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	var errs []error
+	envString(&c.Env, "APP_ENV")
+	envString(&c.Port, "PORT")
+	envString(&c.DB.Host, "DB_HOST")
+	envString(&c.DB.Port, "DB_PORT")
+	envString(&c.DB.User, "DB_USER")
+	envString(&c.DB.Password, "DB_PASSWORD")
+	envString(&c.DB.Name, "DB_NAME")
+	errs = append(errs,
+		envInt(&c.DB.MaxOpenConns, "DB_MAX_OPEN_CONNS"),
+		envInt(&c.DB.MaxIdleConns, "DB_MAX_IDLE_CONNS"),
+		envDuration(&c.DB.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME"),
+		envDuration(&c.DB.ConnectTimeout, "DB_CONNECT_TIMEOUT"),
+	)
+	errs = append(errs,
+		envDuration(&c.HTTP.ReadHeaderTimeout, "HTTP_READ_HEADER_TIMEOUT"),
+		envDuration(&c.HTTP.ReadTimeout, "HTTP_READ_TIMEOUT"),
+		envDuration(&c.HTTP.WriteTimeout, "HTTP_WRITE_TIMEOUT"),
+		envDuration(&c.HTTP.IdleTimeout, "HTTP_IDLE_TIMEOUT"),
+		envDuration(&c.HTTP.ShutdownTimeout, "HTTP_SHUTDOWN_TIMEOUT"),
+		envInt64(&c.HTTP.MaxBodyBytes, "HTTP_MAX_BODY_BYTES"),
+		envDuration(&c.Jobs.LeaseStatusInterval, "JOB_LEASE_STATUS_INTERVAL"),
+		envDuration(&c.Jobs.SearchIndexInterval, "JOB_SEARCH_INDEX_INTERVAL"),
+		envDuration(&c.Jobs.SavedSearchInterval, "JOB_SAVED_SEARCH_INTERVAL"),
+		envDuration(&c.Jobs.TrashPurgeInterval, "JOB_TRASH_PURGE_INTERVAL"),
+		envDuration(&c.Jobs.TrashRetention, "TRASH_RETENTION"),
+		envDuration(&c.Jobs.RentChangeInterval, "JOB_RENT_CHANGE_INTERVAL"),
+	)
+	envString(&c.AdminPassword, "ADMIN_PASSWORD")
+	errs = append(errs,
+		envDuration(&c.AdminTokenTTL, "ADMIN_TOKEN_TTL"),
+		envDuration(&c.TenantTokenTTL, "TENANT_TOKEN_TTL"),
+		envDuration(&c.OwnerTokenTTL, "OWNER_TOKEN_TTL"),
+	)
+	errs = append(errs, envDuration(&c.HealthTimeout, "HEALTH_TIMEOUT"))
+	envString(&c.MetricsToken, "METRICS_TOKEN")
+	envString(&c.PayoutEncryptionKey, "PAYOUT_ENCRYPTION_KEY")
+	envString(&c.Screening.Provider, "SCREENING_PROVIDER")
+	errs = append(errs,
+		envFloat(&c.Screening.ApplicationFee, "APPLICATION_FEE"),
+		envFloat(&c.Screening.MinIncomeRatio, "SCREENING_MIN_INCOME_RATIO"),
+		envInt(&c.Screening.MinCreditScore, "SCREENING_MIN_CREDIT_SCORE"),
+		envInt(&c.Contact.RateLimit, "CONTACT_RATE_LIMIT"),
+		envDuration(&c.Contact.RateWindow, "CONTACT_RATE_WINDOW"),
+		envBool(&c.TrustProxy, "TRUST_PROXY"),
+		envInt64(&c.Media.MaxUploadBytes, "MEDIA_MAX_UPLOAD_BYTES"),
+		envInt64(&c.Media.MaxImagePixels, "MEDIA_MAX_IMAGE_PIXELS"),
+		envDuration(&c.Geocoder.Timeout, "GEOCODER_TIMEOUT"),
+	)
+	envString(&c.Media.Dir, "MEDIA_DIR")
+	envString(&c.Media.BaseURL, "MEDIA_BASE_URL")
+	envString(&c.Media.PrivateDir, "MEDIA_PRIVATE_DIR")
+	envString(&c.Geocoder.Provider, "GEOCODER_PROVIDER")
+	envString(&c.Geocoder.URL, "GEOCODER_URL")
+	envString(&c.Geocoder.UserAgent, "GEOCODER_USER_AGENT")
+	envString(&c.Mail.Provider, "MAIL_PROVIDER")
+	envString(&c.Mail.From, "MAIL_FROM")
+	envString(&c.Mail.Dir, "MAIL_DIR")
+	envString(&c.Mail.SiteURL, "SITE_URL")
+	envString(&c.Mail.APIURL, "API_URL")
+	if raw := os.Getenv("ALLOWED_ORIGINS"); raw != "" {
+		c.AllowedOrigins = splitList(raw)
+	}
+
+	if c.IsDevelopment() {
+		if c.AdminPassword == "" {
+			c.AdminPassword = defaultAdminPassword
+		}
+		if c.PayoutEncryptionKey == "" {
+			c.PayoutEncryptionKey = defaultPayoutEncryptionKey
+		}
+		if len(c.AllowedOrigins) == 0 {
+			c.AllowedOrigins = []string{"http://localhost:3000", "http://localhost:3001"}
+		}
+	}
+
+	return c, errors.Join(errs...)
+}
+
+func (c Config) IsDevelopment() bool {
+	return c.Env == envDevelopment
+}
+
+// validate reports every problem with the configuration at once.
+func (c Config) validate() error {
+	var errs []error
+	if c.Env != envDevelopment && c.Env != envProduction {
+		errs = append(errs, fmt.Errorf("APP_ENV must be %q or %q, got %q", envDevelopment, envProduction, c.Env))
+	}
+	if c.Port == "" {
+		errs = append(errs, errors.New("PORT is required"))
+	}
+	if c.DB.User == "" {
+		errs = append(errs, errors.New("DB_USER is required"))
+	}
+	if c.DB.Password == "" {
+		errs = append(errs, errors.New("DB_PASSWORD is required"))
+	}
+	if c.DB.Name == "" {
+		errs = append(errs, errors.New("DB_NAME is required"))
+	}
+	if c.DB.MaxOpenConns < 1 {
+		errs = append(errs, errors.New("DB_MAX_OPEN_CONNS must be at least 1"))
+	}
+	if c.DB.MaxIdleConns < 0 || c.DB.MaxIdleConns > c.DB.MaxOpenConns {
+		errs = append(errs, errors.New("DB_MAX_IDLE_CONNS must be between 0 and DB_MAX_OPEN_CONNS"))
+	}
+	if c.DB.ConnectTimeout <= 0 {
+		errs = append(errs, errors.New("DB_CONNECT_TIMEOUT must be positive"))
+	}
+	if c.HTTP.ReadHeaderTimeout <= 0 || c.HTTP.ReadTimeout <= 0 || c.HTTP.WriteTimeout <= 0 || c.HTTP.IdleTimeout <= 0 {
+		errs = append(errs, errors.New("HTTP_READ_HEADER_TIMEOUT, HTTP_READ_TIMEOUT, HTTP_WRITE_TIMEOUT and HTTP_IDLE_TIMEOUT must be positive"))
+	}
+	if c.HTTP.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("HTTP_SHUTDOWN_TIMEOUT must be positive"))
+	}
+	if c.HTTP.MaxBodyBytes < 1 {
+		errs = append(errs, errors.New("HTTP_MAX_BODY_BYTES must be positive"))
+	}
+	if c.Jobs.LeaseStatusInterval <= 0 || c.Jobs.SearchIndexInterval <= 0 || c.Jobs.SavedSearchInterval <= 0 || c.Jobs.TrashPurgeInterval <= 0 ||
+		c.Jobs.RentChangeInterval <= 0 {
+		errs = append(errs, errors.New("JOB_LEASE_STATUS_INTERVAL, JOB_SEARCH_INDEX_INTERVAL, JOB_SAVED_SEARCH_INTERVAL, JOB_TRASH_PURGE_INTERVAL and JOB_RENT_CHANGE_INTERVAL must be positive"))
+	}
+	if c.Jobs.TrashRetention <= 0 {
+		errs = append(errs, errors.New("TRASH_RETENTION must be positive"))
+	}
+	if c.AdminTokenTTL <= 0 || c.TenantTokenTTL <= 0 || c.OwnerTokenTTL <= 0 {
+		errs = append(errs, errors.New("ADMIN_TOKEN_TTL, TENANT_TOKEN_TTL and OWNER_TOKEN_TTL must be positive"))
+	}
+	if c.HealthTimeout <= 0 {
+		errs = append(errs, errors.New("HEALTH_TIMEOUT must be positive"))
+	}
+	if _, err := newScreeningProvider(c.Screening.Provider); err != nil {
+		errs = append(errs, fmt.Errorf("SCREENING_PROVIDER: %w", err))
+	}
+	if c.Screening.ApplicationFee < 0 || c.Screening.MinIncomeRatio < 0 {
+		errs = append(errs, errors.New("APPLICATION_FEE and SCREENING_MIN_INCOME_RATIO must not be negative"))
+	}
+	if c.Screening.MinCreditScore < 0 || c.Screening.MinCreditScore > 850 {
+		errs = append(errs, errors.New("SCREENING_MIN_CREDIT_SCORE must be between 0 and 850"))
+	}
+	if c.Contact.RateLimit < 1 || c.Contact.RateWindow <= 0 {
+		errs = append(errs, errors.New("CONTACT_RATE_LIMIT and CONTACT_RATE_WINDOW must be positive"))
+	}
+	if c.Media.Dir == "" {
+		errs = append(errs, errors.New("MEDIA_DIR is required"))
+	}
+	if c.Media.PrivateDir == "" {
+		errs = append(errs, errors.New("MEDIA_PRIVATE_DIR is required"))
+	} else if dirWithin(c.Media.PrivateDir, c.Media.Dir) {
+		errs = append(errs, errors.New("MEDIA_PRIVATE_DIR must not be inside MEDIA_DIR, which is served publicly"))
+	}
+	if c.Media.MaxUploadBytes < 1 {
+		errs = append(errs, errors.New("MEDIA_MAX_UPLOAD_BYTES must be positive"))
+	}
+	if c.Media.MaxImagePixels < 1 {
+		errs = append(errs, errors.New("MEDIA_MAX_IMAGE_PIXELS must be positive"))
+	}
+	if _, err := newGeocoder(c.Geocoder); err != nil {
+		errs = append(errs, fmt.Errorf("GEOCODER_PROVIDER: %w", err))
+	}
+	if c.Geocoder.Timeout <= 0 {
+		errs = append(errs, errors.New("GEOCODER_TIMEOUT must be positive"))
+	}
+	if _, err := newMailer(c.Mail); err != nil {
+		errs = append(errs, fmt.Errorf("MAIL_PROVIDER: %w", err))
+	}
+	if c.Mail.From == "" || c.Mail.SiteURL == "" || c.Mail.APIURL == "" {
+		errs = append(errs, errors.New("MAIL_FROM, SITE_URL and API_URL are required"))
+	}
+	if c.AdminPassword == "" {
+		errs = append(errs, errors.New("ADMIN_PASSWORD is required"))
+	}
+	if c.PayoutEncryptionKey != "" {
+		if _, err := newPayoutCipher(c.PayoutEncryptionKey); err != nil {
+			errs = append(errs, fmt.Errorf("PAYOUT_ENCRYPTION_KEY: %w", err))
+		}
+	}
+	if !c.IsDevelopment() {
+		if c.PayoutEncryptionKey == "" || c.PayoutEncryptionKey == defaultPayoutEncryptionKey {
+			errs = append(errs, errors.New("PAYOUT_ENCRYPTION_KEY is required outside development and must not be the development key"))
+		}
+		if c.AdminPassword == defaultAdminPassword {
+			errs = append(errs, fmt.Errorf("ADMIN_PASSWORD must not be the default %q outside development", defaultAdminPassword))
+		}
+		if len(c.AllowedOrigins) == 0 {
+			errs = append(errs, errors.New("ALLOWED_ORIGINS is required outside development"))
+		}
+		// /metrics includes business figures, so it is never left open.
+		if c.MetricsToken == "" {
+			errs = append(errs, errors.New("METRICS_TOKEN is required outside development"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// redacted returns a copy that is safe to print or log.
+func (c Config) redacted() Config {
+	c.DB.Password = redact(c.DB.Password)
+	c.AdminPassword = redact(c.AdminPassword)
+	c.MetricsToken = redact(c.MetricsToken)
+	c.PayoutEncryptionKey = redact(c.PayoutEncryptionKey)
+	return c
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "********"
+}
+
+// runConfigCommand implements `server config print`.
+func runConfigCommand(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return fmt.Errorf("usage: config print")
+	}
+
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg.redacted()); err != nil {
+		return err
+	}
+	enc.Close()
+
+	if err := cfg.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "\nconfiguration is invalid:\n%v\n", err)
+	}
+	return nil
+}
+
+func envString(dst *string, key string) {
+	if val := os.Getenv(key); val != "" {
+		*dst = val
+	}
+}
+
+func envInt(dst *int, key string) error {
+	val := os.Getenv(key)
+	if val == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return fmt.Errorf("%s: %q is not an integer", key, val)
+	}
+	*dst = n
+	return nil
+}
+
+func envInt64(dst *int64, key string) error {
+	val := os.Getenv(key)
+	if val == "" {
+		return nil
+	}
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s: %q is not an integer", key, val)
+	}
+	*dst = n
+	return nil
+}
+
+func envFloat(dst *float64, key string) error {
+	val := os.Getenv(key)
+	if val == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return fmt.Errorf("%s: %q is not a number", key, val)
+	}
+	*dst = f
+	return nil
+}
+
+func envBool(dst *bool, key string) error {
+	val := os.Getenv(key)
+	if val == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return fmt.Errorf("%s: %q is not a boolean", key, val)
+	}
+	*dst = b
+	return nil
+}
+
+func envDuration(dst *time.Duration, key string) error {
+	val := os.Getenv(key)
+	if val == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return fmt.Errorf("%s: %q is not a duration (e.g. 30s, 5m, 24h)", key, val)
+	}
+	*dst = d
+	return nil
+}
+
+// dirWithin reports whether dir is parent or somewhere below it.
+func dirWithin(dir, parent string) bool {
+	d, err1 := filepath.Abs(dir)
+	p, err2 := filepath.Abs(parent)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	rel, err := filepath.Rel(p, d)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, item := range strings.Split(raw, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearConfigEnv isolates loadConfig from the developer's shell and any
+// config.yaml in the working directory by pointing CONFIG_FILE at an empty file.
+func clearConfigEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{
+		"APP_ENV", "PORT", "DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME",
+		"DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME", "DB_CONNECT_TIMEOUT",
+		"ADMIN_PASSWORD", "ADMIN_TOKEN_TTL", "TENANT_TOKEN_TTL", "OWNER_TOKEN_TTL", "ALLOWED_ORIGINS", "METRICS_TOKEN", "HEALTH_TIMEOUT",
+		"SCREENING_PROVIDER", "APPLICATION_FEE", "SCREENING_MIN_INCOME_RATIO", "SCREENING_MIN_CREDIT_SCORE",
+		"CONTACT_RATE_LIMIT", "CONTACT_RATE_WINDOW", "TRUST_PROXY",
+		"MEDIA_DIR", "MEDIA_BASE_URL", "MEDIA_MAX_UPLOAD_BYTES", "MEDIA_MAX_IMAGE_PIXELS", "MEDIA_PRIVATE_DIR", "PAYOUT_ENCRYPTION_KEY",
+		"JOB_LEASE_STATUS_INTERVAL", "JOB_SEARCH_INDEX_INTERVAL", "GEOCODER_PROVIDER",
+		"GEOCODER_URL", "GEOCODER_USER_AGENT", "GEOCODER_TIMEOUT",
+		"JOB_SAVED_SEARCH_INTERVAL", "JOB_TRASH_PURGE_INTERVAL", "TRASH_RETENTION", "JOB_RENT_CHANGE_INTERVAL",
+		"MAIL_PROVIDER", "MAIL_FROM", "MAIL_DIR", "SITE_URL", "API_URL",
+	} {
+		t.Setenv(key, "")
+	}
+	empty := filepath.Join(t.TempDir(), "empty.yaml")
+	os.WriteFile(empty, nil, 0o600)
+	t.Setenv("CONFIG_FILE", empty)
+}
+
+func setValidProductionEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("DB_USER", "app")
+	t.Setenv("DB_PASSWORD", "db-secret")
+	t.Setenv("DB_NAME", "roses_clovers")
+	t.Setenv("ADMIN_PASSWORD", "a-strong-password")
+	t.Setenv("PAYOUT_ENCRYPTION_KEY", "cHJvZHVjdGlvbi1wYXlvdXQta2V5LWZvci10ZXN0cyE=")
+	t.Setenv("ALLOWED_ORIGINS", "https://example.com, https://admin.example.com")
+	t.Setenv("METRICS_TOKEN", "scrape-secret")
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("CONFIG_FILE", "")
+	setValidProductionEnv(t)
+	t.Setenv("DB_MAX_OPEN_CONNS", "25")
+	t.Setenv("ADMIN_TOKEN_TTL", "2h")
+
+	// With no CONFIG_FILE, a missing config.yaml is not an error.
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	os.Chdir(dir)
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	c, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if err := c.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if c.DB.MaxOpenConns != 25 || c.DB.MaxIdleConns != 5 {
+		t.Fatalf("unexpected pool sizes: %+v", c.DB)
+	}
+	if c.AdminTokenTTL != 2*time.Hour || c.TenantTokenTTL != 24*time.Hour {
+		t.Fatalf("unexpected TTLs: %v %v", c.AdminTokenTTL, c.TenantTokenTTL)
+	}
+	if len(c.AllowedOrigins) != 2 || c.AllowedOrigins[1] != "https://admin.example.com" {
+		t.Fatalf("unexpected origins: %q", c.AllowedOrigins)
+	}
+}
+
+func TestLoadConfigYAMLWithEnvOverride(t *testing.T) {
+	clearConfigEnv(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte(`
+env: production
+port: "9090"
+db:
+  user: yaml-user
+  password: yaml-secret
+  name: yaml_db
+  max_open_conns: 4
+  max_idle_conns: 2
+  conn_max_lifetime: 1m
+admin_password: yaml-admin
+payout_encryption_key: eWFtbC1wYXlvdXQta2V5LWZvci10ZXN0cy0wMDAwMDA=
+metrics_token: yaml-scrape
+tenant_token_ttl: 30m
+allowed_origins:
+  - https://yaml.example.com
+`), 0o600)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("DB_USER", "env-user")
+
+	c, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if err := c.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if c.Port != "9090" || c.DB.Name != "yaml_db" || c.DB.ConnMaxLifetime != time.Minute || c.TenantTokenTTL != 30*time.Minute {
+		t.Fatalf("YAML values not applied: %+v", c)
+	}
+	if c.DB.User != "env-user" {
+		t.Fatalf("environment should override YAML, got user %q", c.DB.User)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DB_MAX_OPEN_CONNS", "lots")
+	t.Setenv("ADMIN_TOKEN_TTL", "forever")
+
+	_, err := loadConfig()
+	if err == nil || !strings.Contains(err.Error(), "DB_MAX_OPEN_CONNS") || !strings.Contains(err.Error(), "ADMIN_TOKEN_TTL") {
+		t.Fatalf("expected both parse errors, got %v", err)
+	}
+
+	// An explicitly named config file must exist.
+	clearConfigEnv(t)
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+	if _, err := loadConfig(); err == nil {
+		t.Fatal("expected an error for a missing CONFIG_FILE")
+	}
+}
+
+func TestValidateRejectsDefaultAdminPasswordOutsideDev(t *testing.T) {
+	c := defaultConfig()
+	c.DB.User, c.DB.Password, c.DB.Name = "app", "secret", "db"
+	c.AllowedOrigins = []string{"https://example.com"}
+	c.AdminPassword = defaultAdminPassword
+
+	if err := c.validate(); err == nil || !strings.Contains(err.Error(), "ADMIN_PASSWORD") {
+		t.Fatalf("expected admin password error in production, got %v", err)
+	}
+
+	c.Env = envDevelopment
+	if err := c.validate(); err != nil {
+		t.Fatalf("default password should be allowed in development: %v", err)
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	c := defaultConfig()
+	c.Env = "staging"
+	c.DB.MaxOpenConns = 0
+	c.Media.PrivateDir = "uploads/private"
+
+	err := c.validate()
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{"APP_ENV", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_MAX_OPEN_CONNS", "ADMIN_PASSWORD", "ALLOWED_ORIGINS", "MEDIA_PRIVATE_DIR", "PAYOUT_ENCRYPTION_KEY", "METRICS_TOKEN"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %s in validation error:\n%v", want, err)
+		}
+	}
+}
+
+func TestDevelopmentDefaults(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("APP_ENV", envDevelopment)
+
+	c, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if c.AdminPassword != defaultAdminPassword || c.PayoutEncryptionKey != defaultPayoutEncryptionKey || len(c.AllowedOrigins) == 0 {
+		t.Fatalf("development defaults not applied: %+v", c)
+	}
+}
+
+func TestConfigRedacted(t *testing.T) {
+	c := defaultConfig()
+	c.DB.Password = "db-secret"
+	c.AdminPassword = "admin-secret"
+
+	r := c.redacted()
+	if r.DB.Password == "db-secret" || r.AdminPassword == "admin-secret" {
+		t.Fatalf("secrets not redacted: %+v", r)
+	}
+	if c.DB.Password != "db-secret" {
+		t.Fatal("redacted must not modify the original")
+	}
+}
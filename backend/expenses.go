@@ -0,0 +1,751 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// MODELS - EXPENSES
+// ============================================================================
+
+// Expense is money spent on a property. It can point at the maintenance
+// request it paid for and carry an uploaded receipt; ReceiptURL is the
+// admin route that serves it.
+type Expense struct {
+	ID                   int       `json:"id"`
+	PropertyID           int       `json:"propertyId"`
+	MaintenanceRequestID *int      `json:"maintenanceRequestId,omitempty"`
+	Category             string    `json:"category"`
+	Vendor               string    `json:"vendor"`
+	Amount               float64   `json:"amount"`
+	ExpenseDate          string    `json:"expenseDate"`
+	Description          *string   `json:"description,omitempty"`
+	ReceiptURL           *string   `json:"receiptUrl,omitempty"`
+	ReceiptName          *string   `json:"receiptName,omitempty"`
+	CreatedAt            time.Time `json:"createdAt"`
+	UpdatedAt            time.Time `json:"updatedAt"`
+	// Joined fields
+	PropertyName *string `json:"propertyName,omitempty"`
+	receiptKey   string
+}
+
+// expenseCategories are the accepted categories, in P&L column order.
+var expenseCategories = []string{"repairs", "taxes", "insurance", "utilities", "hoa", "other"}
+
+// maintenanceExpenseClause picks out the expenses an owner statement
+// deducts as maintenance: repairs, and anything paid against a maintenance
+// request.
+const maintenanceExpenseClause = "(e.category = 'repairs' OR e.maintenance_request_id IS NOT NULL)"
+
+var allowedReceiptTypes = map[string]string{
+	"application/pdf": "pdf",
+	"image/jpeg":      "jpg",
+	"image/png":       "png",
+	"image/webp":      "webp",
+}
+
+func validExpenseCategory(c string) bool {
+	for _, v := range expenseCategories {
+		if c == v {
+			return true
+		}
+	}
+	return false
+}
+
+// validateExpense checks an expense before it is saved, including that a
+// linked maintenance request is for the same property.
+func validateExpense(e *Expense) string {
+	e.Vendor = strings.TrimSpace(e.Vendor)
+	if e.Category == "" {
+		e.Category = "other"
+	}
+	if e.PropertyID == 0 || e.Vendor == "" || e.ExpenseDate == "" {
+		return "Property, vendor and expense date are required"
+	}
+	if !validExpenseCategory(e.Category) {
+		return "Category must be one of " + strings.Join(expenseCategories, ", ")
+	}
+	if e.Amount <= 0 {
+		return "Amount must be positive"
+	}
+	if _, err := time.Parse("2006-01-02", e.ExpenseDate); err != nil {
+		return "Expense date must be YYYY-MM-DD"
+	}
+
+	var n int
+	db.QueryRow("SELECT COUNT(*) FROM properties WHERE id = ?", e.PropertyID).Scan(&n)
+	if n == 0 {
+		return "Property not found"
+	}
+	if e.MaintenanceRequestID != nil {
+		var propertyID int
+		err := db.QueryRow("SELECT property_id FROM maintenance_requests WHERE id = ?", *e.MaintenanceRequestID).Scan(&propertyID)
+		if err != nil {
+			return "Maintenance request not found"
+		}
+		if propertyID != e.PropertyID {
+			return "Maintenance request is for a different property"
+		}
+	}
+	return ""
+}
+
+// ============================================================================
+// HANDLERS - ADMIN EXPENSES
+// ============================================================================
+
+func adminExpensesHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		getExpenses(w, r)
+	case http.MethodPost:
+		createExpense(w, r)
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func adminExpenseByIDHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+
+	id, action, err := extractIDAndAction(r.URL.Path, "/api/admin/expenses/")
+	if err != nil {
+		jsonError(w, "Invalid expense ID", http.StatusBadRequest)
+		return
+	}
+
+	if action == "receipt" {
+		switch r.Method {
+		case http.MethodGet:
+			serveExpenseReceipt(w, r, id)
+		case http.MethodPost:
+			uploadExpenseReceipt(w, r, id)
+		case http.MethodDelete:
+			deleteExpenseReceipt(w, id)
+		default:
+			jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+	if action != "" {
+		jsonError(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		getExpenseByID(w, id)
+	case http.MethodPut:
+		updateExpense(w, r, id)
+	case http.MethodDelete:
+		deleteExpense(w, id)
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getExpenses lists expenses, newest first. Supports propertyId, category,
+// maintenanceRequestId, ownerId and a from/to date range.
+func getExpenses(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := `SELECT ` + expenseColumns + ` FROM expenses e JOIN properties p ON p.id = e.property_id WHERE 1=1`
+	args := []interface{}{}
+
+	for param, column := range map[string]string{"propertyId": "e.property_id", "maintenanceRequestId": "e.maintenance_request_id"} {
+		if raw := q.Get(param); raw != "" {
+			if id, err := strconv.Atoi(raw); err == nil {
+				query += " AND " + column + " = ?"
+				args = append(args, id)
+			}
+		}
+	}
+	if category := q.Get("category"); category != "" {
+		query += " AND e.category = ?"
+		args = append(args, category)
+	}
+	if from := q.Get("from"); from != "" {
+		query += " AND e.expense_date >= ?"
+		args = append(args, from)
+	}
+	if to := q.Get("to"); to != "" {
+		query += " AND e.expense_date <= ?"
+		args = append(args, to)
+	}
+	clause, ownerArgs, err := ownerFilter(q.Get("ownerId"), "e.property_id")
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query += clause
+	args = append(args, ownerArgs...)
+	query += " ORDER BY e.expense_date DESC, e.id DESC"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("Error querying expenses: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	expenses := []Expense{}
+	for rows.Next() {
+		e, err := scanExpense(rows)
+		if err != nil {
+			log.Printf("Error scanning expense: %v", err)
+			continue
+		}
+		expenses = append(expenses, e)
+	}
+
+	jsonResponse(w, expenses, http.StatusOK)
+}
+
+func getExpenseByID(w http.ResponseWriter, id int) {
+	e, err := loadExpense(id)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Expense not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting expense: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, e, http.StatusOK)
+}
+
+func createExpense(w http.ResponseWriter, r *http.Request) {
+	var e Expense
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if msg := validateExpense(&e); msg != "" {
+		jsonError(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO expenses (property_id, maintenance_request_id, category, vendor, amount, expense_date, description)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, e.PropertyID, e.MaintenanceRequestID, e.Category, e.Vendor, e.Amount, e.ExpenseDate, e.Description)
+	if err != nil {
+		log.Printf("Error creating expense: %v", err)
+		jsonError(w, "Failed to create expense", http.StatusInternalServerError)
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	created, err := loadExpense(int(id))
+	if err != nil {
+		log.Printf("Error loading expense: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, created, http.StatusCreated)
+}
+
+// updateExpense replaces an expense's details; its receipt is kept.
+func updateExpense(w http.ResponseWriter, r *http.Request, id int) {
+	var e Expense
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if msg := validateExpense(&e); msg != "" {
+		jsonError(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	result, err := db.Exec(`
+		UPDATE expenses SET property_id=?, maintenance_request_id=?, category=?, vendor=?, amount=?,
+			expense_date=?, description=?
+		WHERE id=?
+	`, e.PropertyID, e.MaintenanceRequestID, e.Category, e.Vendor, e.Amount, e.ExpenseDate, e.Description, id)
+	if err != nil {
+		log.Printf("Error updating expense: %v", err)
+		jsonError(w, "Failed to update expense", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		jsonError(w, "Expense not found", http.StatusNotFound)
+		return
+	}
+
+	getExpenseByID(w, id)
+}
+
+func deleteExpense(w http.ResponseWriter, id int) {
+	e, err := loadExpense(id)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Expense not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := db.Exec("DELETE FROM expenses WHERE id = ?", id); err != nil {
+		log.Printf("Error deleting expense: %v", err)
+		jsonError(w, "Failed to delete expense", http.StatusInternalServerError)
+		return
+	}
+	if e.receiptKey != "" {
+		removeReceipt(e.receiptKey)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// uploadExpenseReceipt accepts multipart/form-data with a "file" part: a
+// PDF or a JPEG, PNG or WebP photo. It replaces any earlier receipt.
+func uploadExpenseReceipt(w http.ResponseWriter, r *http.Request, id int) {
+	e, err := loadExpense(id)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Expense not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	// limitRequestBody has already capped the body for upload routes.
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			jsonError(w, "Upload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		jsonError(w, "Expected multipart/form-data with a file field", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		jsonError(w, "A file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	if header.Size > cfg.Media.MaxUploadBytes {
+		jsonError(w, "Upload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		jsonError(w, "Failed to read upload", http.StatusBadRequest)
+		return
+	}
+
+	// Trust the bytes, not the filename or client-declared type.
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedReceiptTypes[contentType]
+	if !ok {
+		jsonError(w, "Receipts must be PDF, JPEG, PNG or WebP", http.StatusBadRequest)
+		return
+	}
+
+	key := fmt.Sprintf("receipts/%d/%s.%s", id, generateToken()[:20], ext)
+	if err := receiptStore.Save(key, data); err != nil {
+		log.Printf("Error saving receipt %s: %v", key, err)
+		jsonError(w, "Failed to store receipt", http.StatusInternalServerError)
+		return
+	}
+	name := path.Base(strings.ReplaceAll(header.Filename, `\`, "/"))
+	if _, err := db.Exec("UPDATE expenses SET receipt_key = ?, receipt_name = ?, receipt_content_type = ? WHERE id = ?",
+		key, name, contentType, id); err != nil {
+		log.Printf("Error saving receipt: %v", err)
+		removeMedia([]string{key})
+		jsonError(w, "Failed to save receipt", http.StatusInternalServerError)
+		return
+	}
+	if e.receiptKey != "" {
+		removeReceipt(e.receiptKey)
+	}
+
+	getExpenseByID(w, id)
+}
+
+// serveExpenseReceipt streams the stored receipt. Receipts can carry bank
+// details, so they are never cached.
+func serveExpenseReceipt(w http.ResponseWriter, r *http.Request, id int) {
+	var key, name, contentType sql.NullString
+	err := db.QueryRow("SELECT receipt_key, receipt_name, receipt_content_type FROM expenses WHERE id = ?", id).
+		Scan(&key, &name, &contentType)
+	if err == sql.ErrNoRows || (err == nil && key.String == "") {
+		jsonError(w, "Receipt not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	f, err := receiptStore.Open(key.String)
+	if err != nil {
+		log.Printf("Error opening receipt %s: %v", key.String, err)
+		jsonError(w, "Receipt not found", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		jsonError(w, "Failed to read receipt", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", contentType.String)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": name.String}))
+	http.ServeContent(w, r, "", info.ModTime(), f)
+}
+
+func deleteExpenseReceipt(w http.ResponseWriter, id int) {
+	e, err := loadExpense(id)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Expense not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := db.Exec("UPDATE expenses SET receipt_key = NULL, receipt_name = NULL, receipt_content_type = NULL WHERE id = ?", id); err != nil {
+		log.Printf("Error removing receipt: %v", err)
+		jsonError(w, "Failed to remove receipt", http.StatusInternalServerError)
+		return
+	}
+	if e.receiptKey != "" {
+		removeReceipt(e.receiptKey)
+	}
+
+	getExpenseByID(w, id)
+}
+
+func removeReceipt(key string) {
+	if err := receiptStore.Delete(key); err != nil {
+		log.Printf("Error deleting receipt %s: %v", key, err)
+	}
+}
+
+// ============================================================================
+// SCAN HELPERS - EXPENSES
+// ============================================================================
+
+const expenseColumns = `e.id, e.property_id, e.maintenance_request_id, e.category, e.vendor, e.amount,
+	e.expense_date, e.description, e.receipt_key, e.receipt_name, e.created_at, e.updated_at, p.name`
+
+func loadExpense(id int) (Expense, error) {
+	rows, err := db.Query(`SELECT `+expenseColumns+` FROM expenses e JOIN properties p ON p.id = e.property_id WHERE e.id = ?`, id)
+	if err != nil {
+		return Expense{}, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return Expense{}, err
+		}
+		return Expense{}, sql.ErrNoRows
+	}
+	return scanExpense(rows)
+}
+
+func scanExpense(rows *sql.Rows) (Expense, error) {
+	var e Expense
+	var requestID sql.NullInt64
+	var description, receiptKey, receiptName sql.NullString
+	var propertyName string
+	err := rows.Scan(&e.ID, &e.PropertyID, &requestID, &e.Category, &e.Vendor, &e.Amount,
+		&e.ExpenseDate, &description, &receiptKey, &receiptName, &e.CreatedAt, &e.UpdatedAt, &propertyName)
+	if err != nil {
+		return e, err
+	}
+	e.ExpenseDate = dateOnly(e.ExpenseDate)
+	e.MaintenanceRequestID = nullIntPtr(requestID)
+	e.Description = nullStringPtr(description)
+	e.PropertyName = &propertyName
+	if receiptKey.Valid && receiptKey.String != "" {
+		e.receiptKey = receiptKey.String
+		url := fmt.Sprintf("/api/admin/expenses/%d/receipt", e.ID)
+		e.ReceiptURL = &url
+		e.ReceiptName = nullStringPtr(receiptName)
+	}
+	return e, nil
+}
+
+// ============================================================================
+// PROFIT AND LOSS REPORT
+// ============================================================================
+
+// The P&L combines completed payments with expenses over a date range.
+// Deposits are held for the tenant rather than earned, so they are left
+// out; every other payment type is income.
+
+// incomeTypes are the payment types reported as income, in column order.
+// Types outside this list are counted as "other".
+var incomeTypes = []string{"rent", "late_fee", "other"}
+
+// PnLLine is one property's profit and loss, or the portfolio's.
+type PnLLine struct {
+	PropertyID    int                `json:"propertyId,omitempty"`
+	PropertyName  string             `json:"propertyName,omitempty"`
+	Income        map[string]float64 `json:"income"`
+	Expenses      map[string]float64 `json:"expenses"`
+	TotalIncome   float64            `json:"totalIncome"`
+	TotalExpenses float64            `json:"totalExpenses"`
+	NetIncome     float64            `json:"netIncome"`
+}
+
+// PnLReport is the response of GET /api/admin/reports/pnl.
+type PnLReport struct {
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Properties []PnLLine `json:"properties"`
+	Portfolio  PnLLine   `json:"portfolio"`
+}
+
+func newPnLLine(propertyID int, name string) PnLLine {
+	l := PnLLine{PropertyID: propertyID, PropertyName: name, Income: map[string]float64{}, Expenses: map[string]float64{}}
+	for _, t := range incomeTypes {
+		l.Income[t] = 0
+	}
+	for _, c := range expenseCategories {
+		l.Expenses[c] = 0
+	}
+	return l
+}
+
+func (l *PnLLine) add(other PnLLine) {
+	for k, v := range other.Income {
+		l.Income[k] = roundCents(l.Income[k] + v)
+	}
+	for k, v := range other.Expenses {
+		l.Expenses[k] = roundCents(l.Expenses[k] + v)
+	}
+	l.total()
+}
+
+func (l *PnLLine) total() {
+	l.TotalIncome, l.TotalExpenses = 0, 0
+	for _, v := range l.Income {
+		l.TotalIncome += v
+	}
+	for _, v := range l.Expenses {
+		l.TotalExpenses += v
+	}
+	l.TotalIncome = roundCents(l.TotalIncome)
+	l.TotalExpenses = roundCents(l.TotalExpenses)
+	l.NetIncome = roundCents(l.TotalIncome - l.TotalExpenses)
+}
+
+// pnlReportHandler serves the P&L as JSON, or as CSV with ?format=csv. The
+// range is from/to (YYYY-MM-DD, inclusive), year to date by default.
+func pnlReportHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	now := time.Now()
+	from, to := fmt.Sprintf("%d-01-01", now.Year()), now.Format("2006-01-02")
+	if raw := q.Get("from"); raw != "" {
+		from = raw
+	}
+	if raw := q.Get("to"); raw != "" {
+		to = raw
+	}
+	start, err1 := time.Parse("2006-01-02", from)
+	end, err2 := time.Parse("2006-01-02", to)
+	if err1 != nil || err2 != nil {
+		jsonError(w, "from and to must be YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	if end.Before(start) {
+		jsonError(w, "to must not be before from", http.StatusBadRequest)
+		return
+	}
+
+	report, err := buildPnLReport(from, to, q.Get("propertyId"), q.Get("ownerId"))
+	if err != nil {
+		if errors.Is(err, errBadOwnerID) {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error building P&L report: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if q.Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="pnl-%s-to-%s.csv"`, from, to))
+		writePnLCSV(w, report)
+		return
+	}
+	jsonResponse(w, report, http.StatusOK)
+}
+
+// buildPnLReport lists every property that isn't archived or had activity
+// in the range, optionally narrowed to one property or owner.
+func buildPnLReport(from, to, propertyID, ownerID string) (PnLReport, error) {
+	report := PnLReport{From: from, To: to, Properties: []PnLLine{}, Portfolio: newPnLLine(0, "")}
+
+	where, args := "", []interface{}{}
+	if id, err := strconv.Atoi(propertyID); err == nil {
+		where += " AND p.id = ?"
+		args = append(args, id)
+	}
+	clause, ownerArgs, err := ownerFilter(ownerID, "p.id")
+	if err != nil {
+		return report, err
+	}
+	where += clause
+	args = append(args, ownerArgs...)
+
+	lines := map[int]*PnLLine{}
+	var order []int
+	line := func(id int, name string) *PnLLine {
+		if l, ok := lines[id]; ok {
+			return l
+		}
+		l := newPnLLine(id, name)
+		lines[id] = &l
+		order = append(order, id)
+		return &l
+	}
+
+	rows, err := db.Query(`
+		SELECT p.id, p.name, p.listing_status,
+			   pay.payment_type, COALESCE(SUM(pay.amount), 0)
+		FROM properties p
+		LEFT JOIN payments pay ON pay.property_id = p.id AND pay.deleted_at IS NULL
+			AND pay.status = 'completed' AND pay.payment_type <> 'deposit'
+			AND pay.payment_date BETWEEN ? AND ?
+		WHERE 1=1`+where+`
+		GROUP BY p.id, p.name, p.listing_status, pay.payment_type
+		ORDER BY p.name, p.id
+	`, append([]interface{}{from, to}, args...)...)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var name, status string
+		var payType sql.NullString
+		var amount float64
+		if err := rows.Scan(&id, &name, &status, &payType, &amount); err != nil {
+			return report, err
+		}
+		if !payType.Valid && status == listingArchived {
+			continue
+		}
+		l := line(id, name)
+		if payType.Valid {
+			bucket := "other"
+			for _, t := range incomeTypes {
+				if payType.String == t {
+					bucket = t
+				}
+			}
+			l.Income[bucket] = roundCents(l.Income[bucket] + amount)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+	rows.Close()
+
+	rows, err = db.Query(`
+		SELECT p.id, p.name, e.category, SUM(e.amount)
+		FROM expenses e
+		JOIN properties p ON p.id = e.property_id
+		WHERE e.expense_date BETWEEN ? AND ?`+where+`
+		GROUP BY p.id, p.name, e.category
+	`, append([]interface{}{from, to}, args...)...)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var name, category string
+		var amount float64
+		if err := rows.Scan(&id, &name, &category, &amount); err != nil {
+			return report, err
+		}
+		l := line(id, name)
+		l.Expenses[category] = roundCents(l.Expenses[category] + amount)
+	}
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	for _, id := range order {
+		l := lines[id]
+		l.total()
+		report.Properties = append(report.Properties, *l)
+		report.Portfolio.add(*l)
+	}
+	sort.SliceStable(report.Properties, func(i, j int) bool {
+		return report.Properties[i].PropertyName < report.Properties[j].PropertyName
+	})
+	return report, nil
+}
+
+// writePnLCSV writes one row per property and a closing portfolio row,
+// with a column per income type and expense category.
+func writePnLCSV(w io.Writer, report PnLReport) {
+	cw := csv.NewWriter(w)
+	header := []string{"Property ID", "Property"}
+	for _, t := range incomeTypes {
+		header = append(header, "Income: "+t)
+	}
+	header = append(header, "Total income")
+	for _, c := range expenseCategories {
+		header = append(header, "Expense: "+c)
+	}
+	header = append(header, "Total expenses", "Net income")
+	cw.Write(header)
+
+	money := func(x float64) string { return strconv.FormatFloat(x, 'f', 2, 64) }
+	row := func(id, name string, l PnLLine) {
+		rec := []string{id, name}
+		for _, t := range incomeTypes {
+			rec = append(rec, money(l.Income[t]))
+		}
+		rec = append(rec, money(l.TotalIncome))
+		for _, c := range expenseCategories {
+			rec = append(rec, money(l.Expenses[c]))
+		}
+		rec = append(rec, money(l.TotalExpenses), money(l.NetIncome))
+		cw.Write(rec)
+	}
+	for _, l := range report.Properties {
+		row(strconv.Itoa(l.PropertyID), l.PropertyName, l)
+	}
+	row("", "Portfolio", report.Portfolio)
+	cw.Flush()
+}
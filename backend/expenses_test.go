@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// uploadReceipt posts data as an expense's receipt.
+func (e *testEnv) uploadReceipt(token string, expenseID int, name string, data []byte) *httptest.ResponseRecorder {
+	e.t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile("file", name)
+	part.Write(data)
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/admin/expenses/%d/receipt", expenseID), &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestExpenseCRUD(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Oak House", 1500)
+	other := e.createProperty(token, "Elm House", 1200)
+
+	tenant := e.createTenant(token, "ann@example.com", "password123")
+	e.expect(e.createLease(token, other, tenant, day(-30), day(335)), http.StatusCreated)
+	rec := e.do(http.MethodPost, "/api/tenant/requests", e.tenantToken("ann@example.com", "password123"),
+		MaintenanceRequest{Title: "Leak", Description: "Kitchen sink drips"})
+	e.expect(rec, http.StatusCreated)
+	request := decode[MaintenanceRequest](t, rec)
+
+	for _, bad := range []Expense{
+		{Vendor: "Acme", Amount: 10, ExpenseDate: "2026-03-01"},
+		{PropertyID: pid, Vendor: "Acme", Amount: 0, ExpenseDate: "2026-03-01"},
+		{PropertyID: pid, Vendor: "Acme", Amount: 10, ExpenseDate: "March"},
+		{PropertyID: pid, Vendor: "Acme", Amount: 10, ExpenseDate: "2026-03-01", Category: "snacks"},
+		{PropertyID: 9999, Vendor: "Acme", Amount: 10, ExpenseDate: "2026-03-01"},
+		// The request is for Elm House.
+		{PropertyID: pid, Vendor: "Acme", Amount: 10, ExpenseDate: "2026-03-01", MaintenanceRequestID: &request.ID},
+	} {
+		e.expect(e.do(http.MethodPost, "/api/admin/expenses", token, bad), http.StatusBadRequest)
+	}
+
+	rec = e.do(http.MethodPost, "/api/admin/expenses", token, Expense{
+		PropertyID: other, Vendor: "Drip Plumbing", Amount: 180, ExpenseDate: "2026-03-02", MaintenanceRequestID: &request.ID,
+	})
+	e.expect(rec, http.StatusCreated)
+	expense := decode[Expense](t, rec)
+	if expense.Category != "other" || expense.PropertyName == nil || *expense.PropertyName != "Elm House" {
+		t.Fatalf("unexpected expense %+v", expense)
+	}
+	e.expect(e.do(http.MethodPost, "/api/admin/expenses", token, Expense{
+		PropertyID: pid, Category: "taxes", Vendor: "County", Amount: 2400, ExpenseDate: "2026-04-15",
+	}), http.StatusCreated)
+
+	if got := len(decode[[]Expense](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/expenses?maintenanceRequestId=%d", request.ID), token, nil))); got != 1 {
+		t.Fatalf("expected one expense for the request, got %d", got)
+	}
+	if got := len(decode[[]Expense](t, e.do(http.MethodGet, "/api/admin/expenses?from=2026-04-01&to=2026-04-30", token, nil))); got != 1 {
+		t.Fatalf("expected one expense in April, got %d", got)
+	}
+
+	// Receipts are sniffed, not trusted by name.
+	e.expect(e.uploadReceipt(token, expense.ID, "receipt.pdf", []byte("just some text")), http.StatusBadRequest)
+	rec = e.uploadReceipt(token, expense.ID, "receipt.pdf", []byte("%PDF-1.4\n%fake receipt\n"))
+	e.expect(rec, http.StatusOK)
+	withReceipt := decode[Expense](t, rec)
+	if withReceipt.ReceiptURL == nil || withReceipt.ReceiptName == nil || *withReceipt.ReceiptName != "receipt.pdf" {
+		t.Fatalf("expected a receipt, got %+v", withReceipt)
+	}
+	// Receipts are kept out of the public media directory and only served
+	// to admins, uncached.
+	var key string
+	db.QueryRow("SELECT receipt_key FROM expenses WHERE id = ?", expense.ID).Scan(&key)
+	file := filepath.Join(cfg.Media.PrivateDir, filepath.FromSlash(key))
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("receipt not stored: %v", err)
+	}
+	e.expect(e.do(http.MethodGet, *withReceipt.ReceiptURL, "", nil), http.StatusUnauthorized)
+	rec = e.do(http.MethodGet, *withReceipt.ReceiptURL, token, nil)
+	e.expect(rec, http.StatusOK)
+	if rec.Header().Get("Cache-Control") != "no-store" || rec.Header().Get("Content-Type") != "application/pdf" ||
+		!strings.HasPrefix(rec.Body.String(), "%PDF-1.4") {
+		t.Fatalf("unexpected receipt response %v %q", rec.Header(), rec.Body.String())
+	}
+	// Receipts left in the media directory by earlier versions aren't served.
+	legacy := filepath.Join(cfg.Media.Dir, "receipts", "1", "old.pdf")
+	os.MkdirAll(filepath.Dir(legacy), 0o755)
+	os.WriteFile(legacy, []byte("%PDF-1.4\n"), 0o644)
+	e.expect(e.do(http.MethodGet, "/media/receipts/1/old.pdf", "", nil), http.StatusNotFound)
+
+	// Editing keeps the receipt.
+	path := fmt.Sprintf("/api/admin/expenses/%d", expense.ID)
+	withReceipt.Category = "repairs"
+	rec = e.do(http.MethodPut, path, token, withReceipt)
+	e.expect(rec, http.StatusOK)
+	if got := decode[Expense](t, rec); got.Category != "repairs" || got.ReceiptURL == nil {
+		t.Fatalf("unexpected updated expense %+v", got)
+	}
+
+	e.expect(e.do(http.MethodDelete, path, token, nil), http.StatusNoContent)
+	e.expect(e.do(http.MethodGet, path, token, nil), http.StatusNotFound)
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("expected the receipt to be removed, got %v", err)
+	}
+}
+
+func TestPnLReport(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	owner := e.createOwner(token, "Maple Holdings", "maple@example.com", 10)
+	oak := e.createListing(token, Property{Name: "Oak House", OwnerID: &owner.ID})
+	elm := e.createListing(token, Property{Name: "Elm House"})
+	tenant := e.createTenant(token, "ann@example.com", "password123")
+	rec := e.createLease(token, oak.ID, tenant, "2026-01-01", "2026-12-31")
+	e.expect(rec, http.StatusCreated)
+	lease := decode[Lease](t, rec).ID
+
+	for _, p := range []Payment{
+		{LeaseID: lease, Amount: 1500, PaymentDate: "2026-03-01"},
+		{LeaseID: lease, Amount: 50, PaymentDate: "2026-03-08", PaymentType: "late_fee"},
+		{LeaseID: lease, Amount: 1500, PaymentDate: "2026-03-01", PaymentType: "deposit"},
+		{LeaseID: lease, Amount: 1500, PaymentDate: "2026-04-01", Status: "pending"},
+		{LeaseID: lease, Amount: 1500, PaymentDate: "2026-05-01"},
+	} {
+		e.expect(e.do(http.MethodPost, "/api/admin/payments", token, p), http.StatusCreated)
+	}
+	for _, x := range []Expense{
+		{PropertyID: oak.ID, Category: "repairs", Vendor: "Fixit", Amount: 200, ExpenseDate: "2026-03-10"},
+		{PropertyID: oak.ID, Category: "insurance", Vendor: "Acme Mutual", Amount: 300, ExpenseDate: "2026-04-01"},
+		{PropertyID: elm.ID, Category: "utilities", Vendor: "City Water", Amount: 75.5, ExpenseDate: "2026-03-20"},
+		{PropertyID: elm.ID, Category: "hoa", Vendor: "Elm HOA", Amount: 100, ExpenseDate: "2026-06-01"},
+	} {
+		e.expect(e.do(http.MethodPost, "/api/admin/expenses", token, x), http.StatusCreated)
+	}
+
+	rec = e.do(http.MethodGet, "/api/admin/reports/pnl?from=2026-03-01&to=2026-04-30", token, nil)
+	e.expect(rec, http.StatusOK)
+	report := decode[PnLReport](t, rec)
+	if len(report.Properties) != 2 {
+		t.Fatalf("expected two properties, got %+v", report.Properties)
+	}
+	if o := report.Properties[1]; o.PropertyID != oak.ID || o.Income["rent"] != 1500 || o.Income["late_fee"] != 50 ||
+		o.TotalIncome != 1550 || o.Expenses["repairs"] != 200 || o.TotalExpenses != 500 || o.NetIncome != 1050 {
+		t.Fatalf("unexpected Oak House line %+v", o)
+	}
+	p := report.Portfolio
+	if p.TotalIncome != 1550 || p.TotalExpenses != 575.5 || p.NetIncome != 974.5 || p.Expenses["utilities"] != 75.5 {
+		t.Fatalf("unexpected portfolio %+v", p)
+	}
+
+	owned := decode[PnLReport](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/reports/pnl?from=2026-03-01&to=2026-04-30&ownerId=%d", owner.ID), token, nil))
+	if len(owned.Properties) != 1 || owned.Portfolio.NetIncome != 1050 {
+		t.Fatalf("unexpected owner P&L %+v", owned)
+	}
+
+	rec = e.do(http.MethodGet, "/api/admin/reports/pnl?from=2026-03-01&to=2026-04-30&format=csv", token, nil)
+	e.expect(rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(rows) != 4 || rows[3][1] != "Portfolio" || rows[3][len(rows[3])-1] != "974.50" {
+		t.Fatalf("unexpected csv %v", rows)
+	}
+
+	e.expect(e.do(http.MethodGet, "/api/admin/reports/pnl?from=2026-05-01&to=2026-04-01", token, nil), http.StatusBadRequest)
+
+	// Repairs come off the owner's statement.
+	statement := decode[OwnerStatement](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/owners/%d/statement?month=2026-03", owner.ID), token, nil))
+	if statement.GrossRent != 1550 || statement.ManagementFee != 155 || statement.MaintenanceExpenses != 200 || statement.NetDistribution != 1195 {
+		t.Fatalf("unexpected statement %+v", statement)
+	}
+}
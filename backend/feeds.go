@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// LISTING FEEDS
+// ============================================================================
+
+// Rental portals poll the feeds; let them and any CDN in front cache for a
+// while, and revalidate cheaply with the ETag afterwards.
+const feedCacheControl = "public, max-age=900"
+
+// FeedListing is one property as published to rental portals. The JSON feed
+// uses it directly; the XML feed maps it to portal element names.
+type FeedListing struct {
+	ID            int         `json:"id"`
+	URL           string      `json:"url"`
+	Title         string      `json:"title"`
+	PropertyType  string      `json:"propertyType"`
+	Address       FeedAddress `json:"address"`
+	MonthlyRent   float64     `json:"monthlyRent"`
+	DepositAmount *float64    `json:"depositAmount,omitempty"`
+	Bedrooms      int         `json:"bedrooms"`
+	Bathrooms     float64     `json:"bathrooms"`
+	SquareFeet    *int        `json:"squareFeet,omitempty"`
+	AvailableDate *string     `json:"availableDate,omitempty"`
+	Description   *string     `json:"description,omitempty"`
+	Amenities     []string    `json:"amenities"`
+	PetPolicy     *string     `json:"petPolicy,omitempty"`
+	Photos        []FeedPhoto `json:"photos"`
+	UpdatedAt     time.Time   `json:"updatedAt"`
+}
+
+type FeedAddress struct {
+	Street    string   `json:"street"`
+	Unit      *string  `json:"unit,omitempty"`
+	City      string   `json:"city"`
+	State     string   `json:"state"`
+	Zip       string   `json:"zip"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+type FeedPhoto struct {
+	URL     string  `json:"url"`
+	Caption *string `json:"caption,omitempty"`
+}
+
+// listingsFeedHandler serves /api/feeds/listings.xml and
+// /api/feeds/listings.json.
+func listingsFeedHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/api/feeds/")
+	if name != "listings.xml" && name != "listings.json" {
+		jsonError(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	listings, err := loadFeedListings()
+	if err != nil {
+		log.Printf("Error building listings feed: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	var body []byte
+	if name == "listings.xml" {
+		body, err = encodeXMLFeed(listings)
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	} else {
+		body, err = json.Marshal(map[string]interface{}{"listings": listings})
+		w.Header().Set("Content-Type", "application/json")
+	}
+	if err != nil {
+		log.Printf("Error encoding listings feed: %v", err)
+		jsonError(w, "Failed to build feed", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Cache-Control", feedCacheControl)
+	// ServeContent answers If-None-Match with a 304. There is deliberately no
+	// Last-Modified: the newest change among the listings still in the feed
+	// does not move when one drops out, so If-Modified-Since would keep a
+	// leased or unlisted unit alive at the portals. The ETag covers removals.
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(body))
+}
+
+// loadFeedListings returns the listed, syndicated, available properties.
+func loadFeedListings() ([]FeedListing, error) {
+	rows, err := db.Query(`
+		SELECT ` + propertyColumns + `
+		FROM properties WHERE available = TRUE AND syndicate = TRUE AND listing_status = 'listed'
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	var properties []Property
+	for rows.Next() {
+		p, err := scanProperty(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		properties = append(properties, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	listings := []FeedListing{}
+	for _, p := range properties {
+		images, err := loadPropertyImages(p.ID)
+		if err != nil {
+			return nil, err
+		}
+		listings = append(listings, feedListing(p, images))
+	}
+	return listings, nil
+}
+
+func feedListing(p Property, images []PropertyImage) FeedListing {
+	l := FeedListing{
+		ID:           p.ID,
+		URL:          propertyURL(p),
+		Title:        p.Name,
+		PropertyType: p.PropertyType,
+		Address: FeedAddress{
+			Street: p.AddressLine1, Unit: p.AddressLine2, City: p.City, State: p.State, Zip: p.Zip,
+			Latitude: p.Latitude, Longitude: p.Longitude,
+		},
+		MonthlyRent:   p.MonthlyRent,
+		DepositAmount: p.DepositAmount,
+		Bedrooms:      p.Bedrooms,
+		Bathrooms:     p.Bathrooms,
+		SquareFeet:    p.SquareFeet,
+		AvailableDate: p.AvailableDate,
+		Description:   p.Description,
+		Amenities:     p.Amenities,
+		PetPolicy:     p.PetPolicy,
+		Photos:        []FeedPhoto{},
+		UpdatedAt:     p.UpdatedAt,
+	}
+	if l.Amenities == nil {
+		l.Amenities = []string{}
+	}
+	if l.AvailableDate != nil {
+		date := dateOnly(*l.AvailableDate)
+		l.AvailableDate = &date
+	}
+	for _, img := range images {
+		l.Photos = append(l.Photos, FeedPhoto{URL: absoluteURL(img.URLs.Detail), Caption: img.Caption})
+	}
+	// Properties from before galleries only have imageUrl.
+	if len(l.Photos) == 0 && p.ImageURL != nil {
+		l.Photos = append(l.Photos, FeedPhoto{URL: absoluteURL(*p.ImageURL)})
+	}
+	return l
+}
+
+// absoluteURL resolves a root-relative URL (such as a local /media/ path)
+// against the public API address; portals fetch photos from elsewhere.
+func absoluteURL(u string) string {
+	if strings.HasPrefix(u, "/") && !strings.HasPrefix(u, "//") {
+		return strings.TrimSuffix(cfg.Mail.APIURL, "/") + u
+	}
+	return u
+}
+
+// ============================================================================
+// XML FEED
+// ============================================================================
+
+// The XML feed follows the Zillow rental listing feed layout that most
+// portals (and RentalSource-style aggregators) accept.
+type xmlFeed struct {
+	XMLName  xml.Name     `xml:"Listings"`
+	Listings []xmlListing `xml:"Listing"`
+}
+
+type xmlListing struct {
+	ID       int `xml:"id,attr"`
+	Location struct {
+		StreetAddress string   `xml:"StreetAddress"`
+		UnitNumber    *string  `xml:"UnitNumber,omitempty"`
+		City          string   `xml:"City"`
+		State         string   `xml:"State"`
+		Zip           string   `xml:"Zip"`
+		Lat           *float64 `xml:"Lat,omitempty"`
+		Long          *float64 `xml:"Long,omitempty"`
+	} `xml:"Location"`
+	ListingDetails struct {
+		Status        string  `xml:"Status"`
+		Price         string  `xml:"Price"`
+		ListingURL    string  `xml:"ListingUrl"`
+		DateAvailable *string `xml:"DateAvailable,omitempty"`
+	} `xml:"ListingDetails"`
+	RentalDetails struct {
+		Deposit     *string `xml:"Deposit,omitempty"`
+		CatsAllowed *string `xml:"PetsAllowed>Cats,omitempty"`
+		DogsAllowed *string `xml:"PetsAllowed>Dogs,omitempty"`
+	} `xml:"RentalDetails"`
+	BasicDetails struct {
+		PropertyType string  `xml:"PropertyType"`
+		Title        string  `xml:"Title"`
+		Description  *string `xml:"Description,omitempty"`
+		Bedrooms     int     `xml:"Bedrooms"`
+		Bathrooms    float64 `xml:"Bathrooms"`
+		LivingArea   *int    `xml:"LivingArea,omitempty"`
+	} `xml:"BasicDetails"`
+	Pictures  []xmlPicture `xml:"Pictures>Picture"`
+	Amenities []string     `xml:"Amenities>Amenity"`
+}
+
+type xmlPicture struct {
+	URL     string  `xml:"PictureUrl"`
+	Caption *string `xml:"Caption,omitempty"`
+}
+
+func encodeXMLFeed(listings []FeedListing) ([]byte, error) {
+	feed := xmlFeed{Listings: []xmlListing{}}
+	yesNo := func(b bool) *string {
+		s := "no"
+		if b {
+			s = "yes"
+		}
+		return &s
+	}
+	for _, l := range listings {
+		var x xmlListing
+		x.ID = l.ID
+		x.Location.StreetAddress = l.Address.Street
+		x.Location.UnitNumber = l.Address.Unit
+		x.Location.City, x.Location.State, x.Location.Zip = l.Address.City, l.Address.State, l.Address.Zip
+		x.Location.Lat, x.Location.Long = l.Address.Latitude, l.Address.Longitude
+		x.ListingDetails.Status = "For Rent"
+		x.ListingDetails.Price = fmt.Sprintf("%.2f", l.MonthlyRent)
+		x.ListingDetails.ListingURL = l.URL
+		x.ListingDetails.DateAvailable = l.AvailableDate
+		if l.DepositAmount != nil {
+			deposit := fmt.Sprintf("%.2f", *l.DepositAmount)
+			x.RentalDetails.Deposit = &deposit
+		}
+		if l.PetPolicy != nil {
+			x.RentalDetails.CatsAllowed = yesNo(*l.PetPolicy == "cats" || *l.PetPolicy == "cats_and_dogs")
+			x.RentalDetails.DogsAllowed = yesNo(*l.PetPolicy == "dogs" || *l.PetPolicy == "cats_and_dogs")
+		}
+		x.BasicDetails.PropertyType = l.PropertyType
+		x.BasicDetails.Title = l.Title
+		x.BasicDetails.Description = l.Description
+		x.BasicDetails.Bedrooms = l.Bedrooms
+		x.BasicDetails.Bathrooms = l.Bathrooms
+		x.BasicDetails.LivingArea = l.SquareFeet
+		for _, photo := range l.Photos {
+			x.Pictures = append(x.Pictures, xmlPicture{URL: photo.URL, Caption: photo.Caption})
+		}
+		x.Amenities = l.Amenities
+		feed.Listings = append(feed.Listings, x)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
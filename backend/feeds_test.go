@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListingFeeds(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	deposit := 1500.0
+	listed := e.createListing(token, Property{
+		Name: "Feed Flat", Bedrooms: 2, Bathrooms: 1.5, MonthlyRent: 1750, DepositAmount: &deposit,
+		Amenities: []string{"Dishwasher", "Parking"}, AvailableDate: strPtr(day(14)), PetPolicy: strPtr("cats"),
+	})
+	e.expect(e.uploadImage(token, listed.ID, testPNG(t, 40, 20), map[string]string{"caption": "Living room"}), http.StatusCreated)
+
+	optedOut := e.createListing(token, Property{Name: "Private Listing"})
+	optedOut.Syndicate = new(bool)
+	e.expect(e.do(http.MethodPut, fmt.Sprintf("/api/admin/properties/%d", optedOut.ID), token, optedOut), http.StatusOK)
+
+	let := e.createListing(token, Property{Name: "Let Already"})
+	let.Available = false
+	let.Syndicate = nil // omitted: keeps the current value
+	e.expect(e.do(http.MethodPut, fmt.Sprintf("/api/admin/properties/%d", let.ID), token, let), http.StatusOK)
+	if got := decode[Property](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/properties/%d", let.ID), token, nil)); got.Syndicate == nil || !*got.Syndicate {
+		t.Fatalf("expected syndicate to stay on when omitted, got %v", got.Syndicate)
+	}
+
+	rec := e.do(http.MethodGet, "/api/feeds/listings.json", "", nil)
+	e.expect(rec, http.StatusOK)
+	if cc := rec.Header().Get("Cache-Control"); cc != feedCacheControl {
+		t.Errorf("unexpected Cache-Control %q", cc)
+	}
+	if lm := rec.Header().Get("Last-Modified"); lm != "" {
+		t.Errorf("unexpected Last-Modified %q", lm)
+	}
+	feed := decode[struct {
+		Listings []FeedListing `json:"listings"`
+	}](t, rec)
+	if len(feed.Listings) != 1 {
+		t.Fatalf("expected only the syndicated, available listing, got %+v", feed.Listings)
+	}
+	l := feed.Listings[0]
+	if l.Title != "Feed Flat" || l.MonthlyRent != 1750 || *l.DepositAmount != 1500 || l.Bathrooms != 1.5 ||
+		*l.AvailableDate != day(14) || len(l.Amenities) != 2 || l.Address.Latitude == nil {
+		t.Fatalf("unexpected listing: %+v", l)
+	}
+	if len(l.Photos) != 1 || !strings.HasPrefix(l.Photos[0].URL, "http://localhost:8080/media/") || *l.Photos[0].Caption != "Living room" {
+		t.Fatalf("expected one absolute photo URL, got %+v", l.Photos)
+	}
+	if l.URL != "http://localhost:3000/properties/feed-flat-portland" {
+		t.Fatalf("unexpected listing URL %q", l.URL)
+	}
+
+	// Revalidation with the ETag is answered without a body.
+	req := httptest.NewRequest(http.MethodGet, "/api/feeds/listings.json", nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	notModified := httptest.NewRecorder()
+	e.handler.ServeHTTP(notModified, req)
+	if notModified.Code != http.StatusNotModified || notModified.Body.Len() != 0 {
+		t.Fatalf("expected 304 with no body, got %d (%d bytes)", notModified.Code, notModified.Body.Len())
+	}
+
+	// If-Modified-Since alone never gets a 304: Last-Modified could not
+	// account for listings that dropped out of the feed.
+	req = httptest.NewRequest(http.MethodGet, "/api/feeds/listings.json", nil)
+	req.Header.Set("If-Modified-Since", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	revalidated := httptest.NewRecorder()
+	e.handler.ServeHTTP(revalidated, req)
+	if revalidated.Code != http.StatusOK {
+		t.Fatalf("expected a full feed for If-Modified-Since, got %d", revalidated.Code)
+	}
+
+	rec = e.do(http.MethodGet, "/api/feeds/listings.xml", "", nil)
+	e.expect(rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+		t.Errorf("unexpected Content-Type %q", ct)
+	}
+	var x struct {
+		Listings []struct {
+			ID    int      `xml:"id,attr"`
+			Price string   `xml:"ListingDetails>Price"`
+			Cats  string   `xml:"RentalDetails>PetsAllowed>Cats"`
+			Dogs  string   `xml:"RentalDetails>PetsAllowed>Dogs"`
+			Beds  int      `xml:"BasicDetails>Bedrooms"`
+			Pics  []string `xml:"Pictures>Picture>PictureUrl"`
+			Amen  []string `xml:"Amenities>Amenity"`
+		} `xml:"Listing"`
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &x); err != nil {
+		t.Fatalf("invalid XML feed: %v\n%s", err, rec.Body.String())
+	}
+	if len(x.Listings) != 1 {
+		t.Fatalf("expected one XML listing, got %d", len(x.Listings))
+	}
+	xl := x.Listings[0]
+	if xl.ID != listed.ID || xl.Price != "1750.00" || xl.Cats != "yes" || xl.Dogs != "no" || xl.Beds != 2 || len(xl.Pics) != 1 || len(xl.Amen) != 2 {
+		t.Fatalf("unexpected XML listing: %+v", xl)
+	}
+
+	e.expect(e.do(http.MethodGet, "/api/feeds/listings.csv", "", nil), http.StatusNotFound)
+	e.expect(e.do(http.MethodPost, "/api/feeds/listings.xml", "", nil), http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// MODELS - LEADS
+// ============================================================================
+
+type Lead struct {
+	ID         int        `json:"id"`
+	PropertyID *int       `json:"propertyId,omitempty"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email"`
+	Phone      *string    `json:"phone,omitempty"`
+	Message    string     `json:"message"`
+	Status     string     `json:"status"`
+	Source     string     `json:"source"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	Notes      []LeadNote `json:"notes"`
+	// Joined fields
+	PropertyName *string `json:"propertyName,omitempty"`
+}
+
+type LeadNote struct {
+	ID        int       `json:"id"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ContactRequest is the public contact form. Website is a honeypot: it is
+// hidden from people, so anything filling it in is a bot.
+type ContactRequest struct {
+	Name       string  `json:"name"`
+	Email      string  `json:"email"`
+	Phone      *string `json:"phone"`
+	Message    string  `json:"message"`
+	PropertyID *int    `json:"propertyId"`
+	Website    string  `json:"website"`
+}
+
+var leadStatuses = map[string]bool{
+	"new":               true,
+	"contacted":         true,
+	"showing_scheduled": true,
+	"converted":         true,
+	"lost":              true,
+}
+
+// contactLimiter throttles POST /api/contact per client IP.
+var contactLimiter = newRateLimiter()
+
+// ============================================================================
+// HANDLERS - PUBLIC CONTACT FORM
+// ============================================================================
+
+func contactHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ip := clientIP(r)
+	if ok, retry := contactLimiter.allow(ip, cfg.Contact.RateLimit, cfg.Contact.RateWindow, time.Now()); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+		jsonError(w, "Too many messages, please try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	var req ContactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	received := map[string]string{"message": "Thanks, we'll be in touch soon"}
+
+	// Bots get the same answer as people so they have nothing to tune against.
+	if req.Website != "" {
+		log.Printf("Contact form honeypot tripped from %s", ip)
+		jsonResponse(w, received, http.StatusCreated)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Email = strings.TrimSpace(req.Email)
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Name == "" || req.Email == "" || req.Message == "" {
+		jsonError(w, "Name, email, and message are required", http.StatusBadRequest)
+		return
+	}
+	if _, err := mail.ParseAddress(req.Email); err != nil {
+		jsonError(w, "Invalid email address", http.StatusBadRequest)
+		return
+	}
+
+	// Only listings visitors can see take enquiries; drafts, unlisted and
+	// archived properties read as missing.
+	if req.PropertyID != nil {
+		listed, err := publiclyListed(*req.PropertyID)
+		if err != nil {
+			log.Printf("Error checking property listing status: %v", err)
+			jsonError(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		if !listed {
+			jsonError(w, "Property not found", http.StatusBadRequest)
+			return
+		}
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO leads (property_id, name, email, phone, message, status, source, ip_address)
+		VALUES (?, ?, ?, ?, ?, 'new', 'contact_form', ?)
+	`, req.PropertyID, req.Name, req.Email, req.Phone, req.Message, ip)
+	if err != nil {
+		log.Printf("Error creating lead: %v", err)
+		jsonError(w, "Failed to send message", http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, received, http.StatusCreated)
+}
+
+// ============================================================================
+// HANDLERS - ADMIN LEADS
+// ============================================================================
+
+const leadColumns = `
+	l.id, l.property_id, l.name, l.email, l.phone, l.message, l.status, l.source,
+	l.created_at, l.updated_at, p.name as property_name`
+
+func adminLeadsHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := `SELECT ` + leadColumns + `
+		FROM leads l
+		LEFT JOIN properties p ON l.property_id = p.id
+		WHERE 1=1
+	`
+	args := []interface{}{}
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		query += " AND l.status = ?"
+		args = append(args, status)
+	}
+	if propertyID := r.URL.Query().Get("propertyId"); propertyID != "" {
+		if id, err := strconv.Atoi(propertyID); err == nil {
+			query += " AND l.property_id = ?"
+			args = append(args, id)
+		}
+	}
+
+	clause, ownerArgs, err := ownerFilter(r.URL.Query().Get("ownerId"), "l.property_id")
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query += clause
+	args = append(args, ownerArgs...)
+
+	query += " ORDER BY l.created_at DESC, l.id DESC"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("Error querying leads: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	leads := []Lead{}
+	for rows.Next() {
+		l, err := scanLead(rows)
+		if err != nil {
+			log.Printf("Error scanning lead: %v", err)
+			continue
+		}
+		leads = append(leads, l)
+	}
+
+	jsonResponse(w, leads, http.StatusOK)
+}
+
+func adminLeadByIDHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+
+	id, action, err := extractIDAndAction(r.URL.Path, "/api/admin/leads/")
+	if err != nil {
+		jsonError(w, "Invalid lead ID", http.StatusBadRequest)
+		return
+	}
+
+	switch action {
+	case "":
+	case "notes":
+		if r.Method != http.MethodPost {
+			jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		addLeadNote(w, r, id)
+		return
+	default:
+		jsonError(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		getLeadByID(w, id)
+	case http.MethodPut:
+		updateLead(w, r, id)
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func getLeadByID(w http.ResponseWriter, id int) {
+	l, err := loadLead(id)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Lead not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting lead: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, l, http.StatusOK)
+}
+
+func updateLead(w http.ResponseWriter, r *http.Request, id int) {
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if !leadStatuses[body.Status] {
+		jsonError(w, "Status must be one of new, contacted, showing_scheduled, converted, lost", http.StatusBadRequest)
+		return
+	}
+
+	// MySQL reports zero affected rows when the status is unchanged, so a
+	// missing lead is detected by the reload instead.
+	if _, err := db.Exec("UPDATE leads SET status = ? WHERE id = ?", body.Status, id); err != nil {
+		log.Printf("Error updating lead: %v", err)
+		jsonError(w, "Failed to update lead", http.StatusInternalServerError)
+		return
+	}
+
+	getLeadByID(w, id)
+}
+
+func addLeadNote(w http.ResponseWriter, r *http.Request, id int) {
+	var body struct {
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	body.Note = strings.TrimSpace(body.Note)
+	if body.Note == "" {
+		jsonError(w, "Note is required", http.StatusBadRequest)
+		return
+	}
+
+	var exists int
+	db.QueryRow("SELECT COUNT(*) FROM leads WHERE id = ?", id).Scan(&exists)
+	if exists == 0 {
+		jsonError(w, "Lead not found", http.StatusNotFound)
+		return
+	}
+
+	if _, err := db.Exec("INSERT INTO lead_notes (lead_id, note) VALUES (?, ?)", id, body.Note); err != nil {
+		log.Printf("Error adding lead note: %v", err)
+		jsonError(w, "Failed to add note", http.StatusInternalServerError)
+		return
+	}
+
+	l, err := loadLead(id)
+	if err != nil {
+		log.Printf("Error getting lead: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, l, http.StatusCreated)
+}
+
+// ============================================================================
+// SCAN HELPERS - LEADS
+// ============================================================================
+
+// loadLead reads one lead with its notes.
+func loadLead(id int) (Lead, error) {
+	rows, err := db.Query(`SELECT `+leadColumns+`
+		FROM leads l
+		LEFT JOIN properties p ON l.property_id = p.id
+		WHERE l.id = ?
+	`, id)
+	if err != nil {
+		return Lead{}, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return Lead{}, err
+		}
+		return Lead{}, sql.ErrNoRows
+	}
+	l, err := scanLead(rows)
+	if err != nil {
+		return l, err
+	}
+	rows.Close()
+
+	noteRows, err := db.Query("SELECT id, note, created_at FROM lead_notes WHERE lead_id = ? ORDER BY created_at, id", id)
+	if err != nil {
+		return l, err
+	}
+	defer noteRows.Close()
+	for noteRows.Next() {
+		var n LeadNote
+		if err := noteRows.Scan(&n.ID, &n.Note, &n.CreatedAt); err != nil {
+			return l, err
+		}
+		l.Notes = append(l.Notes, n)
+	}
+
+	return l, noteRows.Err()
+}
+
+func scanLead(rows *sql.Rows) (Lead, error) {
+	var l Lead
+	var propertyID sql.NullInt64
+	var phone, propertyName sql.NullString
+
+	err := rows.Scan(&l.ID, &propertyID, &l.Name, &l.Email, &phone, &l.Message, &l.Status, &l.Source,
+		&l.CreatedAt, &l.UpdatedAt, &propertyName)
+	if err != nil {
+		return l, err
+	}
+
+	if propertyID.Valid {
+		id := int(propertyID.Int64)
+		l.PropertyID = &id
+	}
+	l.Phone = nullStringPtr(phone)
+	l.PropertyName = nullStringPtr(propertyName)
+	l.Notes = []LeadNote{}
+
+	return l, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestContactFormCreatesLead(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Asked About", 1400)
+	cfg.Contact.RateLimit = 100 // every rejected message counts against it
+
+	e.expect(e.do(http.MethodGet, "/api/contact", "", nil), http.StatusMethodNotAllowed)
+	e.expect(e.do(http.MethodPost, "/api/contact", "", map[string]string{"name": "No Email"}), http.StatusBadRequest)
+	e.expect(e.do(http.MethodPost, "/api/contact", "", map[string]interface{}{
+		"name": "Lost", "email": "lost@example.com", "message": "Hi", "propertyId": 999,
+	}), http.StatusBadRequest)
+	for _, status := range []string{listingDraft, listingUnlisted, listingArchived} {
+		hidden := e.createListing(token, Property{Name: "Hidden " + status, ListingStatus: status})
+		e.expect(e.do(http.MethodPost, "/api/contact", "", map[string]interface{}{
+			"name": "Curious", "email": "curious@example.com", "message": "Hi", "propertyId": hidden.ID,
+		}), http.StatusBadRequest)
+	}
+
+	rec := e.do(http.MethodPost, "/api/contact", "", map[string]interface{}{
+		"name":       "Jordan Visitor",
+		"email":      "jordan@example.com",
+		"phone":      "555-0110",
+		"message":    "Is the unit still available?",
+		"propertyId": pid,
+	})
+	e.expect(rec, http.StatusCreated)
+
+	leads := decode[[]Lead](t, e.do(http.MethodGet, "/api/admin/leads?status=new", token, nil))
+	if len(leads) != 1 || leads[0].Name != "Jordan Visitor" || *leads[0].PropertyID != pid || *leads[0].PropertyName != "Asked About" {
+		t.Fatalf("unexpected leads: %+v", leads)
+	}
+}
+
+func TestContactFormHoneypot(t *testing.T) {
+	e := newTestEnv(t)
+	rec := e.do(http.MethodPost, "/api/contact", "", map[string]string{
+		"name": "Bot", "email": "bot@example.com", "message": "Buy now", "website": "http://spam.example",
+	})
+	// Indistinguishable from success, but nothing is stored.
+	e.expect(rec, http.StatusCreated)
+
+	var n int
+	db.QueryRow("SELECT COUNT(*) FROM leads").Scan(&n)
+	if n != 0 {
+		t.Fatalf("honeypot submission was stored")
+	}
+}
+
+func TestContactFormRateLimit(t *testing.T) {
+	e := newTestEnv(t)
+	cfg.Contact.RateLimit = 2
+	body := map[string]string{"name": "Eager", "email": "eager@example.com", "message": "Hello"}
+
+	e.expect(e.do(http.MethodPost, "/api/contact", "", body), http.StatusCreated)
+	e.expect(e.do(http.MethodPost, "/api/contact", "", body), http.StatusCreated)
+	rec := e.do(http.MethodPost, "/api/contact", "", body)
+	e.expect(rec, http.StatusTooManyRequests)
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("expected Retry-After on 429")
+	}
+}
+
+func TestRateLimiterWindow(t *testing.T) {
+	l := newRateLimiter()
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("1.2.3.4", 3, time.Minute, now); !ok {
+			t.Fatalf("hit %d should be allowed", i+1)
+		}
+	}
+	ok, retry := l.allow("1.2.3.4", 3, time.Minute, now.Add(10*time.Second))
+	if ok || retry != 50*time.Second {
+		t.Fatalf("expected block with 50s retry, got ok=%v retry=%v", ok, retry)
+	}
+	if ok, _ := l.allow("5.6.7.8", 3, time.Minute, now); !ok {
+		t.Fatal("other keys have their own budget")
+	}
+	if ok, _ := l.allow("1.2.3.4", 3, time.Minute, now.Add(time.Minute)); !ok {
+		t.Fatal("a new window should reset the count")
+	}
+}
+
+func TestAdminLeadWorkflow(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	e.expect(e.do(http.MethodPost, "/api/contact", "", map[string]string{
+		"name": "Riley", "email": "riley@example.com", "message": "Do you allow cats?",
+	}), http.StatusCreated)
+	id := decode[[]Lead](t, e.do(http.MethodGet, "/api/admin/leads", token, nil))[0].ID
+	path := fmt.Sprintf("/api/admin/leads/%d", id)
+
+	e.expect(e.do(http.MethodPut, path, token, map[string]string{"status": "archived"}), http.StatusBadRequest)
+	e.expect(e.do(http.MethodPut, path, token, map[string]string{"status": "contacted"}), http.StatusOK)
+	e.expect(e.do(http.MethodPut, "/api/admin/leads/999", token, map[string]string{"status": "lost"}), http.StatusNotFound)
+
+	e.expect(e.do(http.MethodPost, path+"/notes", token, map[string]string{"note": " "}), http.StatusBadRequest)
+	rec := e.do(http.MethodPost, path+"/notes", token, map[string]string{"note": "Called, left voicemail"})
+	e.expect(rec, http.StatusCreated)
+	lead := decode[Lead](t, rec)
+	if lead.Status != "contacted" || len(lead.Notes) != 1 || lead.Notes[0].Note != "Called, left voicemail" {
+		t.Fatalf("unexpected lead: %+v", lead)
+	}
+
+	if got := decode[[]Lead](t, e.do(http.MethodGet, "/api/admin/leads?status=new", token, nil)); len(got) != 0 {
+		t.Fatalf("expected no new leads, got %d", len(got))
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"database/sql"
+	"net/url"
+	"strings"
+)
+
+// ============================================================================
+// LISTING STATUS
+// ============================================================================
+
+// A property's listing status is whether it is shown to the public at all;
+// available is whether it is currently let. Only listed properties appear
+// on the public site, in search, saved-search alerts and the feeds.
+const (
+	listingDraft    = "draft"    // being set up, not yet published
+	listingListed   = "listed"   // public
+	listingUnlisted = "unlisted" // taken off the market by the owner
+	listingArchived = "archived" // deleted; kept for its leases and payments
+)
+
+func validListingStatus(s string) bool {
+	switch s {
+	case listingDraft, listingListed, listingUnlisted, listingArchived:
+		return true
+	}
+	return false
+}
+
+// adminStatusFilter turns the admin list's ?status= (a comma-separated list
+// of statuses, or "all") into a WHERE condition. Without it archived
+// properties are left out.
+func adminStatusFilter(q url.Values) (string, []interface{}, error) {
+	param := q.Get("status")
+	switch param {
+	case "":
+		return "listing_status <> ?", []interface{}{listingArchived}, nil
+	case "all":
+		return "1=1", nil, nil
+	}
+
+	var placeholders []string
+	var args []interface{}
+	for _, status := range strings.Split(param, ",") {
+		status = strings.TrimSpace(status)
+		if !validListingStatus(status) {
+			return "", nil, searchQueryError("Status must be draft, listed, unlisted, archived or all")
+		}
+		placeholders = append(placeholders, "?")
+		args = append(args, status)
+	}
+	return "listing_status IN (" + strings.Join(placeholders, ", ") + ")", args, nil
+}
+
+// publiclyListed reports whether a property exists and is listed. Public
+// endpoints answer 404 for the rest, as if they did not exist.
+func publiclyListed(propertyID int) (bool, error) {
+	var status string
+	err := db.QueryRow("SELECT listing_status FROM properties WHERE id = ?", propertyID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return status == listingListed, err
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestListingStatusVisibility(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	listed := e.createListing(token, Property{Name: "Listed Loft"})
+	if listed.ListingStatus != listingListed {
+		t.Fatalf("expected new properties to be listed, got %q", listed.ListingStatus)
+	}
+	draft := e.createListing(token, Property{Name: "Draft Duplex", ListingStatus: listingDraft})
+	unlisted := e.createListing(token, Property{Name: "Quiet Cottage"})
+	leased := e.createListing(token, Property{Name: "Leased Flat"})
+
+	unlisted.ListingStatus = listingUnlisted
+	e.expect(e.do(http.MethodPut, fmt.Sprintf("/api/admin/properties/%d", unlisted.ID), token, unlisted), http.StatusOK)
+	// available is independent: a let property stays public.
+	leased.Available = false
+	leased.ListingStatus = "" // omitted: keeps the current value
+	if p := decode[Property](t, e.do(http.MethodPut, fmt.Sprintf("/api/admin/properties/%d", leased.ID), token, leased)); p.ListingStatus != listingListed {
+		t.Fatalf("expected the status to be kept when omitted, got %q", p.ListingStatus)
+	}
+
+	if got := e.searchListings(""); fmt.Sprint(got) != "[Listed Loft Leased Flat]" {
+		t.Fatalf("expected only listed properties in public search, got %v", got)
+	}
+	e.expect(e.do(http.MethodGet, fmt.Sprintf("/api/properties/%d", listed.ID), "", nil), http.StatusOK)
+	for _, p := range []Property{draft, unlisted} {
+		e.expect(e.do(http.MethodGet, fmt.Sprintf("/api/properties/%d", p.ID), "", nil), http.StatusNotFound)
+		e.expect(e.do(http.MethodGet, "/api/properties/"+p.Slug, "", nil), http.StatusNotFound)
+		e.expect(e.do(http.MethodGet, fmt.Sprintf("/api/properties/%d/showings", p.ID), "", nil), http.StatusNotFound)
+	}
+	if sitemap := decode[[]SitemapEntry](t, e.do(http.MethodGet, "/api/sitemap", "", nil)); len(sitemap) != 2 {
+		t.Fatalf("expected the two listed properties in the sitemap, got %+v", sitemap)
+	}
+
+	// Deleting archives; the property is still there for the admin.
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/properties/%d", listed.ID), token, nil), http.StatusNoContent)
+	e.expect(e.do(http.MethodGet, fmt.Sprintf("/api/properties/%d", listed.ID), "", nil), http.StatusNotFound)
+	rec := e.do(http.MethodGet, fmt.Sprintf("/api/admin/properties/%d", listed.ID), token, nil)
+	e.expect(rec, http.StatusOK)
+	if p := decode[Property](t, rec); p.ListingStatus != listingArchived {
+		t.Fatalf("expected the deleted property to be archived, got %q", p.ListingStatus)
+	}
+
+	adminList := func(query string) []string {
+		t.Helper()
+		rec := e.do(http.MethodGet, "/api/admin/properties"+query, token, nil)
+		e.expect(rec, http.StatusOK)
+		return listingNames(decode[[]Property](t, rec))
+	}
+	if got := adminList(""); len(got) != 3 {
+		t.Fatalf("expected archived properties hidden by default, got %v", got)
+	}
+	if got := adminList("?status=archived"); fmt.Sprint(got) != "[Listed Loft]" {
+		t.Fatalf("unexpected archived list %v", got)
+	}
+	if got := adminList("?status=draft,unlisted"); len(got) != 2 {
+		t.Fatalf("unexpected draft and unlisted list %v", got)
+	}
+	if got := adminList("?status=all"); len(got) != 4 {
+		t.Fatalf("expected every property with status=all, got %v", got)
+	}
+	e.expect(e.do(http.MethodGet, "/api/admin/properties?status=gone", token, nil), http.StatusBadRequest)
+
+	// Archived properties are restored by relisting them.
+	restore := decode[Property](t, rec)
+	restore.ListingStatus = listingListed
+	e.expect(e.do(http.MethodPut, fmt.Sprintf("/api/admin/properties/%d", listed.ID), token, restore), http.StatusOK)
+	e.expect(e.do(http.MethodGet, fmt.Sprintf("/api/properties/%d", listed.ID), "", nil), http.StatusOK)
+
+	bad := listed
+	bad.ListingStatus = "hidden"
+	e.expect(e.do(http.MethodPut, fmt.Sprintf("/api/admin/properties/%d", listed.ID), token, bad), http.StatusBadRequest)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// MAIL
+// ============================================================================
+
+// Mailer delivers outgoing email. The log and file implementations keep mail
+// on this machine; an SMTP or API-backed sender plugs in behind this
+// interface.
+type Mailer interface {
+	Name() string
+	Send(ctx context.Context, msg MailMessage) error
+}
+
+// MailMessage is a plain-text email. From is filled in from cfg.Mail.From.
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+	// Headers are extra headers such as List-Unsubscribe.
+	Headers map[string]string
+}
+
+const (
+	mailerLog  = "log"
+	mailerFile = "file"
+)
+
+// mailer is selected from cfg.Mail at startup.
+var mailer Mailer = logMailer{from: "listings@localhost"}
+
+func newMailer(c MailConfig) (Mailer, error) {
+	switch c.Provider {
+	case mailerLog:
+		return logMailer{from: c.From}, nil
+	case mailerFile:
+		if c.Dir == "" {
+			return nil, fmt.Errorf("provider %q needs a directory", mailerFile)
+		}
+		return fileMailer{dir: c.Dir, from: c.From}, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q (supported: %s, %s)", c.Provider, mailerLog, mailerFile)
+	}
+}
+
+// logMailer writes each message to the server log instead of sending it.
+type logMailer struct {
+	from string
+}
+
+func (logMailer) Name() string { return mailerLog }
+
+func (m logMailer) Send(ctx context.Context, msg MailMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	log.Printf("Mail from %s to %s: %s\n%s", m.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// fileMailer writes each message as an .eml file in dir, which most mail
+// clients can open.
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+func (fileMailer) Name() string { return mailerFile }
+
+func (m fileMailer) Send(ctx context.Context, msg MailMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	keys := make([]string, 0, len(msg.Headers))
+	for k := range msg.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s: %s\r\n", k, msg.Headers[k])
+	}
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000000"), generateToken()[:8])
+	return os.WriteFile(filepath.Join(m.dir, name), []byte(b.String()), 0o600)
+}
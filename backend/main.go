@@ -1,20 +1,25 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"github.com/joho/godotenv"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -43,26 +48,64 @@ type tenantSession struct {
 // ============================================================================
 
 type Property struct {
-	ID            int       `json:"id"`
-	Name          string    `json:"name"`
-	AddressLine1  string    `json:"addressLine1"`
-	AddressLine2  *string   `json:"addressLine2,omitempty"`
-	City          string    `json:"city"`
-	State         string    `json:"state"`
-	Zip           string    `json:"zip"`
-	PropertyType  string    `json:"propertyType"`
-	Bedrooms      int       `json:"bedrooms"`
-	Bathrooms     float64   `json:"bathrooms"`
-	SquareFeet    *int      `json:"squareFeet,omitempty"`
-	MonthlyRent   float64   `json:"monthlyRent"`
-	DepositAmount *float64  `json:"depositAmount,omitempty"`
-	Available     bool      `json:"available"`
-	AvailableDate *string   `json:"availableDate,omitempty"`
-	Description   *string   `json:"description,omitempty"`
-	Amenities     []string  `json:"amenities,omitempty"`
-	ImageURL      *string   `json:"imageUrl,omitempty"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	ID            int      `json:"id"`
+	Name          string   `json:"name"`
+	AddressLine1  string   `json:"addressLine1"`
+	AddressLine2  *string  `json:"addressLine2,omitempty"`
+	City          string   `json:"city"`
+	State         string   `json:"state"`
+	Zip           string   `json:"zip"`
+	PropertyType  string   `json:"propertyType"`
+	Bedrooms      int      `json:"bedrooms"`
+	Bathrooms     float64  `json:"bathrooms"`
+	SquareFeet    *int     `json:"squareFeet,omitempty"`
+	MonthlyRent   float64  `json:"monthlyRent"`
+	DepositAmount *float64 `json:"depositAmount,omitempty"`
+	Available     bool     `json:"available"`
+	AvailableDate *string  `json:"availableDate,omitempty"`
+	Description   *string  `json:"description,omitempty"`
+	Amenities     []string `json:"amenities,omitempty"`
+	ImageURL      *string  `json:"imageUrl,omitempty"`
+	Latitude      *float64 `json:"latitude,omitempty"`
+	Longitude     *float64 `json:"longitude,omitempty"`
+	PetPolicy     *string  `json:"petPolicy,omitempty"`
+	// Syndicate includes the property in the listing feeds; omitted on
+	// update it keeps its current value.
+	Syndicate *bool `json:"syndicate,omitempty"`
+	// Slug is derived from the name and city and addresses the public
+	// detail page.
+	Slug string `json:"slug,omitempty"`
+	// ListingStatus controls public visibility (see listingstatus.go);
+	// omitted on update it keeps its current value.
+	ListingStatus string `json:"listingStatus"`
+	// OwnerID links the property to its owner (see owners.go). Omitted on
+	// update it keeps its current value; 0 unlinks it.
+	OwnerID   *int      `json:"ownerId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// DistanceMiles is set on radius searches only
+	DistanceMiles *float64 `json:"distanceMiles,omitempty"`
+	// Gallery and units, only loaded on detail endpoints
+	Images []PropertyImage `json:"images,omitempty"`
+	Units  []Unit          `json:"units,omitempty"`
+}
+
+// PublicProperty is a Property as served to anonymous visitors. The
+// shadowing fields are always nil, so the staff-only fields they hide are
+// left out of the JSON.
+type PublicProperty struct {
+	Property
+	Syndicate     *bool   `json:"syndicate,omitempty"`
+	ListingStatus *string `json:"listingStatus,omitempty"`
+	OwnerID       *int    `json:"ownerId,omitempty"`
+}
+
+func publicProperties(properties []Property) []PublicProperty {
+	public := make([]PublicProperty, len(properties))
+	for i, p := range properties {
+		public[i] = PublicProperty{Property: p}
+	}
+	return public
 }
 
 type Tenant struct {
@@ -82,6 +125,7 @@ type Tenant struct {
 type Lease struct {
 	ID            int       `json:"id"`
 	PropertyID    int       `json:"propertyId"`
+	UnitID        *int      `json:"unitId,omitempty"`
 	TenantID      int       `json:"tenantId"`
 	StartDate     string    `json:"startDate"`
 	EndDate       string    `json:"endDate"`
@@ -95,18 +139,24 @@ type Lease struct {
 	// Joined fields for display
 	PropertyName *string `json:"propertyName,omitempty"`
 	TenantName   *string `json:"tenantName,omitempty"`
+	UnitNumber   *string `json:"unitNumber,omitempty"`
 }
 
 type DashboardStats struct {
-	TotalProperties     int     `json:"totalProperties"`
-	AvailableProperties int     `json:"availableProperties"`
-	TotalTenants        int     `json:"totalTenants"`
-	ActiveLeases        int     `json:"activeLeases"`
-	UpcomingLeases      int     `json:"upcomingLeases"`
+	TotalProperties     int `json:"totalProperties"`
+	AvailableProperties int `json:"availableProperties"`
+	TotalTenants        int `json:"totalTenants"`
+	ActiveLeases        int `json:"activeLeases"`
+	UpcomingLeases      int `json:"upcomingLeases"`
 	// TotalLeases is the count of all leases regardless of status.
 	TotalLeases int `json:"totalLeases"`
 	// MonthlyRevenue is the sum of monthly_rent for active leases only.
 	MonthlyRevenue float64 `json:"monthlyRevenue"`
+	// Units across all buildings; occupied means an active lease.
+	TotalUnits    int `json:"totalUnits"`
+	OccupiedUnits int `json:"occupiedUnits"`
+	// Buildings rolls occupancy up per property.
+	Buildings []BuildingOccupancy `json:"buildings"`
 }
 
 type LoginRequest struct {
@@ -124,12 +174,15 @@ type LoginResponse struct {
 
 type ErrorResponse struct {
 	Error string `json:"error"`
+	// RequestID matches the X-Request-ID response header and access log line.
+	RequestID string `json:"requestId,omitempty"`
 }
 
 type MaintenanceRequest struct {
 	ID          int       `json:"id"`
 	TenantID    int       `json:"tenantId"`
 	PropertyID  int       `json:"propertyId"`
+	UnitID      *int      `json:"unitId,omitempty"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	Category    string    `json:"category"`
@@ -141,6 +194,7 @@ type MaintenanceRequest struct {
 	// Joined fields
 	TenantName   *string `json:"tenantName,omitempty"`
 	PropertyName *string `json:"propertyName,omitempty"`
+	UnitNumber   *string `json:"unitNumber,omitempty"`
 }
 
 type Payment struct {
@@ -148,6 +202,7 @@ type Payment struct {
 	LeaseID     int       `json:"leaseId"`
 	TenantID    int       `json:"tenantId"`
 	PropertyID  int       `json:"propertyId"`
+	UnitID      *int      `json:"unitId,omitempty"`
 	Amount      float64   `json:"amount"`
 	PaymentDate string    `json:"paymentDate"`
 	PaymentType string    `json:"paymentType"`
@@ -158,6 +213,7 @@ type Payment struct {
 	// Joined fields
 	TenantName   *string `json:"tenantName,omitempty"`
 	PropertyName *string `json:"propertyName,omitempty"`
+	UnitNumber   *string `json:"unitNumber,omitempty"`
 }
 
 // ============================================================================
@@ -165,73 +221,239 @@ type Payment struct {
 // ============================================================================
 
 func main() {
+	// Route the standard logger through slog so every line is JSON
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
 	// Load .env file before reading any config
 	if err := godotenv.Load(); err != nil {
 		log.Println("Warning: no .env file found, relying on exported environment variables")
 	}
 
+	var err error
+	cfg, err = loadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// `config print` runs before validation so a broken config can be inspected
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := runConfigCommand(os.Args[2:]); err != nil {
+			log.Fatalf("config: %v", err)
+		}
+		return
+	}
+
+	if err := cfg.validate(); err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+
 	initAllowedOrigins()
 
+	screeningProvider, err = newScreeningProvider(cfg.Screening.Provider)
+	if err != nil {
+		log.Fatalf("Screening provider: %v", err)
+	}
+	payoutCipher, err = newPayoutCipher(cfg.PayoutEncryptionKey)
+	if err != nil {
+		log.Fatalf("Payout encryption: %v", err)
+	}
+	mediaStore = newLocalMediaStore(cfg.Media)
+	receiptStore = localMediaStore{dir: cfg.Media.PrivateDir}
+	geocoder, err = newGeocoder(cfg.Geocoder)
+	if err != nil {
+		log.Fatalf("Geocoder: %v", err)
+	}
+	mailer, err = newMailer(cfg.Mail)
+	if err != nil {
+		log.Fatalf("Mailer: %v", err)
+	}
+
 	if err := connectDB(); err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer db.Close()
 	log.Println("Connected to MySQL database")
 
-	// Update lease statuses on startup
-	updateLeaseStatuses()
+	// Subcommands that need the database: `migrate up|down|status|baseline`
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			if err := runMigrateCommand(os.Args[2:]); err != nil {
+				log.Fatalf("migrate: %v", err)
+			}
+			return
+		default:
+			log.Fatalf("Unknown command %q", os.Args[1])
+		}
+	}
+
+	// Refuse to serve against a schema this binary does not expect
+	if err := checkMigrations(db, migrationFiles); err != nil {
+		log.Fatalf("Database schema is not current: %v (run `migrate up`)", err)
+	}
+	if err := encryptPlaintextPayouts(); err != nil {
+		log.Fatalf("Encrypting owner payout details: %v", err)
+	}
+
+	// Cancelled on SIGINT/SIGTERM to begin a graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Background jobs (lease status updates, ...) run until shutdown
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	waitForJobs := startScheduler(jobsCtx, backgroundJobs())
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           newHandler(),
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		fmt.Printf("Roses & Clovers Properties API starting on port %s...\n", cfg.Port)
+		fmt.Printf("Health check: http://localhost:%s/api/health/ready\n", cfg.Port)
+		serverErr <- srv.ListenAndServe()
+	}()
 
+	select {
+	case err := <-serverErr:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
+
+	// Stop accepting connections and let in-flight requests (e.g. payment
+	// writes) finish, bounded by the shutdown deadline.
+	log.Printf("Shutting down, draining requests for up to %s", cfg.HTTP.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP shutdown incomplete: %v", err)
+	}
+
+	cancelJobs()
+	jobsDone := make(chan struct{})
+	go func() {
+		waitForJobs()
+		close(jobsDone)
+	}()
+	select {
+	case <-jobsDone:
+	case <-shutdownCtx.Done():
+		log.Printf("Background jobs did not stop before the shutdown deadline")
+	}
+
+	log.Println("Shutdown complete")
+}
+
+// newHandler builds the full middleware chain around the API routes. The
+// test suite drives this same handler.
+func newHandler() http.Handler {
+	mux := http.NewServeMux()
+	registerRoutes(mux)
+	return observe(mux, enableCORS(limitRequestBody(mux)))
+}
+
+// registerRoutes wires every API route onto mux.
+func registerRoutes(mux *http.ServeMux) {
 	// Public routes
-	http.HandleFunc("/api/health", healthHandler)
-	http.HandleFunc("/api/properties", propertiesPublicHandler)
-	http.HandleFunc("/api/properties/", propertyByIDPublicHandler)
+	mux.HandleFunc("/api/health", healthLiveHandler) // legacy alias for /api/health/live
+	mux.HandleFunc("/api/health/live", healthLiveHandler)
+	mux.HandleFunc("/api/health/ready", healthReadyHandler)
+	mux.Handle("/metrics", metricsHandler())
+	mux.HandleFunc("/api/properties", propertiesPublicHandler)
+	mux.HandleFunc("/api/properties/", propertyByIDPublicHandler)
+	mux.HandleFunc("/api/contact", contactHandler)
+	mux.HandleFunc("/api/showings/", showingByTokenHandler)
+	mux.HandleFunc("/api/saved-searches", savedSearchesHandler)
+	mux.HandleFunc("/api/saved-searches/", savedSearchByTokenHandler)
+	mux.HandleFunc("/api/feeds/", listingsFeedHandler)
+	mux.HandleFunc("/api/sitemap", sitemapHandler)
+	mux.Handle("/media/", mediaFileHandler())
 
 	// Admin auth
-	http.HandleFunc("/api/admin/login", adminLoginHandler)
-	http.HandleFunc("/api/admin/logout", adminLogoutHandler)
-	http.HandleFunc("/api/admin/me", adminMeHandler)
+	mux.HandleFunc("/api/admin/login", adminLoginHandler)
+	mux.HandleFunc("/api/admin/logout", adminLogoutHandler)
+	mux.HandleFunc("/api/admin/me", adminMeHandler)
 
 	// Admin dashboard
-	http.HandleFunc("/api/admin/dashboard/stats", adminDashboardStatsHandler)
+	mux.HandleFunc("/api/admin/dashboard/stats", adminDashboardStatsHandler)
 
 	// Admin properties
-	http.HandleFunc("/api/admin/properties", adminPropertiesHandler)
-	http.HandleFunc("/api/admin/properties/", adminPropertyByIDHandler)
+	mux.HandleFunc("/api/admin/properties", adminPropertiesHandler)
+	mux.HandleFunc("/api/admin/properties/", adminPropertyByIDHandler)
 
 	// Admin tenants
-	http.HandleFunc("/api/admin/tenants", adminTenantsHandler)
-	http.HandleFunc("/api/admin/tenants/", adminTenantByIDHandler)
+	mux.HandleFunc("/api/admin/tenants", adminTenantsHandler)
+	mux.HandleFunc("/api/admin/tenants/", adminTenantByIDHandler)
 
 	// Admin leases
-	http.HandleFunc("/api/admin/leases", adminLeasesHandler)
-	http.HandleFunc("/api/admin/leases/", adminLeaseByIDHandler)
+	mux.HandleFunc("/api/admin/leases", adminLeasesHandler)
+	mux.HandleFunc("/api/admin/leases/", adminLeaseByIDHandler)
 
 	// Admin maintenance requests
-	http.HandleFunc("/api/admin/requests", adminRequestsHandler)
-	http.HandleFunc("/api/admin/requests/", adminRequestByIDHandler)
+	mux.HandleFunc("/api/admin/requests", adminRequestsHandler)
+	mux.HandleFunc("/api/admin/requests/", adminRequestByIDHandler)
 
 	// Admin payments
-	http.HandleFunc("/api/admin/payments", adminPaymentsHandler)
-	http.HandleFunc("/api/admin/payments/", adminPaymentByIDHandler)
+	mux.HandleFunc("/api/admin/payments", adminPaymentsHandler)
+	mux.HandleFunc("/api/admin/payments/", adminPaymentByIDHandler)
+
+	// Admin trash (soft-deleted tenants, leases and payments)
+	mux.HandleFunc("/api/admin/trash", adminTrashHandler)
+	mux.HandleFunc("/api/admin/trash/", adminTrashItemHandler)
+
+	// Admin reports
+	mux.HandleFunc("/api/admin/reports/", adminReportsHandler)
+
+	// Admin expenses
+	mux.HandleFunc("/api/admin/expenses", adminExpensesHandler)
+	mux.HandleFunc("/api/admin/expenses/", adminExpenseByIDHandler)
+
+	// Admin owners
+	mux.HandleFunc("/api/admin/owners", adminOwnersHandler)
+	mux.HandleFunc("/api/admin/owners/", adminOwnerByIDHandler)
+
+	// Admin rental applications
+	mux.HandleFunc("/api/admin/applications", adminApplicationsHandler)
+	mux.HandleFunc("/api/admin/applications/", adminApplicationByIDHandler)
+
+	// Admin lead inbox
+	mux.HandleFunc("/api/admin/leads", adminLeadsHandler)
+	mux.HandleFunc("/api/admin/leads/", adminLeadByIDHandler)
+
+	// Admin showings
+	mux.HandleFunc("/api/admin/showings", adminShowingsHandler)
+	mux.HandleFunc("/api/admin/showings/", adminShowingByIDHandler)
+	mux.HandleFunc("/api/admin/showings.ics", adminShowingsCalendarHandler)
 
 	// Tenant auth
-	http.HandleFunc("/api/tenant/login", tenantLoginHandler)
-	http.HandleFunc("/api/tenant/logout", tenantLogoutHandler)
-	http.HandleFunc("/api/tenant/me", tenantMeHandler)
+	mux.HandleFunc("/api/tenant/login", tenantLoginHandler)
+	mux.HandleFunc("/api/tenant/logout", tenantLogoutHandler)
+	mux.HandleFunc("/api/tenant/me", tenantMeHandler)
 
 	// Tenant portal
-	http.HandleFunc("/api/tenant/requests", tenantRequestsHandler)
-	http.HandleFunc("/api/tenant/requests/", tenantRequestByIDHandler)
-	http.HandleFunc("/api/tenant/payments", tenantPaymentsHandler)
-	http.HandleFunc("/api/tenant/lease", tenantLeaseHandler)
+	mux.HandleFunc("/api/tenant/requests", tenantRequestsHandler)
+	mux.HandleFunc("/api/tenant/requests/", tenantRequestByIDHandler)
+	mux.HandleFunc("/api/tenant/payments", tenantPaymentsHandler)
+	mux.HandleFunc("/api/tenant/lease", tenantLeaseHandler)
 
-	port := getEnv("PORT", "8080")
-	fmt.Printf("Roses & Clovers Properties API starting on port %s...\n", port)
-	fmt.Printf("Health check: http://localhost:%s/api/health\n", port)
+	// Owner auth
+	mux.HandleFunc("/api/owner/login", ownerLoginHandler)
+	mux.HandleFunc("/api/owner/logout", ownerLogoutHandler)
+	mux.HandleFunc("/api/owner/me", ownerMeHandler)
 
-	if err := http.ListenAndServe(":"+port, enableCORS(http.DefaultServeMux)); err != nil {
-		log.Fatal(err)
-	}
+	// Owner portal
+	mux.HandleFunc("/api/owner/properties", ownerPropertiesHandler)
+	mux.HandleFunc("/api/owner/occupancy", ownerOccupancyHandler)
+	mux.HandleFunc("/api/owner/leases", ownerLeasesHandler)
+	mux.HandleFunc("/api/owner/requests", ownerRequestsHandler)
+	mux.HandleFunc("/api/owner/payments", ownerPaymentsHandler)
+	mux.HandleFunc("/api/owner/statement", ownerStatementHandler)
 }
 
 // ============================================================================
@@ -239,15 +461,10 @@ func main() {
 // ============================================================================
 
 func connectDB() error {
-	host := getEnv("DB_HOST", "127.0.0.1")
-	port := getEnv("DB_PORT", "3306")
-	user := mustGetEnv("DB_USER")
-	password := mustGetEnv("DB_PASSWORD")
-	name := mustGetEnv("DB_NAME")
-
-	log.Printf("Connecting to MySQL as user=%s db=%s host=%s port=%s", user, name, host, port)
+	c := cfg.DB
+	log.Printf("Connecting to MySQL as user=%s db=%s host=%s port=%s", c.User, c.Name, c.Host, c.Port)
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", user, password, host, port, name)
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&timeout=%s", c.User, c.Password, c.Host, c.Port, c.Name, c.ConnectTimeout)
 
 	var err error
 	db, err = sql.Open("mysql", dsn)
@@ -255,30 +472,13 @@ func connectDB() error {
 		return err
 	}
 
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	db.SetMaxOpenConns(c.MaxOpenConns)
+	db.SetMaxIdleConns(c.MaxIdleConns)
+	db.SetConnMaxLifetime(c.ConnMaxLifetime)
 
-	return db.Ping()
-}
-
-// mustGetEnv returns the value of an environment variable or fatally exits.
-// No fallback — the variable must be set in .env or the process environment.
-func mustGetEnv(key string) string {
-	val := os.Getenv(key)
-	if val == "" {
-		log.Fatalf("FATAL: required environment variable %s is not set. Check your .env file.", key)
-	}
-	return val
-}
-
-// getEnv returns the value of an environment variable with a fallback default.
-// Use only for non-sensitive, optional config (e.g. PORT, ALLOWED_ORIGINS).
-func getEnv(key, fallback string) string {
-	if val := os.Getenv(key); val != "" {
-		return val
-	}
-	return fallback
+	ctx, cancel := context.WithTimeout(context.Background(), c.ConnectTimeout)
+	defer cancel()
+	return db.PingContext(ctx)
 }
 
 // ============================================================================
@@ -288,7 +488,7 @@ func getEnv(key, fallback string) string {
 func jsonError(w http.ResponseWriter, message string, status int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(ErrorResponse{Error: message})
+	json.NewEncoder(w).Encode(ErrorResponse{Error: message, RequestID: w.Header().Get(requestIDHeader)})
 }
 
 func jsonResponse(w http.ResponseWriter, data interface{}, status int) {
@@ -346,29 +546,64 @@ func extractID(path, prefix string) (int, error) {
 	return strconv.Atoi(idStr)
 }
 
-// Update lease statuses based on current date
-func updateLeaseStatuses() {
+// extractIDAndAction splits paths like /prefix/12/convert into the ID and the
+// trailing action ("" when there is none).
+func extractIDAndAction(path, prefix string) (int, string, error) {
+	rest := strings.Trim(strings.TrimPrefix(path, prefix), "/")
+	idStr, action, _ := strings.Cut(rest, "/")
+	id, err := strconv.Atoi(idStr)
+	return id, action, err
+}
+
+// isUniqueViolation reports whether err is the database rejecting a
+// duplicate key, checked through the driver's error type rather than its
+// message.
+func isUniqueViolation(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1062 // ER_DUP_ENTRY
+	}
+	return isSQLiteUniqueViolation(err)
+}
+
+// isSQLiteUniqueViolation is set by the test suite, which runs on SQLite, so
+// the server binary does not link the SQLite driver.
+var isSQLiteUniqueViolation = func(err error) bool { return false }
+
+// dateOnly trims a scanned DATE column to YYYY-MM-DD. Drivers that parse
+// times hand back a full timestamp string.
+func dateOnly(s string) string {
+	if len(s) > 10 {
+		return s[:10]
+	}
+	return s
+}
+
+// updateLeaseStatuses moves leases between upcoming, active and ended based
+// on the current date. Runs as a background job.
+func updateLeaseStatuses(ctx context.Context) error {
 	today := time.Now().Format("2006-01-02")
 
 	// Update to 'active' if start_date <= today and end_date >= today
-	_, err := db.Exec(`
+	_, err := db.ExecContext(ctx, `
 		UPDATE leases
 		SET status = 'active'
-		WHERE status = 'upcoming' AND start_date <= ? AND end_date >= ?
+		WHERE status = 'upcoming' AND start_date <= ? AND end_date >= ? AND deleted_at IS NULL
 	`, today, today)
 	if err != nil {
-		log.Printf("Error updating active leases: %v", err)
+		return fmt.Errorf("updating active leases: %w", err)
 	}
 
 	// Update to 'ended' if end_date < today
-	_, err = db.Exec(`
+	_, err = db.ExecContext(ctx, `
 		UPDATE leases
 		SET status = 'ended'
-		WHERE status IN ('active', 'upcoming') AND end_date < ?
+		WHERE status IN ('active', 'upcoming') AND end_date < ? AND deleted_at IS NULL
 	`, today)
 	if err != nil {
-		log.Printf("Error updating ended leases: %v", err)
+		return fmt.Errorf("updating ended leases: %w", err)
 	}
+	return nil
 }
 
 // ============================================================================
@@ -378,13 +613,7 @@ func updateLeaseStatuses() {
 var allowedOrigins []string
 
 func initAllowedOrigins() {
-	defaults := "http://localhost:3000,http://localhost:3001,http://34.227.145.219:3001,https://seanscoolprojectmmis6191.com"
-	raw := getEnv("ALLOWED_ORIGINS", defaults)
-	for _, o := range strings.Split(raw, ",") {
-		if trimmed := strings.TrimSpace(o); trimmed != "" {
-			allowedOrigins = append(allowedOrigins, trimmed)
-		}
-	}
+	allowedOrigins = cfg.AllowedOrigins
 }
 
 func isOriginAllowed(origin string) bool {
@@ -396,6 +625,43 @@ func isOriginAllowed(origin string) bool {
 	return false
 }
 
+// uploadRoutes take file uploads and so are capped at
+// cfg.Media.MaxUploadBytes, plus room for the other form fields, instead of
+// cfg.HTTP.MaxBodyBytes. Routes are matched on method and path alone; what
+// the client says about the body doesn't change its limit.
+var uploadRoutes = []struct{ method, prefix, action string }{
+	{http.MethodPost, "/api/admin/properties/", "images"},
+	{http.MethodPost, "/api/admin/expenses/", "receipt"},
+}
+
+// bodyLimit is the most a request to r's route may send.
+func bodyLimit(r *http.Request) int64 {
+	for _, route := range uploadRoutes {
+		if r.Method != route.method || !strings.HasPrefix(r.URL.Path, route.prefix) {
+			continue
+		}
+		if _, action, err := extractIDAndAction(r.URL.Path, route.prefix); err == nil && action == route.action {
+			return cfg.Media.MaxUploadBytes + 1<<20
+		}
+	}
+	return cfg.HTTP.MaxBodyBytes
+}
+
+// limitRequestBody caps every request body at its route's bodyLimit.
+// Declared oversize bodies are rejected up front; others fail to decode once
+// the limit is hit.
+func limitRequestBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := bodyLimit(r)
+		if r.ContentLength > limit {
+			jsonError(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
 func enableCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		origin := r.Header.Get("Origin")
@@ -406,7 +672,8 @@ func enableCORS(next http.Handler) http.Handler {
 		}
 
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+requestIDHeader)
+		w.Header().Set("Access-Control-Expose-Headers", requestIDHeader)
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		if r.Method == "OPTIONS" {
@@ -422,7 +689,9 @@ func enableCORS(next http.Handler) http.Handler {
 // HANDLERS - HEALTH
 // ============================================================================
 
-func healthHandler(w http.ResponseWriter, r *http.Request) {
+// healthLiveHandler reports that the process is up and serving. It never
+// touches dependencies, so orchestrators only restart a truly stuck process.
+func healthLiveHandler(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, map[string]interface{}{
 		"status":    "healthy",
 		"service":   "Roses & Clovers Properties API",
@@ -430,6 +699,75 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 	}, http.StatusOK)
 }
 
+type componentHealth struct {
+	Status    string               `json:"status"`
+	Error     string               `json:"error,omitempty"`
+	LatencyMs *float64             `json:"latencyMs,omitempty"`
+	Jobs      map[string]jobStatus `json:"jobs,omitempty"`
+}
+
+// healthReadyHandler checks what a request needs: a reachable database with
+// a current schema. Either failing turns the response into a 503 so the load
+// balancer stops routing here. Background jobs are reported too, but a
+// failing job doesn't stop this replica serving requests, so it never
+// changes the status code.
+func healthReadyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), cfg.HealthTimeout)
+	defer cancel()
+
+	components := map[string]componentHealth{}
+	ready := true
+
+	start := time.Now()
+	if err := db.PingContext(ctx); err != nil {
+		components["database"] = componentHealth{Status: "down", Error: err.Error()}
+		ready = false
+	} else {
+		latency := float64(time.Since(start).Microseconds()) / 1000
+		components["database"] = componentHealth{Status: "ok", LatencyMs: &latency}
+	}
+
+	if ready {
+		if err := checkMigrationsReadOnly(ctx, db, migrationFiles); err != nil {
+			components["migrations"] = componentHealth{Status: "pending", Error: err.Error()}
+			ready = false
+		} else {
+			components["migrations"] = componentHealth{Status: "ok"}
+		}
+	} else {
+		components["migrations"] = componentHealth{Status: "unknown", Error: "database unavailable"}
+	}
+
+	scheduler := componentHealth{Status: "ok", Jobs: map[string]jobStatus{}}
+	statuses := snapshotJobStatuses()
+	for _, job := range backgroundJobs() {
+		status, ran := statuses[job.Name]
+		scheduler.Jobs[job.Name] = status
+		if !ran {
+			continue // first run still in progress
+		}
+		if status.LastError != "" {
+			scheduler.Status = "degraded"
+			scheduler.Error = job.Name + ": " + status.LastError
+		} else if time.Since(status.LastSuccess) > 3*job.Interval {
+			scheduler.Status = "degraded"
+			scheduler.Error = job.Name + ": no successful run since " + status.LastSuccess.Format(time.RFC3339)
+		}
+	}
+	components["scheduler"] = scheduler
+
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "degraded", http.StatusServiceUnavailable
+	}
+	jsonResponse(w, map[string]interface{}{
+		"status":     status,
+		"service":    "Roses & Clovers Properties API",
+		"timestamp":  time.Now(),
+		"components": components,
+	}, code)
+}
+
 // ============================================================================
 // HANDLERS - AUTH
 // ============================================================================
@@ -446,15 +784,14 @@ func adminLoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	adminPassword := getEnv("ADMIN_PASSWORD", "admin123")
-	if req.Password != adminPassword {
+	if req.Password != cfg.AdminPassword {
 		jsonError(w, "Invalid password", http.StatusUnauthorized)
 		return
 	}
 
 	token := generateToken()
 	tokenMutex.Lock()
-	tokenStore[token] = time.Now().Add(24 * time.Hour)
+	tokenStore[token] = time.Now().Add(cfg.AdminTokenTTL)
 	tokenMutex.Unlock()
 
 	jsonResponse(w, LoginResponse{Token: token}, http.StatusOK)
@@ -512,14 +849,39 @@ func adminDashboardStatsHandler(w http.ResponseWriter, r *http.Request) {
 
 	var stats DashboardStats
 
-	db.QueryRow("SELECT COUNT(*) FROM properties").Scan(&stats.TotalProperties)
-	db.QueryRow("SELECT COUNT(*) FROM properties WHERE available = TRUE").Scan(&stats.AvailableProperties)
-	db.QueryRow("SELECT COUNT(*) FROM tenants").Scan(&stats.TotalTenants)
-	db.QueryRow("SELECT COUNT(*) FROM leases WHERE status = 'active'").Scan(&stats.ActiveLeases)
-	db.QueryRow("SELECT COUNT(*) FROM leases WHERE status = 'upcoming'").Scan(&stats.UpcomingLeases)
-	db.QueryRow("SELECT COUNT(*) FROM leases").Scan(&stats.TotalLeases)
+	// ?ownerId= narrows every figure to one owner's portfolio.
+	ownerID := r.URL.Query().Get("ownerId")
+	props, args, err := ownerFilter(ownerID, "id")
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	leases, _, _ := ownerFilter(ownerID, "property_id")
+	tenants := ""
+	if leases != "" {
+		tenants = " AND id IN (SELECT tenant_id FROM leases WHERE deleted_at IS NULL" + leases + ")"
+	}
+
+	db.QueryRow("SELECT COUNT(*) FROM properties WHERE listing_status <> 'archived'"+props, args...).Scan(&stats.TotalProperties)
+	db.QueryRow("SELECT COUNT(*) FROM properties WHERE available = TRUE AND listing_status = 'listed'"+props, args...).Scan(&stats.AvailableProperties)
+	db.QueryRow("SELECT COUNT(*) FROM tenants WHERE deleted_at IS NULL"+tenants, args...).Scan(&stats.TotalTenants)
+	db.QueryRow("SELECT COUNT(*) FROM leases WHERE status = 'active' AND deleted_at IS NULL"+leases, args...).Scan(&stats.ActiveLeases)
+	db.QueryRow("SELECT COUNT(*) FROM leases WHERE status = 'upcoming' AND deleted_at IS NULL"+leases, args...).Scan(&stats.UpcomingLeases)
+	db.QueryRow("SELECT COUNT(*) FROM leases WHERE deleted_at IS NULL"+leases, args...).Scan(&stats.TotalLeases)
 	// Monthly revenue = sum of monthly_rent for active leases only
-	db.QueryRow("SELECT COALESCE(SUM(monthly_rent), 0) FROM leases WHERE status = 'active'").Scan(&stats.MonthlyRevenue)
+	db.QueryRow("SELECT COALESCE(SUM(monthly_rent), 0) FROM leases WHERE status = 'active' AND deleted_at IS NULL"+leases, args...).Scan(&stats.MonthlyRevenue)
+
+	buildings, err := loadBuildingOccupancy(ownerID)
+	if err != nil {
+		log.Printf("Error loading occupancy: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	stats.Buildings = buildings
+	for _, b := range buildings {
+		stats.TotalUnits += b.TotalUnits
+		stats.OccupiedUnits += b.OccupiedUnits
+	}
 
 	jsonResponse(w, stats, http.StatusOK)
 }
@@ -534,105 +896,76 @@ func propertiesPublicHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query := `
-		SELECT id, name, address_line1, address_line2, city, state, zip,
-			   property_type, bedrooms, bathrooms, square_feet, monthly_rent,
-			   deposit_amount, available, available_date, description, amenities, image_url,
-			   created_at, updated_at
-		FROM properties WHERE 1=1
-	`
-	args := []interface{}{}
-
-	// Filter by availability
-	if available := r.URL.Query().Get("available"); available != "" {
-		if available == "true" {
-			query += " AND available = TRUE"
-		} else if available == "false" {
-			query += " AND available = FALSE"
-		}
+	// Filters, full-text and radius search are parsed in search.go
+	search, err := parsePropertySearch(r.Context(), r.URL.Query())
+	if err != nil {
+		writeSearchError(w, err)
+		return
 	}
-
-	// Filter by bedrooms
-	if beds := r.URL.Query().Get("beds"); beds != "" {
-		if bedsInt, err := strconv.Atoi(beds); err == nil {
-			query += " AND bedrooms >= ?"
-			args = append(args, bedsInt)
-		}
+	properties, err := search.run(db)
+	if err != nil {
+		writeSearchError(w, err)
+		return
 	}
 
-	// Filter by min rent
-	if minRent := r.URL.Query().Get("minRent"); minRent != "" {
-		if minRentFloat, err := strconv.ParseFloat(minRent, 64); err == nil {
-			query += " AND monthly_rent >= ?"
-			args = append(args, minRentFloat)
-		}
-	}
+	jsonResponse(w, publicProperties(properties), http.StatusOK)
+}
 
-	// Filter by max rent
-	if maxRent := r.URL.Query().Get("maxRent"); maxRent != "" {
-		if maxRentFloat, err := strconv.ParseFloat(maxRent, 64); err == nil {
-			query += " AND monthly_rent <= ?"
-			args = append(args, maxRentFloat)
+// propertyByIDPublicHandler serves /api/properties/:idOrSlug[/action]. A
+// retired slug redirects to the property's current one.
+func propertyByIDPublicHandler(w http.ResponseWriter, r *http.Request) {
+	key, action, _ := strings.Cut(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/properties/"), "/"), "/")
+	id, err := strconv.Atoi(key)
+	slug := key
+	if err != nil {
+		id, slug, err = resolvePropertySlug(key)
+		if err == sql.ErrNoRows {
+			jsonError(w, "Property not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("Error resolving property slug: %v", err)
+			jsonError(w, "Database error", http.StatusInternalServerError)
+			return
 		}
 	}
-
-	// Search by name or address
-	if search := r.URL.Query().Get("search"); search != "" {
-		query += " AND (name LIKE ? OR address_line1 LIKE ? OR city LIKE ?)"
-		searchPattern := "%" + search + "%"
-		args = append(args, searchPattern, searchPattern, searchPattern)
-	}
-
-	// Filter by property type
-	if propType := r.URL.Query().Get("type"); propType != "" {
-		query += " AND property_type = ?"
-		args = append(args, propType)
-	}
-
-	query += " ORDER BY available DESC, monthly_rent ASC"
-
-	rows, err := db.Query(query, args...)
+	// Checked before redirecting a retired slug, so an old slug of a
+	// property that is no longer listed does not reveal its current one.
+	listed, err := publiclyListed(id)
 	if err != nil {
-		log.Printf("Error querying properties: %v", err)
+		log.Printf("Error checking property listing status: %v", err)
 		jsonError(w, "Database error", http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
-
-	properties := []Property{}
-	for rows.Next() {
-		p, err := scanProperty(rows)
-		if err != nil {
-			log.Printf("Error scanning property: %v", err)
-			continue
-		}
-		properties = append(properties, p)
+	if !listed {
+		jsonError(w, "Property not found", http.StatusNotFound)
+		return
 	}
-
-	jsonResponse(w, properties, http.StatusOK)
-}
-
-func propertyByIDPublicHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	if slug != key {
+		redirectPropertySlug(w, r, slug, action)
 		return
 	}
 
-	id, err := extractID(r.URL.Path, "/api/properties/")
-	if err != nil {
-		jsonError(w, "Invalid property ID", http.StatusBadRequest)
+	switch action {
+	case "":
+	case "jsonld":
+	case "applications":
+		submitApplication(w, r, id)
+		return
+	case "showings":
+		propertyShowingsHandler(w, r, id)
+		return
+	default:
+		jsonError(w, "Not found", http.StatusNotFound)
 		return
 	}
 
-	row := db.QueryRow(`
-		SELECT id, name, address_line1, address_line2, city, state, zip,
-			   property_type, bedrooms, bathrooms, square_feet, monthly_rent,
-			   deposit_amount, available, available_date, description, amenities, image_url,
-			   created_at, updated_at
-		FROM properties WHERE id = ?
-	`, id)
+	if r.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-	p, err := scanPropertyRow(row)
+	p, err := loadProperty(id)
 	if err == sql.ErrNoRows {
 		jsonError(w, "Property not found", http.StatusNotFound)
 		return
@@ -643,7 +976,13 @@ func propertyByIDPublicHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	jsonResponse(w, p, http.StatusOK)
+	if action == "jsonld" {
+		w.Header().Set("Content-Type", "application/ld+json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(propertyJSONLD(p))
+		return
+	}
+	jsonResponse(w, PublicProperty{Property: p}, http.StatusOK)
 }
 
 // ============================================================================
@@ -670,12 +1009,29 @@ func adminPropertyByIDHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := extractID(r.URL.Path, "/api/admin/properties/")
+	id, action, err := extractIDAndAction(r.URL.Path, "/api/admin/properties/")
 	if err != nil {
 		jsonError(w, "Invalid property ID", http.StatusBadRequest)
 		return
 	}
 
+	if action == "images" || strings.HasPrefix(action, "images/") {
+		propertyImagesHandler(w, r, id, strings.TrimPrefix(strings.TrimPrefix(action, "images"), "/"))
+		return
+	}
+	if action == "units" || strings.HasPrefix(action, "units/") {
+		propertyUnitsHandler(w, r, id, strings.TrimPrefix(strings.TrimPrefix(action, "units"), "/"))
+		return
+	}
+	if action == "rent-history" || strings.HasPrefix(action, "rent-history/") {
+		propertyRentHistoryHandler(w, r, id, strings.TrimPrefix(strings.TrimPrefix(action, "rent-history"), "/"))
+		return
+	}
+	if action != "" {
+		jsonError(w, "Not found", http.StatusNotFound)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		getPropertyByID(w, id)
@@ -689,13 +1045,24 @@ func adminPropertyByIDHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func getPropertiesAdmin(w http.ResponseWriter, r *http.Request) {
+	where, args, err := adminStatusFilter(r.URL.Query())
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ownerClause, ownerArgs, err := ownerFilter(r.URL.Query().Get("ownerId"), "id")
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	where += ownerClause
+	args = append(args, ownerArgs...)
+
 	rows, err := db.Query(`
-		SELECT id, name, address_line1, address_line2, city, state, zip,
-			   property_type, bedrooms, bathrooms, square_feet, monthly_rent,
-			   deposit_amount, available, available_date, description, amenities, image_url,
-			   created_at, updated_at
-		FROM properties ORDER BY created_at DESC
-	`)
+		SELECT `+propertyColumns+`
+		FROM properties WHERE `+where+`
+		ORDER BY created_at DESC
+	`, args...)
 	if err != nil {
 		log.Printf("Error querying properties: %v", err)
 		jsonError(w, "Database error", http.StatusInternalServerError)
@@ -717,15 +1084,7 @@ func getPropertiesAdmin(w http.ResponseWriter, r *http.Request) {
 }
 
 func getPropertyByID(w http.ResponseWriter, id int) {
-	row := db.QueryRow(`
-		SELECT id, name, address_line1, address_line2, city, state, zip,
-			   property_type, bedrooms, bathrooms, square_feet, monthly_rent,
-			   deposit_amount, available, available_date, description, amenities, image_url,
-			   created_at, updated_at
-		FROM properties WHERE id = ?
-	`, id)
-
-	p, err := scanPropertyRow(row)
+	p, err := loadProperty(id)
 	if err == sql.ErrNoRows {
 		jsonError(w, "Property not found", http.StatusNotFound)
 		return
@@ -751,27 +1110,88 @@ func createProperty(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	amenitiesJSON, _ := json.Marshal(p.Amenities)
+	// Without an explicit unit list the property is a single-unit building
+	// described by its own fields.
+	units := p.Units
+	if len(units) == 0 {
+		units = []Unit{unitFromProperty(p)}
+	}
+	seen := map[string]bool{}
+	for i := range units {
+		if msg := validateUnit(&units[i]); msg != "" {
+			jsonError(w, msg, http.StatusBadRequest)
+			return
+		}
+		if seen[units[i].UnitNumber] {
+			jsonError(w, "Unit numbers must be unique within a property", http.StatusBadRequest)
+			return
+		}
+		seen[units[i].UnitNumber] = true
+	}
+	if msg := validatePropertyListing(&p); msg != "" {
+		jsonError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if p.ListingStatus == "" {
+		p.ListingStatus = listingListed
+	}
+	locateProperty(r.Context(), &p)
 
-	result, err := db.Exec(`
+	amenitiesJSON := jsonText(p.Amenities)
+
+	tx, err := db.Begin()
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
 		INSERT INTO properties (name, address_line1, address_line2, city, state, zip,
 			property_type, bedrooms, bathrooms, square_feet, monthly_rent,
-			deposit_amount, available, available_date, description, amenities, image_url)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			deposit_amount, available, available_date, description, amenities, image_url,
+			latitude, longitude, pet_policy, syndicate, listing_status, owner_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, 0))
 	`, p.Name, p.AddressLine1, p.AddressLine2, p.City, p.State, p.Zip,
 		p.PropertyType, p.Bedrooms, p.Bathrooms, p.SquareFeet, p.MonthlyRent,
-		p.DepositAmount, p.Available, p.AvailableDate, p.Description, string(amenitiesJSON), p.ImageURL)
-
+		p.DepositAmount, p.Available, p.AvailableDate, p.Description, amenitiesJSON, p.ImageURL,
+		p.Latitude, p.Longitude, p.PetPolicy, p.Syndicate == nil || *p.Syndicate, p.ListingStatus, p.OwnerID)
+	if err == nil {
+		id, _ := result.LastInsertId()
+		p.ID = int(id)
+		for _, u := range units {
+			if _, err = insertUnit(tx, p.ID, u); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = syncPropertyFromUnits(tx, p.ID)
+	}
+	if err == nil {
+		err = recordRentChange(tx, p.ID, "Listed")
+	}
+	if err == nil {
+		err = syncPropertySlug(tx, p.ID)
+	}
+	if err == nil {
+		err = indexPropertySearch(tx, p.ID)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
 	if err != nil {
 		log.Printf("Error creating property: %v", err)
 		jsonError(w, "Failed to create property", http.StatusInternalServerError)
 		return
 	}
 
-	id, _ := result.LastInsertId()
-	p.ID = int(id)
-	p.CreatedAt = time.Now()
-	p.UpdatedAt = time.Now()
+	p, err = loadProperty(p.ID)
+	if err != nil {
+		log.Printf("Error getting property: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
 
 	jsonResponse(w, p, http.StatusCreated)
 }
@@ -783,53 +1203,126 @@ func updateProperty(w http.ResponseWriter, r *http.Request, id int) {
 		return
 	}
 
-	amenitiesJSON, _ := json.Marshal(p.Amenities)
+	var oldZip string
+	var oldLat, oldLng sql.NullFloat64
+	err := db.QueryRow("SELECT zip, latitude, longitude FROM properties WHERE id = ?", id).Scan(&oldZip, &oldLat, &oldLng)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Property not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting property: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if msg := validatePropertyListing(&p); msg != "" {
+		jsonError(w, msg, http.StatusBadRequest)
+		return
+	}
+	// Clients send back the coordinates they were given, so a new ZIP with
+	// unchanged coordinates means the address moved and needs geocoding.
+	if p.Zip != oldZip && p.Latitude != nil && p.Longitude != nil &&
+		*p.Latitude == oldLat.Float64 && *p.Longitude == oldLng.Float64 {
+		p.Latitude, p.Longitude = nil, nil
+	}
+	locateProperty(r.Context(), &p)
+
+	amenitiesJSON := jsonText(p.Amenities)
 
-	result, err := db.Exec(`
+	tx, err := db.Begin()
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
 		UPDATE properties SET name=?, address_line1=?, address_line2=?, city=?, state=?, zip=?,
 			property_type=?, bedrooms=?, bathrooms=?, square_feet=?, monthly_rent=?,
-			deposit_amount=?, available=?, available_date=?, description=?, amenities=?, image_url=?
+			deposit_amount=?, available=?, available_date=?, description=?, amenities=?, image_url=?,
+			latitude=?, longitude=?, pet_policy=?, syndicate=COALESCE(?, syndicate),
+			listing_status=COALESCE(NULLIF(?, ''), listing_status),
+			owner_id=NULLIF(COALESCE(?, owner_id), 0)
 		WHERE id=?
 	`, p.Name, p.AddressLine1, p.AddressLine2, p.City, p.State, p.Zip,
 		p.PropertyType, p.Bedrooms, p.Bathrooms, p.SquareFeet, p.MonthlyRent,
-		p.DepositAmount, p.Available, p.AvailableDate, p.Description, string(amenitiesJSON), p.ImageURL, id)
+		p.DepositAmount, p.Available, p.AvailableDate, p.Description, amenitiesJSON, p.ImageURL,
+		p.Latitude, p.Longitude, p.PetPolicy, p.Syndicate, p.ListingStatus, p.OwnerID, id)
 
+	// A single-unit building is edited through the property as before. For
+	// multi-unit buildings the unit fields are a rollup and edits go to the
+	// units themselves.
+	var unitCount int
+	if err == nil {
+		err = tx.QueryRow("SELECT COUNT(*) FROM units WHERE property_id = ?", id).Scan(&unitCount)
+	}
+	if err == nil && unitCount == 1 {
+		_, err = tx.Exec(`
+			UPDATE units SET bedrooms=?, bathrooms=?, square_feet=?, monthly_rent=?,
+				deposit_amount=?, available=?, available_date=?
+			WHERE property_id=?
+		`, p.Bedrooms, p.Bathrooms, p.SquareFeet, p.MonthlyRent,
+			p.DepositAmount, p.Available, p.AvailableDate, id)
+	}
+	if err == nil {
+		err = syncPropertyFromUnits(tx, id)
+	}
+	if err == nil {
+		err = recordRentChange(tx, id, "Edited")
+	}
+	if err == nil {
+		err = syncPropertySlug(tx, id)
+	}
+	if err == nil {
+		err = indexPropertySearch(tx, id)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
 	if err != nil {
 		log.Printf("Error updating property: %v", err)
 		jsonError(w, "Failed to update property", http.StatusInternalServerError)
 		return
 	}
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		jsonError(w, "Property not found", http.StatusNotFound)
-		return
-	}
-
-	p.ID = id
-	jsonResponse(w, p, http.StatusOK)
+	getPropertyByID(w, id)
 }
 
+// deleteProperty archives a property: it leaves the public site and the
+// default admin list, but its leases, payments and photos are kept and it
+// can be restored by setting listingStatus.
 func deleteProperty(w http.ResponseWriter, id int) {
 	// Check for active/upcoming leases
 	var leaseCount int
-	db.QueryRow("SELECT COUNT(*) FROM leases WHERE property_id = ? AND status IN ('active', 'upcoming')", id).Scan(&leaseCount)
+	db.QueryRow("SELECT COUNT(*) FROM leases WHERE property_id = ? AND status IN ('active', 'upcoming') AND deleted_at IS NULL", id).Scan(&leaseCount)
 	if leaseCount > 0 {
 		jsonError(w, "Cannot delete property with active or upcoming leases", http.StatusConflict)
 		return
 	}
 
-	result, err := db.Exec("DELETE FROM properties WHERE id = ?", id)
+	result, err := db.Exec("UPDATE properties SET listing_status = ? WHERE id = ? AND listing_status <> ?",
+		listingArchived, id, listingArchived)
 	if err != nil {
-		log.Printf("Error deleting property: %v", err)
+		log.Printf("Error archiving property: %v", err)
 		jsonError(w, "Failed to delete property", http.StatusInternalServerError)
 		return
 	}
 
 	rows, _ := result.RowsAffected()
 	if rows == 0 {
-		jsonError(w, "Property not found", http.StatusNotFound)
-		return
+		// Nothing changed: either there is no such property, or it is
+		// already archived and deleting again succeeds without effect.
+		var exists int
+		err := db.QueryRow("SELECT COUNT(*) FROM properties WHERE id = ?", id).Scan(&exists)
+		if err != nil {
+			log.Printf("Error checking property: %v", err)
+			jsonError(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		if exists == 0 {
+			jsonError(w, "Property not found", http.StatusNotFound)
+			return
+		}
 	}
 
 	w.WriteHeader(http.StatusNoContent)
@@ -846,7 +1339,7 @@ func adminTenantsHandler(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodGet:
-		getTenants(w)
+		getTenants(w, r)
 	case http.MethodPost:
 		createTenant(w, r)
 	default:
@@ -877,12 +1370,28 @@ func adminTenantByIDHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func getTenants(w http.ResponseWriter) {
-	rows, err := db.Query(`
+func getTenants(w http.ResponseWriter, r *http.Request) {
+	query := `
 		SELECT id, first_name, last_name, email, phone, date_of_birth,
 			   emergency_contact_name, emergency_contact_phone, notes, created_at, updated_at
-		FROM tenants ORDER BY last_name, first_name
-	`)
+		FROM tenants WHERE deleted_at IS NULL
+	`
+	var args []interface{}
+
+	// An owner's tenants are those with a lease on one of their properties.
+	clause, ownerArgs, err := ownerFilter(r.URL.Query().Get("ownerId"), "l.property_id")
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if clause != "" {
+		query += " AND id IN (SELECT l.tenant_id FROM leases l WHERE l.deleted_at IS NULL" + clause + ")"
+		args = append(args, ownerArgs...)
+	}
+
+	query += " ORDER BY last_name, first_name"
+
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		log.Printf("Error querying tenants: %v", err)
 		jsonError(w, "Database error", http.StatusInternalServerError)
@@ -907,7 +1416,7 @@ func getTenantByID(w http.ResponseWriter, id int) {
 	row := db.QueryRow(`
 		SELECT id, first_name, last_name, email, phone, date_of_birth,
 			   emergency_contact_name, emergency_contact_phone, notes, created_at, updated_at
-		FROM tenants WHERE id = ?
+		FROM tenants WHERE id = ? AND deleted_at IS NULL
 	`, id)
 
 	t, err := scanTenantRow(row)
@@ -940,6 +1449,14 @@ func createTenant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The email stays taken while a deleted tenant is in the trash.
+	var trashed int
+	db.QueryRow("SELECT COUNT(*) FROM tenants WHERE email = ? AND deleted_at IS NOT NULL", t.Email).Scan(&trashed)
+	if trashed > 0 {
+		jsonError(w, "A deleted tenant with this email is in the trash; restore them instead", http.StatusConflict)
+		return
+	}
+
 	var passwordHash *string
 	if body.Password != "" {
 		hash, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
@@ -959,7 +1476,7 @@ func createTenant(w http.ResponseWriter, r *http.Request) {
 		t.EmergencyContactName, t.EmergencyContactPhone, t.Notes, passwordHash)
 
 	if err != nil {
-		if strings.Contains(err.Error(), "Duplicate entry") {
+		if isUniqueViolation(err) {
 			jsonError(w, "A tenant with this email already exists", http.StatusConflict)
 			return
 		}
@@ -994,18 +1511,18 @@ func updateTenant(w http.ResponseWriter, r *http.Request, id int) {
 			jsonError(w, "Failed to hash password", http.StatusInternalServerError)
 			return
 		}
-		db.Exec("UPDATE tenants SET password_hash=? WHERE id=?", string(hash), id)
+		db.Exec("UPDATE tenants SET password_hash=? WHERE id=? AND deleted_at IS NULL", string(hash), id)
 	}
 
 	result, err := db.Exec(`
 		UPDATE tenants SET first_name=?, last_name=?, email=?, phone=?, date_of_birth=?,
 			emergency_contact_name=?, emergency_contact_phone=?, notes=?
-		WHERE id=?
+		WHERE id=? AND deleted_at IS NULL
 	`, t.FirstName, t.LastName, t.Email, t.Phone, t.DateOfBirth,
 		t.EmergencyContactName, t.EmergencyContactPhone, t.Notes, id)
 
 	if err != nil {
-		if strings.Contains(err.Error(), "Duplicate entry") {
+		if isUniqueViolation(err) {
 			jsonError(w, "A tenant with this email already exists", http.StatusConflict)
 			return
 		}
@@ -1024,27 +1541,28 @@ func updateTenant(w http.ResponseWriter, r *http.Request, id int) {
 	jsonResponse(w, t, http.StatusOK)
 }
 
+// deleteTenant moves a tenant, with their leases and payments, to the trash
+// (see trash.go).
 func deleteTenant(w http.ResponseWriter, id int) {
 	// Check for active/upcoming leases
 	var leaseCount int
-	db.QueryRow("SELECT COUNT(*) FROM leases WHERE tenant_id = ? AND status IN ('active', 'upcoming')", id).Scan(&leaseCount)
+	db.QueryRow("SELECT COUNT(*) FROM leases WHERE tenant_id = ? AND status IN ('active', 'upcoming') AND deleted_at IS NULL", id).Scan(&leaseCount)
 	if leaseCount > 0 {
 		jsonError(w, "Cannot delete tenant with active or upcoming leases", http.StatusConflict)
 		return
 	}
 
-	result, err := db.Exec("DELETE FROM tenants WHERE id = ?", id)
+	found, err := softDelete("tenant", id)
 	if err != nil {
 		log.Printf("Error deleting tenant: %v", err)
 		jsonError(w, "Failed to delete tenant", http.StatusInternalServerError)
 		return
 	}
-
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
+	if !found {
 		jsonError(w, "Tenant not found", http.StatusNotFound)
 		return
 	}
+	revokeTenantSessions(id)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -1097,11 +1615,13 @@ func getLeases(w http.ResponseWriter, r *http.Request) {
 			   l.monthly_rent, l.deposit_amount, l.status, l.payment_due_day,
 			   l.notes, l.created_at, l.updated_at,
 			   p.name as property_name,
-			   CONCAT(t.first_name, ' ', t.last_name) as tenant_name
+			   CONCAT(t.first_name, ' ', t.last_name) as tenant_name,
+			   l.unit_id, u.unit_number
 		FROM leases l
 		JOIN properties p ON l.property_id = p.id
 		JOIN tenants t ON l.tenant_id = t.id
-		WHERE 1=1
+		LEFT JOIN units u ON l.unit_id = u.id
+		WHERE l.deleted_at IS NULL
 	`
 	args := []interface{}{}
 
@@ -1117,6 +1637,13 @@ func getLeases(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if unitID := r.URL.Query().Get("unitId"); unitID != "" {
+		if id, err := strconv.Atoi(unitID); err == nil {
+			query += " AND l.unit_id = ?"
+			args = append(args, id)
+		}
+	}
+
 	if tenantID := r.URL.Query().Get("tenantId"); tenantID != "" {
 		if id, err := strconv.Atoi(tenantID); err == nil {
 			query += " AND l.tenant_id = ?"
@@ -1124,6 +1651,14 @@ func getLeases(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	clause, ownerArgs, err := ownerFilter(r.URL.Query().Get("ownerId"), "l.property_id")
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query += clause
+	args = append(args, ownerArgs...)
+
 	query += " ORDER BY l.start_date DESC"
 
 	rows, err := db.Query(query, args...)
@@ -1153,11 +1688,13 @@ func getLeaseByID(w http.ResponseWriter, id int) {
 			   l.monthly_rent, l.deposit_amount, l.status, l.payment_due_day,
 			   l.notes, l.created_at, l.updated_at,
 			   p.name as property_name,
-			   CONCAT(t.first_name, ' ', t.last_name) as tenant_name
+			   CONCAT(t.first_name, ' ', t.last_name) as tenant_name,
+			   l.unit_id, u.unit_number
 		FROM leases l
 		JOIN properties p ON l.property_id = p.id
 		JOIN tenants t ON l.tenant_id = t.id
-		WHERE l.id = ?
+		LEFT JOIN units u ON l.unit_id = u.id
+		WHERE l.id = ? AND l.deleted_at IS NULL
 	`, id)
 
 	l, err := scanLeaseWithJoinsRow(row)
@@ -1182,7 +1719,7 @@ func createLease(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate required fields
-	if l.PropertyID == 0 || l.TenantID == 0 || l.StartDate == "" || l.EndDate == "" {
+	if (l.PropertyID == 0 && l.UnitID == nil) || l.TenantID == 0 || l.StartDate == "" || l.EndDate == "" {
 		jsonError(w, "Property, tenant, start date, and end date are required", http.StatusBadRequest)
 		return
 	}
@@ -1199,32 +1736,34 @@ func createLease(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check property exists
-	var propertyExists int
-	db.QueryRow("SELECT COUNT(*) FROM properties WHERE id = ?", l.PropertyID).Scan(&propertyExists)
-	if propertyExists == 0 {
-		jsonError(w, "Property not found", http.StatusBadRequest)
+	// Check the property and unit exist
+	unit, err := resolveUnit(l.PropertyID, l.UnitID)
+	if err != nil {
+		writeUnitError(w, err)
 		return
 	}
+	l.PropertyID = unit.PropertyID
+	l.UnitID = &unit.ID
 
 	// Check tenant exists
 	var tenantExists int
-	db.QueryRow("SELECT COUNT(*) FROM tenants WHERE id = ?", l.TenantID).Scan(&tenantExists)
+	db.QueryRow("SELECT COUNT(*) FROM tenants WHERE id = ? AND deleted_at IS NULL", l.TenantID).Scan(&tenantExists)
 	if tenantExists == 0 {
 		jsonError(w, "Tenant not found", http.StatusBadRequest)
 		return
 	}
 
-	// Check for overlapping leases on the same property
+	// Check for overlapping leases on the same unit
 	var overlap int
 	db.QueryRow(`
 		SELECT COUNT(*) FROM leases
-		WHERE property_id = ?
+		WHERE unit_id = ?
 		AND status IN ('active', 'upcoming')
+		AND deleted_at IS NULL
 		AND NOT (end_date < ? OR start_date > ?)
-	`, l.PropertyID, l.StartDate, l.EndDate).Scan(&overlap)
+	`, unit.ID, l.StartDate, l.EndDate).Scan(&overlap)
 	if overlap > 0 {
-		jsonError(w, "This property already has an active or upcoming lease during this period", http.StatusConflict)
+		jsonError(w, "This unit already has an active or upcoming lease during this period", http.StatusConflict)
 		return
 	}
 
@@ -1243,10 +1782,10 @@ func createLease(w http.ResponseWriter, r *http.Request) {
 	}
 
 	result, err := db.Exec(`
-		INSERT INTO leases (property_id, tenant_id, start_date, end_date, monthly_rent,
+		INSERT INTO leases (property_id, unit_id, tenant_id, start_date, end_date, monthly_rent,
 			deposit_amount, status, payment_due_day, notes)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, l.PropertyID, l.TenantID, l.StartDate, l.EndDate, l.MonthlyRent,
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, l.PropertyID, l.UnitID, l.TenantID, l.StartDate, l.EndDate, l.MonthlyRent,
 		l.DepositAmount, l.Status, l.PaymentDueDay, l.Notes)
 
 	if err != nil {
@@ -1260,9 +1799,9 @@ func createLease(w http.ResponseWriter, r *http.Request) {
 	l.CreatedAt = time.Now()
 	l.UpdatedAt = time.Now()
 
-	// Update property availability
+	// Update unit (and so property) availability
 	if l.Status == "active" {
-		db.Exec("UPDATE properties SET available = FALSE WHERE id = ?", l.PropertyID)
+		setUnitAvailability(unit.ID, false)
 	}
 
 	jsonResponse(w, l, http.StatusCreated)
@@ -1289,27 +1828,38 @@ func updateLease(w http.ResponseWriter, r *http.Request, id int) {
 		}
 	}
 
+	if l.PropertyID != 0 || l.UnitID != nil {
+		unit, err := resolveUnit(l.PropertyID, l.UnitID)
+		if err != nil {
+			writeUnitError(w, err)
+			return
+		}
+		l.PropertyID = unit.PropertyID
+		l.UnitID = &unit.ID
+	}
+
 	// Check for overlapping leases (excluding current)
-	if l.PropertyID != 0 && l.StartDate != "" && l.EndDate != "" {
+	if l.UnitID != nil && l.StartDate != "" && l.EndDate != "" {
 		var overlap int
 		db.QueryRow(`
 			SELECT COUNT(*) FROM leases
-			WHERE property_id = ?
+			WHERE unit_id = ?
 			AND id != ?
 			AND status IN ('active', 'upcoming')
+			AND deleted_at IS NULL
 			AND NOT (end_date < ? OR start_date > ?)
-		`, l.PropertyID, id, l.StartDate, l.EndDate).Scan(&overlap)
+		`, *l.UnitID, id, l.StartDate, l.EndDate).Scan(&overlap)
 		if overlap > 0 {
-			jsonError(w, "This property already has an active or upcoming lease during this period", http.StatusConflict)
+			jsonError(w, "This unit already has an active or upcoming lease during this period", http.StatusConflict)
 			return
 		}
 	}
 
 	result, err := db.Exec(`
-		UPDATE leases SET property_id=?, tenant_id=?, start_date=?, end_date=?,
+		UPDATE leases SET property_id=?, unit_id=?, tenant_id=?, start_date=?, end_date=?,
 			monthly_rent=?, deposit_amount=?, status=?, payment_due_day=?, notes=?
-		WHERE id=?
-	`, l.PropertyID, l.TenantID, l.StartDate, l.EndDate, l.MonthlyRent,
+		WHERE id=? AND deleted_at IS NULL
+	`, l.PropertyID, l.UnitID, l.TenantID, l.StartDate, l.EndDate, l.MonthlyRent,
 		l.DepositAmount, l.Status, l.PaymentDueDay, l.Notes, id)
 
 	if err != nil {
@@ -1328,28 +1878,27 @@ func updateLease(w http.ResponseWriter, r *http.Request, id int) {
 	jsonResponse(w, l, http.StatusOK)
 }
 
+// deleteLease moves a lease and its payments to the trash (see trash.go).
 func deleteLease(w http.ResponseWriter, id int) {
 	// Get lease info before deleting
-	var propertyID int
+	var unitID sql.NullInt64
 	var status string
-	db.QueryRow("SELECT property_id, status FROM leases WHERE id = ?", id).Scan(&propertyID, &status)
+	db.QueryRow("SELECT unit_id, status FROM leases WHERE id = ?", id).Scan(&unitID, &status)
 
-	result, err := db.Exec("DELETE FROM leases WHERE id = ?", id)
+	found, err := softDelete("lease", id)
 	if err != nil {
 		log.Printf("Error deleting lease: %v", err)
 		jsonError(w, "Failed to delete lease", http.StatusInternalServerError)
 		return
 	}
-
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
+	if !found {
 		jsonError(w, "Lease not found", http.StatusNotFound)
 		return
 	}
 
-	// Update property availability if lease was active
-	if status == "active" {
-		db.Exec("UPDATE properties SET available = TRUE WHERE id = ?", propertyID)
+	// Update unit (and so property) availability if lease was active
+	if status == "active" && unitID.Valid {
+		setUnitAvailability(int(unitID.Int64), true)
 	}
 
 	w.WriteHeader(http.StatusNoContent)
@@ -1359,17 +1908,44 @@ func deleteLease(w http.ResponseWriter, id int) {
 // SCAN HELPERS
 // ============================================================================
 
+// propertyColumns is the select list scanProperty and scanPropertyRow expect.
+const propertyColumns = `id, name, address_line1, address_line2, city, state, zip,
+			   property_type, bedrooms, bathrooms, square_feet, monthly_rent,
+			   deposit_amount, available, available_date, description, amenities, image_url,
+			   created_at, updated_at, latitude, longitude, pet_policy, syndicate, slug, listing_status, owner_id`
+
+// loadProperty reads one property with its gallery and units.
+func loadProperty(id int) (Property, error) {
+	row := db.QueryRow(`
+		SELECT `+propertyColumns+`
+		FROM properties WHERE id = ?
+	`, id)
+
+	p, err := scanPropertyRow(row)
+	if err != nil {
+		return p, err
+	}
+	if p.Images, err = loadPropertyImages(id); err != nil {
+		return p, err
+	}
+	p.Units, err = loadUnits(db, id)
+	return p, err
+}
+
 func scanProperty(rows *sql.Rows) (Property, error) {
 	var p Property
 	var addressLine2, description, availableDate, imageURL sql.NullString
 	var squareFeet sql.NullInt64
 	var depositAmount sql.NullFloat64
-	var amenitiesJSON sql.NullString
+	var amenitiesJSON, petPolicy, slug sql.NullString
+	var latitude, longitude sql.NullFloat64
+	var syndicate bool
+	var ownerID sql.NullInt64
 
 	err := rows.Scan(&p.ID, &p.Name, &p.AddressLine1, &addressLine2, &p.City, &p.State, &p.Zip,
 		&p.PropertyType, &p.Bedrooms, &p.Bathrooms, &squareFeet, &p.MonthlyRent,
 		&depositAmount, &p.Available, &availableDate, &description, &amenitiesJSON, &imageURL,
-		&p.CreatedAt, &p.UpdatedAt)
+		&p.CreatedAt, &p.UpdatedAt, &latitude, &longitude, &petPolicy, &syndicate, &slug, &p.ListingStatus, &ownerID)
 	if err != nil {
 		return p, err
 	}
@@ -1396,6 +1972,16 @@ func scanProperty(rows *sql.Rows) (Property, error) {
 	if amenitiesJSON.Valid && amenitiesJSON.String != "" {
 		json.Unmarshal([]byte(amenitiesJSON.String), &p.Amenities)
 	}
+	if latitude.Valid && longitude.Valid {
+		p.Latitude, p.Longitude = &latitude.Float64, &longitude.Float64
+	}
+	p.PetPolicy = nullStringPtr(petPolicy)
+	p.Syndicate = &syndicate
+	p.Slug = slug.String
+	if ownerID.Valid {
+		id := int(ownerID.Int64)
+		p.OwnerID = &id
+	}
 
 	return p, nil
 }
@@ -1405,12 +1991,15 @@ func scanPropertyRow(row *sql.Row) (Property, error) {
 	var addressLine2, description, availableDate, imageURL sql.NullString
 	var squareFeet sql.NullInt64
 	var depositAmount sql.NullFloat64
-	var amenitiesJSON sql.NullString
+	var amenitiesJSON, petPolicy, slug sql.NullString
+	var latitude, longitude sql.NullFloat64
+	var syndicate bool
+	var ownerID sql.NullInt64
 
 	err := row.Scan(&p.ID, &p.Name, &p.AddressLine1, &addressLine2, &p.City, &p.State, &p.Zip,
 		&p.PropertyType, &p.Bedrooms, &p.Bathrooms, &squareFeet, &p.MonthlyRent,
 		&depositAmount, &p.Available, &availableDate, &description, &amenitiesJSON, &imageURL,
-		&p.CreatedAt, &p.UpdatedAt)
+		&p.CreatedAt, &p.UpdatedAt, &latitude, &longitude, &petPolicy, &syndicate, &slug, &p.ListingStatus, &ownerID)
 	if err != nil {
 		return p, err
 	}
@@ -1437,6 +2026,16 @@ func scanPropertyRow(row *sql.Row) (Property, error) {
 	if amenitiesJSON.Valid && amenitiesJSON.String != "" {
 		json.Unmarshal([]byte(amenitiesJSON.String), &p.Amenities)
 	}
+	if latitude.Valid && longitude.Valid {
+		p.Latitude, p.Longitude = &latitude.Float64, &longitude.Float64
+	}
+	p.PetPolicy = nullStringPtr(petPolicy)
+	p.Syndicate = &syndicate
+	p.Slug = slug.String
+	if ownerID.Valid {
+		id := int(ownerID.Int64)
+		p.OwnerID = &id
+	}
 
 	return p, nil
 }
@@ -1503,10 +2102,12 @@ func scanLeaseWithJoins(rows *sql.Rows) (Lease, error) {
 	var l Lease
 	var depositAmount sql.NullFloat64
 	var notes, propertyName, tenantName sql.NullString
+	var unitID sql.NullInt64
+	var unitNumber sql.NullString
 
 	err := rows.Scan(&l.ID, &l.PropertyID, &l.TenantID, &l.StartDate, &l.EndDate,
 		&l.MonthlyRent, &depositAmount, &l.Status, &l.PaymentDueDay,
-		&notes, &l.CreatedAt, &l.UpdatedAt, &propertyName, &tenantName)
+		&notes, &l.CreatedAt, &l.UpdatedAt, &propertyName, &tenantName, &unitID, &unitNumber)
 	if err != nil {
 		return l, err
 	}
@@ -1523,6 +2124,8 @@ func scanLeaseWithJoins(rows *sql.Rows) (Lease, error) {
 	if tenantName.Valid {
 		l.TenantName = &tenantName.String
 	}
+	l.UnitID = nullIntPtr(unitID)
+	l.UnitNumber = nullStringPtr(unitNumber)
 
 	return l, nil
 }
@@ -1531,10 +2134,12 @@ func scanLeaseWithJoinsRow(row *sql.Row) (Lease, error) {
 	var l Lease
 	var depositAmount sql.NullFloat64
 	var notes, propertyName, tenantName sql.NullString
+	var unitID sql.NullInt64
+	var unitNumber sql.NullString
 
 	err := row.Scan(&l.ID, &l.PropertyID, &l.TenantID, &l.StartDate, &l.EndDate,
 		&l.MonthlyRent, &depositAmount, &l.Status, &l.PaymentDueDay,
-		&notes, &l.CreatedAt, &l.UpdatedAt, &propertyName, &tenantName)
+		&notes, &l.CreatedAt, &l.UpdatedAt, &propertyName, &tenantName, &unitID, &unitNumber)
 	if err != nil {
 		return l, err
 	}
@@ -1551,6 +2156,8 @@ func scanLeaseWithJoinsRow(row *sql.Row) (Lease, error) {
 	if tenantName.Valid {
 		l.TenantName = &tenantName.String
 	}
+	l.UnitID = nullIntPtr(unitID)
+	l.UnitNumber = nullStringPtr(unitNumber)
 
 	return l, nil
 }
@@ -1578,7 +2185,7 @@ func tenantLoginHandler(w http.ResponseWriter, r *http.Request) {
 
 	var tenantID int
 	var passwordHash sql.NullString
-	err := db.QueryRow("SELECT id, password_hash FROM tenants WHERE email = ?", req.Email).
+	err := db.QueryRow("SELECT id, password_hash FROM tenants WHERE email = ? AND deleted_at IS NULL", req.Email).
 		Scan(&tenantID, &passwordHash)
 	if err == sql.ErrNoRows {
 		jsonError(w, "Invalid email or password", http.StatusUnauthorized)
@@ -1602,7 +2209,7 @@ func tenantLoginHandler(w http.ResponseWriter, r *http.Request) {
 
 	token := generateToken()
 	tenantTokenMutex.Lock()
-	tenantTokenStore[token] = tenantSession{TenantID: tenantID, Expiry: time.Now().Add(24 * time.Hour)}
+	tenantTokenStore[token] = tenantSession{TenantID: tenantID, Expiry: time.Now().Add(cfg.TenantTokenTTL)}
 	tenantTokenMutex.Unlock()
 
 	jsonResponse(w, LoginResponse{Token: token}, http.StatusOK)
@@ -1641,7 +2248,7 @@ func tenantMeHandler(w http.ResponseWriter, r *http.Request) {
 	row := db.QueryRow(`
 		SELECT id, first_name, last_name, email, phone, date_of_birth,
 			   emergency_contact_name, emergency_contact_phone, notes, created_at, updated_at
-		FROM tenants WHERE id = ?
+		FROM tenants WHERE id = ? AND deleted_at IS NULL
 	`, tenantID)
 
 	t, err := scanTenantRow(row)
@@ -1706,11 +2313,13 @@ func tenantLeaseHandler(w http.ResponseWriter, r *http.Request) {
 			   l.monthly_rent, l.deposit_amount, l.status, l.payment_due_day,
 			   l.notes, l.created_at, l.updated_at,
 			   p.name as property_name,
-			   CONCAT(t.first_name, ' ', t.last_name) as tenant_name
+			   CONCAT(t.first_name, ' ', t.last_name) as tenant_name,
+			   l.unit_id, u.unit_number
 		FROM leases l
 		JOIN properties p ON l.property_id = p.id
 		JOIN tenants t ON l.tenant_id = t.id
-		WHERE l.tenant_id = ? AND l.status IN ('active', 'upcoming')
+		LEFT JOIN units u ON l.unit_id = u.id
+		WHERE l.tenant_id = ? AND l.status IN ('active', 'upcoming') AND l.deleted_at IS NULL
 		ORDER BY l.start_date DESC
 		LIMIT 1
 	`, tenantID)
@@ -1722,11 +2331,13 @@ func tenantLeaseHandler(w http.ResponseWriter, r *http.Request) {
 				   l.monthly_rent, l.deposit_amount, l.status, l.payment_due_day,
 				   l.notes, l.created_at, l.updated_at,
 				   p.name as property_name,
-				   CONCAT(t.first_name, ' ', t.last_name) as tenant_name
+				   CONCAT(t.first_name, ' ', t.last_name) as tenant_name,
+				   l.unit_id, u.unit_number
 			FROM leases l
 			JOIN properties p ON l.property_id = p.id
 			JOIN tenants t ON l.tenant_id = t.id
-			WHERE l.tenant_id = ?
+			LEFT JOIN units u ON l.unit_id = u.id
+			WHERE l.tenant_id = ? AND l.deleted_at IS NULL
 			ORDER BY l.end_date DESC
 			LIMIT 1
 		`, tenantID)
@@ -1782,9 +2393,11 @@ func tenantRequestByIDHandler(w http.ResponseWriter, r *http.Request) {
 		SELECT mr.id, mr.tenant_id, mr.property_id, mr.title, mr.description,
 			   mr.category, mr.priority, mr.status, mr.admin_notes,
 			   mr.created_at, mr.updated_at,
-			   p.name as property_name
+			   p.name as property_name,
+			   mr.unit_id, u.unit_number
 		FROM maintenance_requests mr
 		JOIN properties p ON mr.property_id = p.id
+		LEFT JOIN units u ON mr.unit_id = u.id
 		WHERE mr.id = ? AND mr.tenant_id = ?
 	`, id, tenantID)
 
@@ -1806,9 +2419,11 @@ func getTenantRequests(w http.ResponseWriter, tenantID int) {
 		SELECT mr.id, mr.tenant_id, mr.property_id, mr.title, mr.description,
 			   mr.category, mr.priority, mr.status, mr.admin_notes,
 			   mr.created_at, mr.updated_at,
-			   p.name as property_name
+			   p.name as property_name,
+			   mr.unit_id, u.unit_number
 		FROM maintenance_requests mr
 		JOIN properties p ON mr.property_id = p.id
+		LEFT JOIN units u ON mr.unit_id = u.id
 		WHERE mr.tenant_id = ?
 		ORDER BY mr.created_at DESC
 	`, tenantID)
@@ -1851,11 +2466,12 @@ func submitTenantRequest(w http.ResponseWriter, r *http.Request, tenantID int) {
 	}
 
 	var propertyID int
+	var unitID sql.NullInt64
 	err := db.QueryRow(`
-		SELECT property_id FROM leases
-		WHERE tenant_id = ? AND status IN ('active', 'upcoming')
+		SELECT property_id, unit_id FROM leases
+		WHERE tenant_id = ? AND status IN ('active', 'upcoming') AND deleted_at IS NULL
 		ORDER BY start_date DESC LIMIT 1
-	`, tenantID).Scan(&propertyID)
+	`, tenantID).Scan(&propertyID, &unitID)
 	if err == sql.ErrNoRows {
 		jsonError(w, "No active lease found. Cannot submit a request without an active lease.", http.StatusBadRequest)
 		return
@@ -1864,11 +2480,12 @@ func submitTenantRequest(w http.ResponseWriter, r *http.Request, tenantID int) {
 		jsonError(w, "Database error", http.StatusInternalServerError)
 		return
 	}
+	req.UnitID = nullIntPtr(unitID)
 
 	result, err := db.Exec(`
-		INSERT INTO maintenance_requests (tenant_id, property_id, title, description, category, priority, status)
-		VALUES (?, ?, ?, ?, ?, ?, 'open')
-	`, tenantID, propertyID, req.Title, req.Description, req.Category, req.Priority)
+		INSERT INTO maintenance_requests (tenant_id, property_id, unit_id, title, description, category, priority, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 'open')
+	`, tenantID, propertyID, req.UnitID, req.Title, req.Description, req.Category, req.Priority)
 	if err != nil {
 		log.Printf("Error creating maintenance request: %v", err)
 		jsonError(w, "Failed to submit request", http.StatusInternalServerError)
@@ -1901,10 +2518,12 @@ func tenantPaymentsHandler(w http.ResponseWriter, r *http.Request) {
 		SELECT p.id, p.lease_id, p.tenant_id, p.property_id, p.amount,
 			   p.payment_date, p.payment_type, p.status, p.notes,
 			   p.created_at, p.updated_at,
-			   prop.name as property_name
+			   prop.name as property_name,
+			   p.unit_id, u.unit_number
 		FROM payments p
 		JOIN properties prop ON p.property_id = prop.id
-		WHERE p.tenant_id = ?
+		LEFT JOIN units u ON p.unit_id = u.id
+		WHERE p.tenant_id = ? AND p.deleted_at IS NULL
 		ORDER BY p.payment_date DESC
 	`, tenantID)
 	if err != nil {
@@ -1941,15 +2560,21 @@ func adminRequestsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	getAdminRequests(w, r)
+}
+
+func getAdminRequests(w http.ResponseWriter, r *http.Request) {
 	query := `
 		SELECT mr.id, mr.tenant_id, mr.property_id, mr.title, mr.description,
 			   mr.category, mr.priority, mr.status, mr.admin_notes,
 			   mr.created_at, mr.updated_at,
 			   CONCAT(t.first_name, ' ', t.last_name) as tenant_name,
-			   p.name as property_name
+			   p.name as property_name,
+			   mr.unit_id, u.unit_number
 		FROM maintenance_requests mr
 		JOIN tenants t ON mr.tenant_id = t.id
 		JOIN properties p ON mr.property_id = p.id
+		LEFT JOIN units u ON mr.unit_id = u.id
 		WHERE 1=1
 	`
 	args := []interface{}{}
@@ -1964,6 +2589,12 @@ func adminRequestsHandler(w http.ResponseWriter, r *http.Request) {
 			args = append(args, id)
 		}
 	}
+	if unitID := r.URL.Query().Get("unitId"); unitID != "" {
+		if id, err := strconv.Atoi(unitID); err == nil {
+			query += " AND mr.unit_id = ?"
+			args = append(args, id)
+		}
+	}
 	if tenantID := r.URL.Query().Get("tenantId"); tenantID != "" {
 		if id, err := strconv.Atoi(tenantID); err == nil {
 			query += " AND mr.tenant_id = ?"
@@ -1971,6 +2602,14 @@ func adminRequestsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	clause, ownerArgs, err := ownerFilter(r.URL.Query().Get("ownerId"), "mr.property_id")
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query += clause
+	args = append(args, ownerArgs...)
+
 	query += " ORDER BY mr.created_at DESC"
 
 	rows, err := db.Query(query, args...)
@@ -2021,10 +2660,12 @@ func getAdminRequestByID(w http.ResponseWriter, id int) {
 			   mr.category, mr.priority, mr.status, mr.admin_notes,
 			   mr.created_at, mr.updated_at,
 			   CONCAT(t.first_name, ' ', t.last_name) as tenant_name,
-			   p.name as property_name
+			   p.name as property_name,
+			   mr.unit_id, u.unit_number
 		FROM maintenance_requests mr
 		JOIN tenants t ON mr.tenant_id = t.id
 		JOIN properties p ON mr.property_id = p.id
+		LEFT JOIN units u ON mr.unit_id = u.id
 		WHERE mr.id = ?
 	`, id)
 
@@ -2117,11 +2758,13 @@ func getAdminPayments(w http.ResponseWriter, r *http.Request) {
 			   p.payment_date, p.payment_type, p.status, p.notes,
 			   p.created_at, p.updated_at,
 			   CONCAT(t.first_name, ' ', t.last_name) as tenant_name,
-			   prop.name as property_name
+			   prop.name as property_name,
+			   p.unit_id, u.unit_number
 		FROM payments p
 		JOIN tenants t ON p.tenant_id = t.id
 		JOIN properties prop ON p.property_id = prop.id
-		WHERE 1=1
+		LEFT JOIN units u ON p.unit_id = u.id
+		WHERE p.deleted_at IS NULL
 	`
 	args := []interface{}{}
 
@@ -2137,6 +2780,12 @@ func getAdminPayments(w http.ResponseWriter, r *http.Request) {
 			args = append(args, id)
 		}
 	}
+	if unitID := r.URL.Query().Get("unitId"); unitID != "" {
+		if id, err := strconv.Atoi(unitID); err == nil {
+			query += " AND p.unit_id = ?"
+			args = append(args, id)
+		}
+	}
 	if status := r.URL.Query().Get("status"); status != "" {
 		query += " AND p.status = ?"
 		args = append(args, status)
@@ -2146,6 +2795,14 @@ func getAdminPayments(w http.ResponseWriter, r *http.Request) {
 		args = append(args, payType)
 	}
 
+	clause, ownerArgs, err := ownerFilter(r.URL.Query().Get("ownerId"), "p.property_id")
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query += clause
+	args = append(args, ownerArgs...)
+
 	query += " ORDER BY p.payment_date DESC"
 
 	rows, err := db.Query(query, args...)
@@ -2187,8 +2844,9 @@ func createAdminPayment(w http.ResponseWriter, r *http.Request) {
 		pay.Status = "completed"
 	}
 
-	err := db.QueryRow("SELECT tenant_id, property_id FROM leases WHERE id = ?", pay.LeaseID).
-		Scan(&pay.TenantID, &pay.PropertyID)
+	var unitID sql.NullInt64
+	err := db.QueryRow("SELECT tenant_id, property_id, unit_id FROM leases WHERE id = ? AND deleted_at IS NULL", pay.LeaseID).
+		Scan(&pay.TenantID, &pay.PropertyID, &unitID)
 	if err == sql.ErrNoRows {
 		jsonError(w, "Lease not found", http.StatusBadRequest)
 		return
@@ -2197,11 +2855,12 @@ func createAdminPayment(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, "Database error", http.StatusInternalServerError)
 		return
 	}
+	pay.UnitID = nullIntPtr(unitID)
 
 	result, err := db.Exec(`
-		INSERT INTO payments (lease_id, tenant_id, property_id, amount, payment_date, payment_type, status, notes)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, pay.LeaseID, pay.TenantID, pay.PropertyID, pay.Amount, pay.PaymentDate,
+		INSERT INTO payments (lease_id, tenant_id, property_id, unit_id, amount, payment_date, payment_type, status, notes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, pay.LeaseID, pay.TenantID, pay.PropertyID, pay.UnitID, pay.Amount, pay.PaymentDate,
 		pay.PaymentType, pay.Status, pay.Notes)
 	if err != nil {
 		log.Printf("Error creating payment: %v", err)
@@ -2223,11 +2882,13 @@ func getAdminPaymentByID(w http.ResponseWriter, id int) {
 			   p.payment_date, p.payment_type, p.status, p.notes,
 			   p.created_at, p.updated_at,
 			   CONCAT(t.first_name, ' ', t.last_name) as tenant_name,
-			   prop.name as property_name
+			   prop.name as property_name,
+			   p.unit_id, u.unit_number
 		FROM payments p
 		JOIN tenants t ON p.tenant_id = t.id
 		JOIN properties prop ON p.property_id = prop.id
-		WHERE p.id = ?
+		LEFT JOIN units u ON p.unit_id = u.id
+		WHERE p.id = ? AND p.deleted_at IS NULL
 	`, id)
 
 	pay, err := scanPaymentWithJoinsRow(row)
@@ -2252,7 +2913,7 @@ func updateAdminPayment(w http.ResponseWriter, r *http.Request, id int) {
 
 	result, err := db.Exec(`
 		UPDATE payments SET amount=?, payment_date=?, payment_type=?, status=?, notes=?
-		WHERE id=?
+		WHERE id=? AND deleted_at IS NULL
 	`, pay.Amount, pay.PaymentDate, pay.PaymentType, pay.Status, pay.Notes, id)
 	if err != nil {
 		log.Printf("Error updating payment: %v", err)
@@ -2269,16 +2930,15 @@ func updateAdminPayment(w http.ResponseWriter, r *http.Request, id int) {
 	getAdminPaymentByID(w, id)
 }
 
+// deleteAdminPayment moves a payment to the trash (see trash.go).
 func deleteAdminPayment(w http.ResponseWriter, id int) {
-	result, err := db.Exec("DELETE FROM payments WHERE id = ?", id)
+	found, err := softDelete("payment", id)
 	if err != nil {
 		log.Printf("Error deleting payment: %v", err)
 		jsonError(w, "Failed to delete payment", http.StatusInternalServerError)
 		return
 	}
-
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
+	if !found {
 		jsonError(w, "Payment not found", http.StatusNotFound)
 		return
 	}
@@ -2293,10 +2953,12 @@ func deleteAdminPayment(w http.ResponseWriter, id int) {
 func scanMaintenanceRequest(rows *sql.Rows) (MaintenanceRequest, error) {
 	var req MaintenanceRequest
 	var adminNotes, propertyName sql.NullString
+	var unitID sql.NullInt64
+	var unitNumber sql.NullString
 
 	err := rows.Scan(&req.ID, &req.TenantID, &req.PropertyID, &req.Title, &req.Description,
 		&req.Category, &req.Priority, &req.Status, &adminNotes,
-		&req.CreatedAt, &req.UpdatedAt, &propertyName)
+		&req.CreatedAt, &req.UpdatedAt, &propertyName, &unitID, &unitNumber)
 	if err != nil {
 		return req, err
 	}
@@ -2307,6 +2969,8 @@ func scanMaintenanceRequest(rows *sql.Rows) (MaintenanceRequest, error) {
 	if propertyName.Valid {
 		req.PropertyName = &propertyName.String
 	}
+	req.UnitID = nullIntPtr(unitID)
+	req.UnitNumber = nullStringPtr(unitNumber)
 
 	return req, nil
 }
@@ -2314,10 +2978,12 @@ func scanMaintenanceRequest(rows *sql.Rows) (MaintenanceRequest, error) {
 func scanMaintenanceRequestRow(row *sql.Row) (MaintenanceRequest, error) {
 	var req MaintenanceRequest
 	var adminNotes, propertyName sql.NullString
+	var unitID sql.NullInt64
+	var unitNumber sql.NullString
 
 	err := row.Scan(&req.ID, &req.TenantID, &req.PropertyID, &req.Title, &req.Description,
 		&req.Category, &req.Priority, &req.Status, &adminNotes,
-		&req.CreatedAt, &req.UpdatedAt, &propertyName)
+		&req.CreatedAt, &req.UpdatedAt, &propertyName, &unitID, &unitNumber)
 	if err != nil {
 		return req, err
 	}
@@ -2328,6 +2994,8 @@ func scanMaintenanceRequestRow(row *sql.Row) (MaintenanceRequest, error) {
 	if propertyName.Valid {
 		req.PropertyName = &propertyName.String
 	}
+	req.UnitID = nullIntPtr(unitID)
+	req.UnitNumber = nullStringPtr(unitNumber)
 
 	return req, nil
 }
@@ -2335,10 +3003,12 @@ func scanMaintenanceRequestRow(row *sql.Row) (MaintenanceRequest, error) {
 func scanMaintenanceRequestWithTenant(rows *sql.Rows) (MaintenanceRequest, error) {
 	var req MaintenanceRequest
 	var adminNotes, tenantName, propertyName sql.NullString
+	var unitID sql.NullInt64
+	var unitNumber sql.NullString
 
 	err := rows.Scan(&req.ID, &req.TenantID, &req.PropertyID, &req.Title, &req.Description,
 		&req.Category, &req.Priority, &req.Status, &adminNotes,
-		&req.CreatedAt, &req.UpdatedAt, &tenantName, &propertyName)
+		&req.CreatedAt, &req.UpdatedAt, &tenantName, &propertyName, &unitID, &unitNumber)
 	if err != nil {
 		return req, err
 	}
@@ -2352,6 +3022,8 @@ func scanMaintenanceRequestWithTenant(rows *sql.Rows) (MaintenanceRequest, error
 	if propertyName.Valid {
 		req.PropertyName = &propertyName.String
 	}
+	req.UnitID = nullIntPtr(unitID)
+	req.UnitNumber = nullStringPtr(unitNumber)
 
 	return req, nil
 }
@@ -2359,10 +3031,12 @@ func scanMaintenanceRequestWithTenant(rows *sql.Rows) (MaintenanceRequest, error
 func scanMaintenanceRequestWithTenantRow(row *sql.Row) (MaintenanceRequest, error) {
 	var req MaintenanceRequest
 	var adminNotes, tenantName, propertyName sql.NullString
+	var unitID sql.NullInt64
+	var unitNumber sql.NullString
 
 	err := row.Scan(&req.ID, &req.TenantID, &req.PropertyID, &req.Title, &req.Description,
 		&req.Category, &req.Priority, &req.Status, &adminNotes,
-		&req.CreatedAt, &req.UpdatedAt, &tenantName, &propertyName)
+		&req.CreatedAt, &req.UpdatedAt, &tenantName, &propertyName, &unitID, &unitNumber)
 	if err != nil {
 		return req, err
 	}
@@ -2376,6 +3050,8 @@ func scanMaintenanceRequestWithTenantRow(row *sql.Row) (MaintenanceRequest, erro
 	if propertyName.Valid {
 		req.PropertyName = &propertyName.String
 	}
+	req.UnitID = nullIntPtr(unitID)
+	req.UnitNumber = nullStringPtr(unitNumber)
 
 	return req, nil
 }
@@ -2383,10 +3059,12 @@ func scanMaintenanceRequestWithTenantRow(row *sql.Row) (MaintenanceRequest, erro
 func scanPayment(rows *sql.Rows) (Payment, error) {
 	var pay Payment
 	var notes, propertyName sql.NullString
+	var unitID sql.NullInt64
+	var unitNumber sql.NullString
 
 	err := rows.Scan(&pay.ID, &pay.LeaseID, &pay.TenantID, &pay.PropertyID, &pay.Amount,
 		&pay.PaymentDate, &pay.PaymentType, &pay.Status, &notes,
-		&pay.CreatedAt, &pay.UpdatedAt, &propertyName)
+		&pay.CreatedAt, &pay.UpdatedAt, &propertyName, &unitID, &unitNumber)
 	if err != nil {
 		return pay, err
 	}
@@ -2397,6 +3075,8 @@ func scanPayment(rows *sql.Rows) (Payment, error) {
 	if propertyName.Valid {
 		pay.PropertyName = &propertyName.String
 	}
+	pay.UnitID = nullIntPtr(unitID)
+	pay.UnitNumber = nullStringPtr(unitNumber)
 
 	return pay, nil
 }
@@ -2404,10 +3084,12 @@ func scanPayment(rows *sql.Rows) (Payment, error) {
 func scanPaymentWithJoins(rows *sql.Rows) (Payment, error) {
 	var pay Payment
 	var notes, tenantName, propertyName sql.NullString
+	var unitID sql.NullInt64
+	var unitNumber sql.NullString
 
 	err := rows.Scan(&pay.ID, &pay.LeaseID, &pay.TenantID, &pay.PropertyID, &pay.Amount,
 		&pay.PaymentDate, &pay.PaymentType, &pay.Status, &notes,
-		&pay.CreatedAt, &pay.UpdatedAt, &tenantName, &propertyName)
+		&pay.CreatedAt, &pay.UpdatedAt, &tenantName, &propertyName, &unitID, &unitNumber)
 	if err != nil {
 		return pay, err
 	}
@@ -2421,6 +3103,8 @@ func scanPaymentWithJoins(rows *sql.Rows) (Payment, error) {
 	if propertyName.Valid {
 		pay.PropertyName = &propertyName.String
 	}
+	pay.UnitID = nullIntPtr(unitID)
+	pay.UnitNumber = nullStringPtr(unitNumber)
 
 	return pay, nil
 }
@@ -2428,10 +3112,12 @@ func scanPaymentWithJoins(rows *sql.Rows) (Payment, error) {
 func scanPaymentWithJoinsRow(row *sql.Row) (Payment, error) {
 	var pay Payment
 	var notes, tenantName, propertyName sql.NullString
+	var unitID sql.NullInt64
+	var unitNumber sql.NullString
 
 	err := row.Scan(&pay.ID, &pay.LeaseID, &pay.TenantID, &pay.PropertyID, &pay.Amount,
 		&pay.PaymentDate, &pay.PaymentType, &pay.Status, &notes,
-		&pay.CreatedAt, &pay.UpdatedAt, &tenantName, &propertyName)
+		&pay.CreatedAt, &pay.UpdatedAt, &tenantName, &propertyName, &unitID, &unitNumber)
 	if err != nil {
 		return pay, err
 	}
@@ -2445,6 +3131,8 @@ func scanPaymentWithJoinsRow(row *sql.Row) (Payment, error) {
 	if propertyName.Valid {
 		pay.PropertyName = &propertyName.String
 	}
+	pay.UnitID = nullIntPtr(unitID)
+	pay.UnitNumber = nullStringPtr(unitNumber)
 
 	return pay, nil
 }
@@ -0,0 +1,949 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// ============================================================================
+// TEST HARNESS
+// ============================================================================
+
+const testAdminPassword = "test-admin-password"
+
+func init() {
+	isSQLiteUniqueViolation = func(err error) bool {
+		var sqliteErr sqlite3.Error
+		return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+	}
+}
+
+// testEnv drives the real routing table against a throwaway SQLite database,
+// so the suite runs with plain `go test` and no MySQL server.
+type testEnv struct {
+	t       *testing.T
+	handler http.Handler
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	testDB, err := sql.Open("sqlite3", path+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	schema, err := os.ReadFile("testdata/schema_sqlite.sql")
+	if err != nil {
+		t.Fatalf("read schema: %v", err)
+	}
+	if _, err := testDB.Exec(string(schema)); err != nil {
+		t.Fatalf("apply schema: %v", err)
+	}
+
+	// The SQLite schema mirrors every migration, so record them as applied.
+	if _, err := migrateBaseline(testDB, migrationFiles, 1<<30); err != nil {
+		t.Fatalf("baseline migrations: %v", err)
+	}
+
+	prevDB := db
+	db = testDB
+	t.Cleanup(func() {
+		db = prevDB
+		testDB.Close()
+	})
+
+	// Sessions live in package-level maps; start each test with none.
+	tokenMutex.Lock()
+	tokenStore = make(map[string]time.Time)
+	tokenMutex.Unlock()
+	tenantTokenMutex.Lock()
+	tenantTokenStore = make(map[string]tenantSession)
+	tenantTokenMutex.Unlock()
+	ownerTokenMutex.Lock()
+	ownerTokenStore = make(map[string]ownerSession)
+	ownerTokenMutex.Unlock()
+	contactLimiter = newRateLimiter()
+	showingLimiter = newRateLimiter()
+	savedSearchLimiter = newRateLimiter()
+	applicationLimiter = newRateLimiter()
+
+	prevLog := accessLog
+	accessLog = slog.New(slog.NewJSONHandler(io.Discard, nil))
+	t.Cleanup(func() { accessLog = prevLog })
+
+	prevCfg := cfg
+	cfg = defaultConfig()
+	cfg.AdminPassword = testAdminPassword
+	cfg.Media.Dir = t.TempDir()
+	cfg.Media.PrivateDir = t.TempDir()
+	t.Cleanup(func() { cfg = prevCfg })
+
+	prevCipher := payoutCipher
+	payoutCipher, _ = newPayoutCipher(defaultPayoutEncryptionKey)
+	t.Cleanup(func() { payoutCipher = prevCipher })
+
+	// SQLite has no FULLTEXT; search a term table instead.
+	prevSearch := textSearch
+	textSearch = sqliteTermIndex{}
+	t.Cleanup(func() { textSearch = prevSearch })
+
+	prevStore, prevReceipts := mediaStore, receiptStore
+	mediaStore = newLocalMediaStore(cfg.Media)
+	receiptStore = localMediaStore{dir: cfg.Media.PrivateDir}
+	t.Cleanup(func() { mediaStore, receiptStore = prevStore, prevReceipts })
+
+	return &testEnv{t: t, handler: newHandler()}
+}
+
+// do issues a request and returns the recorder. body is JSON-encoded unless
+// it is already a string.
+func (e *testEnv) do(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	e.t.Helper()
+
+	var reader *bytes.Reader
+	switch b := body.(type) {
+	case nil:
+		reader = bytes.NewReader(nil)
+	case string:
+		reader = bytes.NewReader([]byte(b))
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			e.t.Fatalf("marshal body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// expect fails the test when the response status does not match.
+func (e *testEnv) expect(rec *httptest.ResponseRecorder, status int) {
+	e.t.Helper()
+	if rec.Code != status {
+		e.t.Fatalf("expected status %d, got %d: %s", status, rec.Code, rec.Body.String())
+	}
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+	return v
+}
+
+func (e *testEnv) adminToken() string {
+	e.t.Helper()
+	rec := e.do(http.MethodPost, "/api/admin/login", "", LoginRequest{Password: testAdminPassword})
+	e.expect(rec, http.StatusOK)
+	return decode[LoginResponse](e.t, rec).Token
+}
+
+func (e *testEnv) tenantToken(email, password string) string {
+	e.t.Helper()
+	rec := e.do(http.MethodPost, "/api/tenant/login", "", TenantLoginRequest{Email: email, Password: password})
+	e.expect(rec, http.StatusOK)
+	return decode[LoginResponse](e.t, rec).Token
+}
+
+func (e *testEnv) createProperty(token, name string, rent float64) int {
+	e.t.Helper()
+	rec := e.do(http.MethodPost, "/api/admin/properties", token, Property{
+		Name:         name,
+		AddressLine1: "1 Test St",
+		City:         "Portland",
+		State:        "OR",
+		Zip:          "97201",
+		PropertyType: "apartment",
+		Bedrooms:     2,
+		Bathrooms:    1,
+		MonthlyRent:  rent,
+		Available:    true,
+		Amenities:    []string{"Dishwasher"},
+	})
+	e.expect(rec, http.StatusCreated)
+	return decode[Property](e.t, rec).ID
+}
+
+func (e *testEnv) createTenant(token, email, password string) int {
+	e.t.Helper()
+	rec := e.do(http.MethodPost, "/api/admin/tenants", token, map[string]interface{}{
+		"firstName": "Test",
+		"lastName":  strings.Split(email, "@")[0],
+		"email":     email,
+		"password":  password,
+	})
+	e.expect(rec, http.StatusCreated)
+	return decode[Tenant](e.t, rec).ID
+}
+
+func (e *testEnv) createLease(token string, propertyID, tenantID int, start, end string) *httptest.ResponseRecorder {
+	e.t.Helper()
+	return e.do(http.MethodPost, "/api/admin/leases", token, Lease{
+		PropertyID:  propertyID,
+		TenantID:    tenantID,
+		StartDate:   start,
+		EndDate:     end,
+		MonthlyRent: 1500,
+	})
+}
+
+// day returns a YYYY-MM-DD date offset from today.
+func day(offset int) string {
+	return time.Now().AddDate(0, 0, offset).Format("2006-01-02")
+}
+
+// ============================================================================
+// PUBLIC
+// ============================================================================
+
+func TestHealthLive(t *testing.T) {
+	e := newTestEnv(t)
+	for _, path := range []string{"/api/health", "/api/health/live"} {
+		rec := e.do(http.MethodGet, path, "", nil)
+		e.expect(rec, http.StatusOK)
+		if body := decode[map[string]interface{}](t, rec); body["status"] != "healthy" {
+			t.Fatalf("unexpected health body: %v", body)
+		}
+	}
+}
+
+type readyBody struct {
+	Status     string                     `json:"status"`
+	Components map[string]componentHealth `json:"components"`
+}
+
+func TestHealthReady(t *testing.T) {
+	e := newTestEnv(t)
+	jobMutex.Lock()
+	prevJobs := jobStatuses
+	jobStatuses = map[string]jobStatus{}
+	jobMutex.Unlock()
+	t.Cleanup(func() {
+		jobMutex.Lock()
+		jobStatuses = prevJobs
+		jobMutex.Unlock()
+	})
+
+	rec := e.do(http.MethodGet, "/api/health/ready", "", nil)
+	e.expect(rec, http.StatusOK)
+	body := decode[readyBody](t, rec)
+	if body.Status != "ready" || body.Components["database"].Status != "ok" || body.Components["migrations"].Status != "ok" {
+		t.Fatalf("unexpected ready body: %+v", body)
+	}
+
+	// A failing background job is reported but leaves the replica ready.
+	runJob(context.Background(), scheduledJob{Name: "lease-statuses", Run: func(context.Context) error {
+		return fmt.Errorf("lock wait timeout")
+	}})
+	rec = e.do(http.MethodGet, "/api/health/ready", "", nil)
+	e.expect(rec, http.StatusOK)
+	if got := decode[readyBody](t, rec).Components["scheduler"]; got.Status != "degraded" || !strings.Contains(got.Error, "lock wait timeout") {
+		t.Fatalf("unexpected scheduler component: %+v", got)
+	}
+	runJob(context.Background(), scheduledJob{Name: "lease-statuses", Run: updateLeaseStatuses})
+
+	// A pending migration degrades readiness.
+	db.Exec("DELETE FROM schema_migrations WHERE version = 1")
+	rec = e.do(http.MethodGet, "/api/health/ready", "", nil)
+	e.expect(rec, http.StatusServiceUnavailable)
+	if got := decode[readyBody](t, rec).Components["migrations"]; got.Status != "pending" {
+		t.Fatalf("unexpected migrations component: %+v", got)
+	}
+
+	// And losing the database entirely.
+	db.Close()
+	rec = e.do(http.MethodGet, "/api/health/ready", "", nil)
+	e.expect(rec, http.StatusServiceUnavailable)
+	body = decode[readyBody](t, rec)
+	if body.Status != "degraded" || body.Components["database"].Status != "down" {
+		t.Fatalf("unexpected body with database down: %+v", body)
+	}
+	e.expect(e.do(http.MethodGet, "/api/health/live", "", nil), http.StatusOK)
+}
+
+func TestCORSPreflight(t *testing.T) {
+	e := newTestEnv(t)
+	prevOrigins := allowedOrigins
+	allowedOrigins = []string{"http://localhost:3000"}
+	t.Cleanup(func() { allowedOrigins = prevOrigins })
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/admin/properties", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	rec := httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+
+	e.expect(rec, http.StatusNoContent)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:3000" {
+		t.Fatalf("expected origin to be echoed, got %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodOptions, "/api/admin/properties", nil)
+	req.Header.Set("Origin", "http://evil.example")
+	rec = httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("unexpected allow-origin for unknown origin: %q", got)
+	}
+}
+
+func TestRequestBodyLimit(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Oak House", 1500)
+	cfg.HTTP.MaxBodyBytes = 64
+
+	big := map[string]string{"name": strings.Repeat("x", 200)}
+	e.expect(e.do(http.MethodPost, "/api/admin/properties", token, big), http.StatusRequestEntityTooLarge)
+
+	// Bodies without a declared length are cut off while decoding.
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/properties", strings.NewReader(`{"name":"`+strings.Repeat("x", 200)+`"}`))
+	req.ContentLength = -1
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+	e.expect(rec, http.StatusBadRequest)
+
+	// Claiming a multipart body doesn't lift the limit.
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/properties", strings.NewReader(`{"name":"`+strings.Repeat("x", 200)+`"}`))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+	e.expect(rec, http.StatusRequestEntityTooLarge)
+
+	// Upload routes have their own, larger limit.
+	e.expect(e.uploadImage(token, pid, testPNG(t, 40, 40), nil), http.StatusCreated)
+}
+
+func TestPublicProperties(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	cheap := e.createProperty(token, "Cheap Place", 900)
+	e.createProperty(token, "Fancy Place", 2500)
+
+	rec := e.do(http.MethodGet, "/api/properties", "", nil)
+	e.expect(rec, http.StatusOK)
+	if got := decode[[]Property](t, rec); len(got) != 2 {
+		t.Fatalf("expected 2 properties, got %d", len(got))
+	}
+
+	rec = e.do(http.MethodGet, "/api/properties?maxRent=1000&search=Cheap", "", nil)
+	e.expect(rec, http.StatusOK)
+	got := decode[[]Property](t, rec)
+	if len(got) != 1 || got[0].ID != cheap {
+		t.Fatalf("filter returned %+v", got)
+	}
+
+	rec = e.do(http.MethodGet, fmt.Sprintf("/api/properties/%d", cheap), "", nil)
+	e.expect(rec, http.StatusOK)
+	if p := decode[Property](t, rec); p.Name != "Cheap Place" || len(p.Amenities) != 1 {
+		t.Fatalf("unexpected property: %+v", p)
+	}
+
+	e.expect(e.do(http.MethodGet, "/api/properties/9999", "", nil), http.StatusNotFound)
+	e.expect(e.do(http.MethodGet, "/api/properties/abc", "", nil), http.StatusNotFound)
+	e.expect(e.do(http.MethodPost, "/api/properties", "", nil), http.StatusMethodNotAllowed)
+}
+
+// ============================================================================
+// AUTH
+// ============================================================================
+
+func TestAdminAuth(t *testing.T) {
+	e := newTestEnv(t)
+
+	e.expect(e.do(http.MethodPost, "/api/admin/login", "", LoginRequest{Password: "wrong"}), http.StatusUnauthorized)
+	e.expect(e.do(http.MethodPost, "/api/admin/login", "", "{not json"), http.StatusBadRequest)
+	e.expect(e.do(http.MethodGet, "/api/admin/login", "", nil), http.StatusMethodNotAllowed)
+
+	token := e.adminToken()
+	e.expect(e.do(http.MethodGet, "/api/admin/me", token, nil), http.StatusOK)
+	e.expect(e.do(http.MethodGet, "/api/admin/me", "bogus", nil), http.StatusUnauthorized)
+
+	e.expect(e.do(http.MethodPost, "/api/admin/logout", token, nil), http.StatusOK)
+	e.expect(e.do(http.MethodGet, "/api/admin/me", token, nil), http.StatusUnauthorized)
+}
+
+func TestAdminTokenExpiry(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	tokenMutex.Lock()
+	tokenStore[token] = time.Now().Add(-time.Minute)
+	tokenMutex.Unlock()
+
+	e.expect(e.do(http.MethodGet, "/api/admin/me", token, nil), http.StatusUnauthorized)
+}
+
+func TestAdminRoutesRequireAuth(t *testing.T) {
+	e := newTestEnv(t)
+	routes := []struct{ method, path string }{
+		{http.MethodGet, "/api/admin/me"},
+		{http.MethodGet, "/api/admin/dashboard/stats"},
+		{http.MethodGet, "/api/admin/properties"},
+		{http.MethodPost, "/api/admin/properties"},
+		{http.MethodGet, "/api/admin/properties/1"},
+		{http.MethodPut, "/api/admin/properties/1"},
+		{http.MethodDelete, "/api/admin/properties/1"},
+		{http.MethodGet, "/api/admin/properties/1/images"},
+		{http.MethodPost, "/api/admin/properties/1/images"},
+		{http.MethodPut, "/api/admin/properties/1/images/order"},
+		{http.MethodPut, "/api/admin/properties/1/images/1"},
+		{http.MethodDelete, "/api/admin/properties/1/images/1"},
+		{http.MethodGet, "/api/admin/properties/1/units"},
+		{http.MethodPost, "/api/admin/properties/1/units"},
+		{http.MethodGet, "/api/admin/properties/1/units/1"},
+		{http.MethodPut, "/api/admin/properties/1/units/1"},
+		{http.MethodDelete, "/api/admin/properties/1/units/1"},
+		{http.MethodGet, "/api/admin/tenants"},
+		{http.MethodPost, "/api/admin/tenants"},
+		{http.MethodGet, "/api/admin/tenants/1"},
+		{http.MethodPut, "/api/admin/tenants/1"},
+		{http.MethodDelete, "/api/admin/tenants/1"},
+		{http.MethodGet, "/api/admin/leases"},
+		{http.MethodPost, "/api/admin/leases"},
+		{http.MethodGet, "/api/admin/leases/1"},
+		{http.MethodPut, "/api/admin/leases/1"},
+		{http.MethodDelete, "/api/admin/leases/1"},
+		{http.MethodGet, "/api/admin/requests"},
+		{http.MethodGet, "/api/admin/requests/1"},
+		{http.MethodPut, "/api/admin/requests/1"},
+		{http.MethodGet, "/api/admin/payments"},
+		{http.MethodPost, "/api/admin/payments"},
+		{http.MethodGet, "/api/admin/payments/1"},
+		{http.MethodPut, "/api/admin/payments/1"},
+		{http.MethodDelete, "/api/admin/payments/1"},
+		{http.MethodGet, "/api/admin/properties/1/rent-history"},
+		{http.MethodPost, "/api/admin/properties/1/rent-history"},
+		{http.MethodDelete, "/api/admin/properties/1/rent-history/1"},
+		{http.MethodGet, "/api/admin/reports/vacancy"},
+		{http.MethodGet, "/api/admin/owners"},
+		{http.MethodPost, "/api/admin/owners"},
+		{http.MethodGet, "/api/admin/owners/1"},
+		{http.MethodPut, "/api/admin/owners/1"},
+		{http.MethodDelete, "/api/admin/owners/1"},
+		{http.MethodGet, "/api/admin/owners/1/statement"},
+		{http.MethodGet, "/api/admin/expenses"},
+		{http.MethodPost, "/api/admin/expenses"},
+		{http.MethodGet, "/api/admin/expenses/1"},
+		{http.MethodPut, "/api/admin/expenses/1"},
+		{http.MethodDelete, "/api/admin/expenses/1"},
+		{http.MethodPost, "/api/admin/expenses/1/receipt"},
+		{http.MethodGet, "/api/admin/trash"},
+		{http.MethodPost, "/api/admin/trash/tenant/1/restore"},
+		{http.MethodGet, "/api/admin/applications"},
+		{http.MethodGet, "/api/admin/applications/1"},
+		{http.MethodPut, "/api/admin/applications/1"},
+		{http.MethodPost, "/api/admin/applications/1/convert"},
+		{http.MethodPost, "/api/admin/applications/1/fee"},
+		{http.MethodPost, "/api/admin/applications/1/screen"},
+		{http.MethodGet, "/api/admin/leads"},
+		{http.MethodGet, "/api/admin/leads/1"},
+		{http.MethodPut, "/api/admin/leads/1"},
+		{http.MethodPost, "/api/admin/leads/1/notes"},
+		{http.MethodGet, "/api/admin/showings"},
+		{http.MethodPost, "/api/admin/showings"},
+		{http.MethodDelete, "/api/admin/showings/1"},
+		{http.MethodGet, "/api/admin/showings.ics"},
+	}
+
+	for _, rt := range routes {
+		t.Run(rt.method+" "+rt.path, func(t *testing.T) {
+			e.expect(e.do(rt.method, rt.path, "", nil), http.StatusUnauthorized)
+		})
+	}
+}
+
+func TestTenantRoutesRequireAuth(t *testing.T) {
+	e := newTestEnv(t)
+	adminToken := e.adminToken()
+	routes := []struct{ method, path string }{
+		{http.MethodGet, "/api/tenant/me"},
+		{http.MethodGet, "/api/tenant/lease"},
+		{http.MethodGet, "/api/tenant/requests"},
+		{http.MethodPost, "/api/tenant/requests"},
+		{http.MethodGet, "/api/tenant/requests/1"},
+		{http.MethodGet, "/api/tenant/payments"},
+	}
+
+	for _, rt := range routes {
+		t.Run(rt.method+" "+rt.path, func(t *testing.T) {
+			e.expect(e.do(rt.method, rt.path, "", nil), http.StatusUnauthorized)
+			// Admin sessions are not tenant sessions.
+			e.expect(e.do(rt.method, rt.path, adminToken, nil), http.StatusUnauthorized)
+		})
+	}
+}
+
+func TestTenantAuth(t *testing.T) {
+	e := newTestEnv(t)
+	admin := e.adminToken()
+	e.createTenant(admin, "nopass@example.com", "")
+	e.createTenant(admin, "alice@example.com", "alice-secret")
+	e.expect(e.do(http.MethodPost, "/api/admin/tenants", admin, map[string]interface{}{
+		"firstName": "Other", "lastName": "Alice", "email": "alice@example.com",
+	}), http.StatusConflict)
+
+	e.expect(e.do(http.MethodPost, "/api/tenant/login", "", TenantLoginRequest{Email: "alice@example.com"}), http.StatusBadRequest)
+	e.expect(e.do(http.MethodPost, "/api/tenant/login", "", TenantLoginRequest{Email: "alice@example.com", Password: "nope"}), http.StatusUnauthorized)
+	e.expect(e.do(http.MethodPost, "/api/tenant/login", "", TenantLoginRequest{Email: "nobody@example.com", Password: "x"}), http.StatusUnauthorized)
+	e.expect(e.do(http.MethodPost, "/api/tenant/login", "", TenantLoginRequest{Email: "nopass@example.com", Password: "x"}), http.StatusUnauthorized)
+
+	token := e.tenantToken("alice@example.com", "alice-secret")
+	rec := e.do(http.MethodGet, "/api/tenant/me", token, nil)
+	e.expect(rec, http.StatusOK)
+	if me := decode[Tenant](t, rec); me.Email != "alice@example.com" {
+		t.Fatalf("unexpected tenant: %+v", me)
+	}
+
+	// Tenant sessions are not admin sessions.
+	e.expect(e.do(http.MethodGet, "/api/admin/me", token, nil), http.StatusUnauthorized)
+
+	e.expect(e.do(http.MethodPost, "/api/tenant/logout", token, nil), http.StatusOK)
+	e.expect(e.do(http.MethodGet, "/api/tenant/me", token, nil), http.StatusUnauthorized)
+}
+
+// ============================================================================
+// ADMIN CRUD
+// ============================================================================
+
+func TestAdminPropertiesCRUD(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	e.expect(e.do(http.MethodPost, "/api/admin/properties", token, Property{Name: "Missing address"}), http.StatusBadRequest)
+
+	id := e.createProperty(token, "Clover Flat", 1200)
+	path := fmt.Sprintf("/api/admin/properties/%d", id)
+
+	rec := e.do(http.MethodGet, path, token, nil)
+	e.expect(rec, http.StatusOK)
+	p := decode[Property](t, rec)
+
+	p.MonthlyRent = 1300
+	p.Name = "Clover Flat Renovated"
+	e.expect(e.do(http.MethodPut, path, token, p), http.StatusOK)
+
+	rec = e.do(http.MethodGet, path, token, nil)
+	e.expect(rec, http.StatusOK)
+	if got := decode[Property](t, rec); got.MonthlyRent != 1300 || got.Name != "Clover Flat Renovated" {
+		t.Fatalf("update not persisted: %+v", got)
+	}
+
+	rec = e.do(http.MethodGet, "/api/admin/properties", token, nil)
+	e.expect(rec, http.StatusOK)
+	if got := decode[[]Property](t, rec); len(got) != 1 {
+		t.Fatalf("expected 1 property, got %d", len(got))
+	}
+
+	e.expect(e.do(http.MethodPut, "/api/admin/properties/9999", token, p), http.StatusNotFound)
+	e.expect(e.do(http.MethodGet, "/api/admin/properties/9999", token, nil), http.StatusNotFound)
+	e.expect(e.do(http.MethodGet, "/api/admin/properties/x", token, nil), http.StatusBadRequest)
+	e.expect(e.do(http.MethodPatch, path, token, nil), http.StatusMethodNotAllowed)
+}
+
+func TestAdminTenantsCRUD(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	e.expect(e.do(http.MethodPost, "/api/admin/tenants", token, Tenant{FirstName: "No"}), http.StatusBadRequest)
+
+	id := e.createTenant(token, "bob@example.com", "")
+	path := fmt.Sprintf("/api/admin/tenants/%d", id)
+
+	rec := e.do(http.MethodGet, path, token, nil)
+	e.expect(rec, http.StatusOK)
+	tenant := decode[Tenant](t, rec)
+
+	// Setting a password via update grants portal access.
+	e.expect(e.do(http.MethodPut, path, token, map[string]interface{}{
+		"firstName": tenant.FirstName,
+		"lastName":  "Builder",
+		"email":     tenant.Email,
+		"password":  "bob-secret",
+	}), http.StatusOK)
+	e.tenantToken("bob@example.com", "bob-secret")
+
+	rec = e.do(http.MethodGet, "/api/admin/tenants", token, nil)
+	e.expect(rec, http.StatusOK)
+	if got := decode[[]Tenant](t, rec); len(got) != 1 || got[0].LastName != "Builder" {
+		t.Fatalf("unexpected tenants: %+v", got)
+	}
+
+	e.expect(e.do(http.MethodGet, "/api/admin/tenants/9999", token, nil), http.StatusNotFound)
+	e.expect(e.do(http.MethodPut, "/api/admin/tenants/9999", token, tenant), http.StatusNotFound)
+}
+
+func TestDashboardStats(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	p1 := e.createProperty(token, "One", 1000)
+	p2 := e.createProperty(token, "Two", 2000)
+	e.createProperty(token, "Three", 3000)
+	t1 := e.createTenant(token, "t1@example.com", "")
+	t2 := e.createTenant(token, "t2@example.com", "")
+
+	e.expect(e.createLease(token, p1, t1, day(-30), day(300)), http.StatusCreated)
+	e.expect(e.createLease(token, p2, t2, day(30), day(400)), http.StatusCreated)
+
+	rec := e.do(http.MethodGet, "/api/admin/dashboard/stats", token, nil)
+	e.expect(rec, http.StatusOK)
+	stats := decode[DashboardStats](t, rec)
+
+	want := DashboardStats{
+		TotalProperties:     3,
+		AvailableProperties: 2,
+		TotalTenants:        2,
+		ActiveLeases:        1,
+		UpcomingLeases:      1,
+		TotalLeases:         2,
+		MonthlyRevenue:      1500,
+		TotalUnits:          3,
+		OccupiedUnits:       1,
+		Buildings: []BuildingOccupancy{
+			{PropertyID: p1, PropertyName: "One", TotalUnits: 1, OccupiedUnits: 1, OccupancyRate: 100},
+			{PropertyID: p1 + 2, PropertyName: "Three", TotalUnits: 1, VacantUnits: 1},
+			{PropertyID: p2, PropertyName: "Two", TotalUnits: 1, VacantUnits: 1},
+		},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Fatalf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+// ============================================================================
+// LEASES
+// ============================================================================
+
+func TestCreateLeaseValidation(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Lease Target", 1500)
+	tid := e.createTenant(token, "lease@example.com", "")
+
+	cases := []struct {
+		name       string
+		propertyID int
+		tenantID   int
+		start, end string
+		status     int
+	}{
+		{"missing fields", 0, tid, day(0), day(30), http.StatusBadRequest},
+		{"bad date", pid, tid, "01/02/2025", day(30), http.StatusBadRequest},
+		{"end before start", pid, tid, day(30), day(0), http.StatusBadRequest},
+		{"unknown property", 9999, tid, day(0), day(30), http.StatusBadRequest},
+		{"unknown tenant", pid, 9999, day(0), day(30), http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e.expect(e.createLease(token, c.propertyID, c.tenantID, c.start, c.end), c.status)
+		})
+	}
+}
+
+func TestCreateLeaseOverlap(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Busy Place", 1500)
+	other := e.createProperty(token, "Quiet Place", 1500)
+	t1 := e.createTenant(token, "first@example.com", "")
+	t2 := e.createTenant(token, "second@example.com", "")
+
+	rec := e.createLease(token, pid, t1, day(-10), day(100))
+	e.expect(rec, http.StatusCreated)
+	if l := decode[Lease](t, rec); l.Status != "active" || l.PaymentDueDay != 1 {
+		t.Fatalf("unexpected lease: %+v", l)
+	}
+
+	// An active lease takes the property off the market.
+	rec = e.do(http.MethodGet, fmt.Sprintf("/api/properties/%d", pid), "", nil)
+	if p := decode[Property](t, rec); p.Available {
+		t.Fatal("property should be unavailable once leased")
+	}
+
+	// Overlapping on either edge or fully contained is rejected.
+	e.expect(e.createLease(token, pid, t2, day(50), day(200)), http.StatusConflict)
+	e.expect(e.createLease(token, pid, t2, day(-100), day(-10)), http.StatusConflict)
+	e.expect(e.createLease(token, pid, t2, day(0), day(10)), http.StatusConflict)
+
+	// Back-to-back and other properties are fine.
+	rec = e.createLease(token, pid, t2, day(101), day(400))
+	e.expect(rec, http.StatusCreated)
+	if l := decode[Lease](t, rec); l.Status != "upcoming" {
+		t.Fatalf("future lease should be upcoming, got %q", l.Status)
+	}
+	e.expect(e.createLease(token, other, t2, day(50), day(200)), http.StatusCreated)
+}
+
+func TestLeaseUpdateAndDelete(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Lease Life", 1500)
+	t1 := e.createTenant(token, "life1@example.com", "")
+	t2 := e.createTenant(token, "life2@example.com", "")
+
+	first := decode[Lease](t, e.createLease(token, pid, t1, day(-10), day(100)))
+	second := decode[Lease](t, e.createLease(token, pid, t2, day(200), day(500)))
+
+	rec := e.do(http.MethodGet, "/api/admin/leases?status=upcoming", token, nil)
+	e.expect(rec, http.StatusOK)
+	if got := decode[[]Lease](t, rec); len(got) != 1 || got[0].ID != second.ID || got[0].TenantName == nil {
+		t.Fatalf("unexpected upcoming leases: %+v", got)
+	}
+
+	// Moving the second lease on top of the first conflicts.
+	second.StartDate = day(50)
+	e.expect(e.do(http.MethodPut, fmt.Sprintf("/api/admin/leases/%d", second.ID), token, second), http.StatusConflict)
+
+	// Updating the first lease against itself does not.
+	first.StartDate = day(-10)
+	first.EndDate = day(150)
+	e.expect(e.do(http.MethodPut, fmt.Sprintf("/api/admin/leases/%d", first.ID), token, first), http.StatusOK)
+
+	rec = e.do(http.MethodGet, fmt.Sprintf("/api/admin/leases/%d", first.ID), token, nil)
+	e.expect(rec, http.StatusOK)
+
+	// Deleting the active lease puts the property back on the market.
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/leases/%d", first.ID), token, nil), http.StatusNoContent)
+	rec = e.do(http.MethodGet, fmt.Sprintf("/api/properties/%d", pid), "", nil)
+	if p := decode[Property](t, rec); !p.Available {
+		t.Fatal("property should be available after its active lease is deleted")
+	}
+
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/leases/%d", first.ID), token, nil), http.StatusNotFound)
+	e.expect(e.do(http.MethodGet, "/api/admin/leases/9999", token, nil), http.StatusNotFound)
+}
+
+// ============================================================================
+// DELETION GUARDS
+// ============================================================================
+
+func TestDeletePropertyGuard(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	leased := e.createProperty(token, "Leased", 1500)
+	vacant := e.createProperty(token, "Vacant", 1500)
+	tid := e.createTenant(token, "guard@example.com", "")
+	e.expect(e.createLease(token, leased, tid, day(10), day(300)), http.StatusCreated)
+
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/properties/%d", leased), token, nil), http.StatusConflict)
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/properties/%d", vacant), token, nil), http.StatusNoContent)
+	// Deleting again is a no-op, unlike an ID that never existed.
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/properties/%d", vacant), token, nil), http.StatusNoContent)
+	e.expect(e.do(http.MethodDelete, "/api/admin/properties/9999", token, nil), http.StatusNotFound)
+}
+
+func TestDeleteTenantGuard(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Guarded", 1500)
+	leased := e.createTenant(token, "leased@example.com", "")
+	free := e.createTenant(token, "free@example.com", "")
+	e.expect(e.createLease(token, pid, leased, day(-5), day(300)), http.StatusCreated)
+
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/tenants/%d", leased), token, nil), http.StatusConflict)
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/tenants/%d", free), token, nil), http.StatusNoContent)
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/tenants/%d", free), token, nil), http.StatusNotFound)
+}
+
+// ============================================================================
+// PAYMENTS & MAINTENANCE REQUESTS
+// ============================================================================
+
+func TestAdminPaymentsCRUD(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Paying", 1500)
+	tid := e.createTenant(token, "payer@example.com", "")
+	lease := decode[Lease](t, e.createLease(token, pid, tid, day(-30), day(300)))
+
+	e.expect(e.do(http.MethodPost, "/api/admin/payments", token, Payment{LeaseID: lease.ID}), http.StatusBadRequest)
+	e.expect(e.do(http.MethodPost, "/api/admin/payments", token, Payment{LeaseID: 9999, Amount: 1, PaymentDate: day(0)}), http.StatusBadRequest)
+
+	rec := e.do(http.MethodPost, "/api/admin/payments", token, Payment{LeaseID: lease.ID, Amount: 1500, PaymentDate: day(0)})
+	e.expect(rec, http.StatusCreated)
+	pay := decode[Payment](t, rec)
+	if pay.TenantID != tid || pay.PropertyID != pid || pay.PaymentType != "rent" || pay.Status != "completed" {
+		t.Fatalf("payment defaults not applied: %+v", pay)
+	}
+
+	rec = e.do(http.MethodGet, fmt.Sprintf("/api/admin/payments?leaseId=%d&status=completed", lease.ID), token, nil)
+	e.expect(rec, http.StatusOK)
+	if got := decode[[]Payment](t, rec); len(got) != 1 {
+		t.Fatalf("expected 1 payment, got %d", len(got))
+	}
+
+	path := fmt.Sprintf("/api/admin/payments/%d", pay.ID)
+	pay.Amount = 1400
+	pay.Status = "refunded"
+	rec = e.do(http.MethodPut, path, token, pay)
+	e.expect(rec, http.StatusOK)
+	if got := decode[Payment](t, rec); got.Amount != 1400 || got.Status != "refunded" {
+		t.Fatalf("update not persisted: %+v", got)
+	}
+
+	e.expect(e.do(http.MethodDelete, path, token, nil), http.StatusNoContent)
+	e.expect(e.do(http.MethodGet, path, token, nil), http.StatusNotFound)
+	e.expect(e.do(http.MethodDelete, path, token, nil), http.StatusNotFound)
+}
+
+func TestTenantPortal(t *testing.T) {
+	e := newTestEnv(t)
+	admin := e.adminToken()
+	pid := e.createProperty(admin, "Portal Place", 1500)
+	tid := e.createTenant(admin, "portal@example.com", "portal-secret")
+	tenant := e.tenantToken("portal@example.com", "portal-secret")
+
+	// No lease yet: lease is null and requests are refused.
+	rec := e.do(http.MethodGet, "/api/tenant/lease", tenant, nil)
+	e.expect(rec, http.StatusOK)
+	if strings.TrimSpace(rec.Body.String()) != "null" {
+		t.Fatalf("expected null lease, got %s", rec.Body.String())
+	}
+	e.expect(e.do(http.MethodPost, "/api/tenant/requests", tenant, MaintenanceRequest{Title: "Leak", Description: "Drip"}), http.StatusBadRequest)
+
+	lease := decode[Lease](t, e.createLease(admin, pid, tid, day(-30), day(300)))
+	e.expect(e.do(http.MethodPost, "/api/admin/payments", admin, Payment{LeaseID: lease.ID, Amount: 1500, PaymentDate: day(-1)}), http.StatusCreated)
+
+	rec = e.do(http.MethodGet, "/api/tenant/lease", tenant, nil)
+	e.expect(rec, http.StatusOK)
+	if got := decode[Lease](t, rec); got.ID != lease.ID {
+		t.Fatalf("unexpected tenant lease: %+v", got)
+	}
+
+	rec = e.do(http.MethodGet, "/api/tenant/payments", tenant, nil)
+	e.expect(rec, http.StatusOK)
+	if got := decode[[]Payment](t, rec); len(got) != 1 || got[0].Amount != 1500 {
+		t.Fatalf("unexpected tenant payments: %+v", got)
+	}
+
+	e.expect(e.do(http.MethodPost, "/api/tenant/requests", tenant, MaintenanceRequest{Title: "Leak"}), http.StatusBadRequest)
+	rec = e.do(http.MethodPost, "/api/tenant/requests", tenant, MaintenanceRequest{Title: "Leak", Description: "Kitchen sink drips"})
+	e.expect(rec, http.StatusCreated)
+	req := decode[MaintenanceRequest](t, rec)
+	if req.PropertyID != pid || req.Status != "open" || req.Category != "other" || req.Priority != "medium" {
+		t.Fatalf("unexpected request defaults: %+v", req)
+	}
+
+	rec = e.do(http.MethodGet, "/api/tenant/requests", tenant, nil)
+	e.expect(rec, http.StatusOK)
+	if got := decode[[]MaintenanceRequest](t, rec); len(got) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(got))
+	}
+
+	// Admin triage round-trips through the admin request routes.
+	rec = e.do(http.MethodGet, "/api/admin/requests?status=open", admin, nil)
+	e.expect(rec, http.StatusOK)
+	if got := decode[[]MaintenanceRequest](t, rec); len(got) != 1 || got[0].TenantName == nil {
+		t.Fatalf("unexpected admin requests: %+v", got)
+	}
+	notes := "Plumber booked"
+	rec = e.do(http.MethodPut, fmt.Sprintf("/api/admin/requests/%d", req.ID), admin, map[string]interface{}{
+		"status":     "in_progress",
+		"adminNotes": notes,
+	})
+	e.expect(rec, http.StatusOK)
+	if got := decode[MaintenanceRequest](t, rec); got.Status != "in_progress" || got.AdminNotes == nil || *got.AdminNotes != notes {
+		t.Fatalf("update not persisted: %+v", got)
+	}
+	e.expect(e.do(http.MethodGet, "/api/admin/requests/9999", admin, nil), http.StatusNotFound)
+	e.expect(e.do(http.MethodPut, "/api/admin/requests/9999", admin, map[string]string{"status": "closed"}), http.StatusNotFound)
+
+	rec = e.do(http.MethodGet, fmt.Sprintf("/api/tenant/requests/%d", req.ID), tenant, nil)
+	e.expect(rec, http.StatusOK)
+	if got := decode[MaintenanceRequest](t, rec); got.Status != "in_progress" {
+		t.Fatalf("tenant should see admin status, got %q", got.Status)
+	}
+}
+
+func TestTenantRequestIsolation(t *testing.T) {
+	e := newTestEnv(t)
+	admin := e.adminToken()
+	p1 := e.createProperty(admin, "Alice Home", 1500)
+	p2 := e.createProperty(admin, "Bob Home", 1500)
+	alice := e.createTenant(admin, "alice@example.com", "alice-secret")
+	bob := e.createTenant(admin, "bob@example.com", "bob-secret")
+	e.expect(e.createLease(admin, p1, alice, day(-10), day(300)), http.StatusCreated)
+	e.expect(e.createLease(admin, p2, bob, day(-10), day(300)), http.StatusCreated)
+
+	aliceToken := e.tenantToken("alice@example.com", "alice-secret")
+	bobToken := e.tenantToken("bob@example.com", "bob-secret")
+
+	rec := e.do(http.MethodPost, "/api/tenant/requests", aliceToken, MaintenanceRequest{Title: "Heat", Description: "No heat"})
+	e.expect(rec, http.StatusCreated)
+	aliceReq := decode[MaintenanceRequest](t, rec)
+	path := fmt.Sprintf("/api/tenant/requests/%d", aliceReq.ID)
+
+	e.expect(e.do(http.MethodGet, path, aliceToken, nil), http.StatusOK)
+	e.expect(e.do(http.MethodGet, path, bobToken, nil), http.StatusNotFound)
+
+	rec = e.do(http.MethodGet, "/api/tenant/requests", bobToken, nil)
+	e.expect(rec, http.StatusOK)
+	if got := decode[[]MaintenanceRequest](t, rec); len(got) != 0 {
+		t.Fatalf("bob should not see alice's requests: %+v", got)
+	}
+
+	e.expect(e.do(http.MethodGet, "/api/tenant/requests/abc", aliceToken, nil), http.StatusBadRequest)
+	e.expect(e.do(http.MethodPut, path, aliceToken, nil), http.StatusMethodNotAllowed)
+}
+
+// ============================================================================
+// BACKGROUND JOBS
+// ============================================================================
+
+func TestUpdateLeaseStatuses(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Status Place", 1500)
+	tid := e.createTenant(token, "status@example.com", "")
+
+	lease := decode[Lease](t, e.createLease(token, pid, tid, day(1), day(60)))
+	if lease.Status != "upcoming" {
+		t.Fatalf("expected upcoming lease, got %q", lease.Status)
+	}
+
+	// Simulate time passing by moving the lease into the past.
+	db.Exec("UPDATE leases SET start_date = ?, end_date = ? WHERE id = ?", day(-5), day(60), lease.ID)
+	updateLeaseStatuses(context.Background())
+	var status string
+	db.QueryRow("SELECT status FROM leases WHERE id = ?", lease.ID).Scan(&status)
+	if status != "active" {
+		t.Fatalf("expected active, got %q", status)
+	}
+
+	db.Exec("UPDATE leases SET start_date = ?, end_date = ? WHERE id = ?", day(-60), day(-1), lease.ID)
+	updateLeaseStatuses(context.Background())
+	db.QueryRow("SELECT status FROM leases WHERE id = ?", lease.ID).Scan(&status)
+	if status != "ended" {
+		t.Fatalf("expected ended, got %q", status)
+	}
+}
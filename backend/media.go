@@ -0,0 +1,585 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ============================================================================
+// MEDIA STORAGE
+// ============================================================================
+
+// MediaStore holds uploaded files by key (a slash-separated relative path).
+// The local disk store is the only implementation; an object store would
+// slot in here.
+type MediaStore interface {
+	Save(key string, data []byte) error
+	Delete(key string) error
+	URL(key string) string
+}
+
+// mediaStore is set up from cfg.Media at startup.
+var mediaStore MediaStore = localMediaStore{dir: "uploads", baseURL: "/media"}
+
+// receiptStore keeps uploads that must not be public, like expense
+// receipts. It is set up from cfg.Media.PrivateDir at startup, and its
+// files are only served through authenticated API routes.
+var receiptStore = localMediaStore{dir: "private"}
+
+type localMediaStore struct {
+	dir     string
+	baseURL string
+}
+
+func newLocalMediaStore(c MediaConfig) localMediaStore {
+	return localMediaStore{dir: c.Dir, baseURL: strings.TrimSuffix(c.BaseURL, "/")}
+}
+
+func (s localMediaStore) path(key string) (string, error) {
+	clean := path.Clean("/" + key)[1:]
+	if clean == "" || clean != key {
+		return "", fmt.Errorf("invalid media key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
+
+func (s localMediaStore) Save(key string, data []byte) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	// Write then rename so a reader never sees a half-written file.
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+func (s localMediaStore) Delete(key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s localMediaStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+func (s localMediaStore) Open(key string) (*os.File, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+// mediaFileHandler serves the local media directory without directory
+// listings. Receipts used to be stored here too, so anything left under
+// receipts/ is refused.
+func mediaFileHandler() http.Handler {
+	files := http.StripPrefix("/media/", http.FileServer(http.Dir(cfg.Media.Dir)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") || strings.HasPrefix(path.Clean(r.URL.Path), "/media/receipts/") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		files.ServeHTTP(w, r)
+	})
+}
+
+// ============================================================================
+// MODELS - PROPERTY IMAGES
+// ============================================================================
+
+type PropertyImage struct {
+	ID         int               `json:"id"`
+	PropertyID int               `json:"propertyId"`
+	Caption    *string           `json:"caption,omitempty"`
+	SortOrder  int               `json:"sortOrder"`
+	IsCover    bool              `json:"isCover"`
+	Width      int               `json:"width"`
+	Height     int               `json:"height"`
+	URLs       PropertyImageURLs `json:"urls"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	storageKey string
+	ext        string
+}
+
+// PropertyImageURLs point at each stored variant.
+type PropertyImageURLs struct {
+	Original string `json:"original"`
+	Card     string `json:"card"`
+	Detail   string `json:"detail"`
+}
+
+// imageVariants are the resized copies generated on upload. Neither is ever
+// upscaled past the original.
+var imageVariants = []struct {
+	name     string
+	maxWidth int
+}{
+	{"card", 480},
+	{"detail", 1600},
+}
+
+var allowedImageTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+func (img *PropertyImage) fillURLs() {
+	img.URLs = PropertyImageURLs{
+		Original: mediaStore.URL(img.storageKey + "-original." + img.ext),
+		Card:     mediaStore.URL(img.storageKey + "-card.jpg"),
+		Detail:   mediaStore.URL(img.storageKey + "-detail.jpg"),
+	}
+}
+
+func (img *PropertyImage) keys() []string {
+	keys := []string{img.storageKey + "-original." + img.ext}
+	for _, v := range imageVariants {
+		keys = append(keys, img.storageKey+"-"+v.name+".jpg")
+	}
+	return keys
+}
+
+// ============================================================================
+// HANDLERS - ADMIN PROPERTY IMAGES
+// ============================================================================
+
+// propertyImagesHandler serves /api/admin/properties/:id/images[/...]; rest
+// is whatever follows "images".
+func propertyImagesHandler(w http.ResponseWriter, r *http.Request, propertyID int, rest string) {
+	var exists int
+	db.QueryRow("SELECT COUNT(*) FROM properties WHERE id = ?", propertyID).Scan(&exists)
+	if exists == 0 {
+		jsonError(w, "Property not found", http.StatusNotFound)
+		return
+	}
+
+	switch rest {
+	case "":
+		switch r.Method {
+		case http.MethodGet:
+			listPropertyImages(w, propertyID)
+		case http.MethodPost:
+			uploadPropertyImage(w, r, propertyID)
+		default:
+			jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	case "order":
+		if r.Method != http.MethodPut {
+			jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		reorderPropertyImages(w, r, propertyID)
+		return
+	}
+
+	imageID, err := strconv.Atoi(rest)
+	if err != nil {
+		jsonError(w, "Invalid image ID", http.StatusBadRequest)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		updatePropertyImage(w, r, propertyID, imageID)
+	case http.MethodDelete:
+		deletePropertyImage(w, propertyID, imageID)
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func listPropertyImages(w http.ResponseWriter, propertyID int) {
+	images, err := loadPropertyImages(propertyID)
+	if err != nil {
+		log.Printf("Error loading property images: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, images, http.StatusOK)
+}
+
+// uploadPropertyImage accepts multipart/form-data with a "file" part and
+// optional "caption" and "cover" fields. The first image becomes the cover.
+func uploadPropertyImage(w http.ResponseWriter, r *http.Request, propertyID int) {
+	// limitRequestBody has already capped the body for upload routes.
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			jsonError(w, "Upload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		jsonError(w, "Expected multipart/form-data with a file field", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		jsonError(w, "A file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	if header.Size > cfg.Media.MaxUploadBytes {
+		jsonError(w, "Upload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		jsonError(w, "Failed to read upload", http.StatusBadRequest)
+		return
+	}
+
+	// Trust the bytes, not the filename or client-declared type.
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedImageTypes[contentType]
+	if !ok {
+		jsonError(w, "Images must be JPEG, PNG or WebP", http.StatusBadRequest)
+		return
+	}
+	// Check the declared size before decoding: a small file can claim
+	// dimensions whose pixels would take gigabytes to hold.
+	conf, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		jsonError(w, "Could not decode image", http.StatusBadRequest)
+		return
+	}
+	if int64(conf.Width)*int64(conf.Height) > cfg.Media.MaxImagePixels {
+		jsonError(w, fmt.Sprintf("Images may be at most %d pixels", cfg.Media.MaxImagePixels), http.StatusBadRequest)
+		return
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		jsonError(w, "Could not decode image", http.StatusBadRequest)
+		return
+	}
+
+	img := PropertyImage{
+		PropertyID: propertyID,
+		Width:      src.Bounds().Dx(),
+		Height:     src.Bounds().Dy(),
+		storageKey: fmt.Sprintf("properties/%d/%s", propertyID, generateToken()[:20]),
+		ext:        ext,
+	}
+	if caption := strings.TrimSpace(r.FormValue("caption")); caption != "" {
+		img.Caption = &caption
+	}
+
+	files := map[string][]byte{img.storageKey + "-original." + ext: data}
+	for _, v := range imageVariants {
+		resized, err := encodeVariant(src, v.maxWidth)
+		if err != nil {
+			log.Printf("Error resizing image: %v", err)
+			jsonError(w, "Failed to process image", http.StatusInternalServerError)
+			return
+		}
+		files[img.storageKey+"-"+v.name+".jpg"] = resized
+	}
+	for key, b := range files {
+		if err := mediaStore.Save(key, b); err != nil {
+			log.Printf("Error saving media %s: %v", key, err)
+			removeMedia(img.keys())
+			jsonError(w, "Failed to store image", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var count, maxOrder int
+	db.QueryRow("SELECT COUNT(*), COALESCE(MAX(sort_order), -1) FROM property_images WHERE property_id = ?", propertyID).Scan(&count, &maxOrder)
+	img.SortOrder = maxOrder + 1
+	img.IsCover = count == 0 || r.FormValue("cover") == "true"
+
+	tx, err := db.Begin()
+	if err != nil {
+		removeMedia(img.keys())
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if img.IsCover {
+		tx.Exec("UPDATE property_images SET is_cover = FALSE WHERE property_id = ?", propertyID)
+	}
+	result, err := tx.Exec(`
+		INSERT INTO property_images (property_id, storage_key, original_ext, content_type,
+			width, height, caption, sort_order, is_cover)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, propertyID, img.storageKey, ext, contentType, img.Width, img.Height, img.Caption, img.SortOrder, img.IsCover)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("Error saving property image: %v", err)
+		removeMedia(img.keys())
+		jsonError(w, "Failed to save image", http.StatusInternalServerError)
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	img.ID = int(id)
+	img.CreatedAt = time.Now()
+	img.fillURLs()
+	syncCoverImage(propertyID)
+
+	jsonResponse(w, img, http.StatusCreated)
+}
+
+func updatePropertyImage(w http.ResponseWriter, r *http.Request, propertyID, imageID int) {
+	var body struct {
+		Caption *string `json:"caption"`
+		IsCover bool    `json:"isCover"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	var isCover bool
+	err := db.QueryRow("SELECT is_cover FROM property_images WHERE id = ? AND property_id = ?", imageID, propertyID).Scan(&isCover)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Image not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	// A property with photos always has a cover; pick another to move it.
+	if isCover && !body.IsCover {
+		jsonError(w, "Choose a different cover image instead of unsetting this one", http.StatusConflict)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if body.IsCover {
+		tx.Exec("UPDATE property_images SET is_cover = FALSE WHERE property_id = ?", propertyID)
+	}
+	_, err = tx.Exec("UPDATE property_images SET caption = ?, is_cover = ? WHERE id = ?", body.Caption, body.IsCover, imageID)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("Error updating property image: %v", err)
+		jsonError(w, "Failed to update image", http.StatusInternalServerError)
+		return
+	}
+	syncCoverImage(propertyID)
+
+	listPropertyImages(w, propertyID)
+}
+
+// reorderPropertyImages takes {"imageIds": [...]} listing every image of the
+// property in the new order.
+func reorderPropertyImages(w http.ResponseWriter, r *http.Request, propertyID int) {
+	var body struct {
+		ImageIDs []int `json:"imageIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	images, err := loadPropertyImages(propertyID)
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	current := make(map[int]bool, len(images))
+	for _, img := range images {
+		current[img.ID] = true
+	}
+	seen := make(map[int]bool, len(body.ImageIDs))
+	for _, id := range body.ImageIDs {
+		if !current[id] || seen[id] {
+			jsonError(w, "imageIds must list each of the property's images exactly once", http.StatusBadRequest)
+			return
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(current) {
+		jsonError(w, "imageIds must list each of the property's images exactly once", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+	for i, id := range body.ImageIDs {
+		if _, err := tx.Exec("UPDATE property_images SET sort_order = ? WHERE id = ?", i, id); err != nil {
+			log.Printf("Error reordering images: %v", err)
+			jsonError(w, "Failed to reorder images", http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		jsonError(w, "Failed to reorder images", http.StatusInternalServerError)
+		return
+	}
+
+	listPropertyImages(w, propertyID)
+}
+
+func deletePropertyImage(w http.ResponseWriter, propertyID, imageID int) {
+	img := PropertyImage{ID: imageID}
+	var isCover bool
+	err := db.QueryRow(`
+		SELECT storage_key, original_ext, is_cover FROM property_images WHERE id = ? AND property_id = ?
+	`, imageID, propertyID).Scan(&img.storageKey, &img.ext, &isCover)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Image not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := db.Exec("DELETE FROM property_images WHERE id = ?", imageID); err != nil {
+		log.Printf("Error deleting property image: %v", err)
+		jsonError(w, "Failed to delete image", http.StatusInternalServerError)
+		return
+	}
+	removeMedia(img.keys())
+
+	// Promote the first remaining image so the gallery keeps a cover.
+	if isCover {
+		db.Exec(`
+			UPDATE property_images SET is_cover = TRUE
+			WHERE id = (SELECT id FROM (
+				SELECT id FROM property_images WHERE property_id = ? ORDER BY sort_order, id LIMIT 1
+			) AS first_image)
+		`, propertyID)
+	}
+	syncCoverImage(propertyID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ============================================================================
+// MEDIA HELPERS
+// ============================================================================
+
+// encodeVariant scales src down to maxWidth (keeping aspect ratio) and
+// encodes it as JPEG.
+func encodeVariant(src image.Image, maxWidth int) ([]byte, error) {
+	b := src.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width > maxWidth {
+		height = height * maxWidth / width
+		width = maxWidth
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	// JPEG has no alpha; flatten transparent areas onto white rather than
+	// letting them come out black.
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 82}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func removeMedia(keys []string) {
+	for _, key := range keys {
+		if err := mediaStore.Delete(key); err != nil {
+			log.Printf("Error deleting media %s: %v", key, err)
+		}
+	}
+}
+
+// syncCoverImage points properties.image_url at the cover's card variant so
+// listing cards keep working off the single image field. It is cleared when
+// the last uploaded photo goes, unless it was set to an outside URL.
+func syncCoverImage(propertyID int) {
+	img := PropertyImage{}
+	err := db.QueryRow(`
+		SELECT storage_key, original_ext FROM property_images WHERE property_id = ? AND is_cover = TRUE
+	`, propertyID).Scan(&img.storageKey, &img.ext)
+	if err == sql.ErrNoRows {
+		db.Exec("UPDATE properties SET image_url = NULL WHERE id = ? AND image_url LIKE ?",
+			propertyID, mediaStore.URL(fmt.Sprintf("properties/%d/", propertyID))+"%")
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading cover image: %v", err)
+		return
+	}
+	img.fillURLs()
+	db.Exec("UPDATE properties SET image_url = ? WHERE id = ?", img.URLs.Card, propertyID)
+}
+
+// loadPropertyImages returns a property's gallery in display order.
+func loadPropertyImages(propertyID int) ([]PropertyImage, error) {
+	rows, err := db.Query(`
+		SELECT id, property_id, storage_key, original_ext, width, height, caption,
+			   sort_order, is_cover, created_at
+		FROM property_images WHERE property_id = ?
+		ORDER BY sort_order, id
+	`, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := []PropertyImage{}
+	for rows.Next() {
+		var img PropertyImage
+		var caption sql.NullString
+		err := rows.Scan(&img.ID, &img.PropertyID, &img.storageKey, &img.ext, &img.Width, &img.Height,
+			&caption, &img.SortOrder, &img.IsCover, &img.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		img.Caption = nullStringPtr(caption)
+		img.fillURLs()
+		images = append(images, img)
+	}
+	return images, rows.Err()
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, height/2, color.RGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// uploadImage posts data as the "file" part plus any extra form fields.
+func (e *testEnv) uploadImage(token string, propertyID int, data []byte, fields map[string]string) *httptest.ResponseRecorder {
+	e.t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	part, _ := mw.CreateFormFile("file", "photo.png")
+	part.Write(data)
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/admin/properties/%d/images", propertyID), &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func mediaPath(url string) string {
+	return filepath.Join(cfg.Media.Dir, filepath.FromSlash(strings.TrimPrefix(url, "/media/")))
+}
+
+func TestUploadPropertyImageCreatesVariants(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Photo House", 1500)
+
+	rec := e.uploadImage(token, pid, testPNG(t, 2000, 1000), map[string]string{"caption": "Front"})
+	e.expect(rec, http.StatusCreated)
+	img := decode[PropertyImage](t, rec)
+	if !img.IsCover || img.Width != 2000 || img.Height != 1000 {
+		t.Fatalf("unexpected image: %+v", img)
+	}
+	if img.Caption == nil || *img.Caption != "Front" {
+		t.Fatalf("expected caption Front, got %v", img.Caption)
+	}
+
+	for name, url := range map[string]string{"card": img.URLs.Card, "detail": img.URLs.Detail} {
+		f, err := os.Open(mediaPath(url))
+		if err != nil {
+			t.Fatalf("%s variant missing: %v", name, err)
+		}
+		conf, format, err := image.DecodeConfig(f)
+		f.Close()
+		if err != nil || format != "jpeg" {
+			t.Fatalf("%s variant: format %q, err %v", name, format, err)
+		}
+		// testPNG is transparent apart from one line; JPEG flattens it onto
+		// white.
+		f, _ = os.Open(mediaPath(url))
+		variant, _, err := image.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s variant: %v", name, err)
+		}
+		if r, g, b, _ := variant.At(0, 0).RGBA(); r < 0xf000 || g < 0xf000 || b < 0xf000 {
+			t.Errorf("%s variant background is %v, want white", name, variant.At(0, 0))
+		}
+		want := map[string]int{"card": 480, "detail": 1600}[name]
+		if conf.Width != want || conf.Height != want/2 {
+			t.Errorf("%s variant is %dx%d, want %dx%d", name, conf.Width, conf.Height, want, want/2)
+		}
+	}
+
+	// Served from the media route.
+	served := e.do(http.MethodGet, img.URLs.Original, "", nil)
+	e.expect(served, http.StatusOK)
+	if served.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("expected image/png, got %q", served.Header().Get("Content-Type"))
+	}
+
+	// The cover's card image feeds the listing image and the detail gallery.
+	p := decode[Property](t, e.do(http.MethodGet, fmt.Sprintf("/api/properties/%d", pid), "", nil))
+	if p.ImageURL == nil || *p.ImageURL != img.URLs.Card {
+		t.Errorf("expected imageUrl %q, got %v", img.URLs.Card, p.ImageURL)
+	}
+	if len(p.Images) != 1 || p.Images[0].ID != img.ID {
+		t.Errorf("expected gallery with image %d, got %+v", img.ID, p.Images)
+	}
+}
+
+func TestUploadPropertyImageRejectsBadFiles(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Photo House", 1500)
+
+	e.expect(e.uploadImage(token, pid, []byte("GIF89a not really"), nil), http.StatusBadRequest)
+	e.expect(e.uploadImage(token, pid, []byte("<html>hello</html>"), nil), http.StatusBadRequest)
+	e.expect(e.uploadImage(token, pid+1, testPNG(t, 10, 10), nil), http.StatusNotFound)
+
+	// A few hundred bytes claiming 50000x50000 pixels is refused from its
+	// header, before decoding would allocate 10 GB.
+	bomb := testPNG(t, 1, 1)
+	binary.BigEndian.PutUint32(bomb[16:], 50000)
+	binary.BigEndian.PutUint32(bomb[20:], 50000)
+	binary.BigEndian.PutUint32(bomb[29:], crc32.ChecksumIEEE(bomb[12:29]))
+	e.expect(e.uploadImage(token, pid, bomb, nil), http.StatusBadRequest)
+	cfg.Media.MaxImagePixels = 300 * 300
+	e.expect(e.uploadImage(token, pid, testPNG(t, 400, 400), nil), http.StatusBadRequest)
+
+	cfg.Media.MaxUploadBytes = 100
+	e.expect(e.uploadImage(token, pid, testPNG(t, 400, 400), nil), http.StatusRequestEntityTooLarge)
+
+	images := decode[[]PropertyImage](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/properties/%d/images", pid), token, nil))
+	if len(images) != 0 {
+		t.Fatalf("expected no images stored, got %d", len(images))
+	}
+}
+
+func TestPropertyImageOrderingAndCover(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Gallery House", 1500)
+	base := fmt.Sprintf("/api/admin/properties/%d/images", pid)
+
+	var ids []int
+	for i := 0; i < 3; i++ {
+		rec := e.uploadImage(token, pid, testPNG(t, 20, 10), nil)
+		e.expect(rec, http.StatusCreated)
+		ids = append(ids, decode[PropertyImage](t, rec).ID)
+	}
+
+	// Reorder requires every image exactly once.
+	e.expect(e.do(http.MethodPut, base+"/order", token, map[string]interface{}{"imageIds": []int{ids[2], ids[0]}}), http.StatusBadRequest)
+	e.expect(e.do(http.MethodPut, base+"/order", token, map[string]interface{}{"imageIds": []int{ids[2], ids[0], ids[0]}}), http.StatusBadRequest)
+	images := decode[[]PropertyImage](t, e.do(http.MethodPut, base+"/order", token, map[string]interface{}{"imageIds": []int{ids[2], ids[0], ids[1]}}))
+	if images[0].ID != ids[2] || images[1].ID != ids[0] || images[2].ID != ids[1] {
+		t.Fatalf("unexpected order: %+v", images)
+	}
+
+	// Moving the cover clears the old one; unsetting it outright is refused.
+	e.expect(e.do(http.MethodPut, fmt.Sprintf("%s/%d", base, ids[0]), token, map[string]interface{}{"isCover": false}), http.StatusConflict)
+	rec := e.do(http.MethodPut, fmt.Sprintf("%s/%d", base, ids[1]), token, map[string]interface{}{"caption": "Kitchen", "isCover": true})
+	e.expect(rec, http.StatusOK)
+	covers := 0
+	for _, img := range decode[[]PropertyImage](t, rec) {
+		if img.IsCover {
+			covers++
+			if img.ID != ids[1] {
+				t.Errorf("expected image %d as cover, got %d", ids[1], img.ID)
+			}
+		}
+	}
+	if covers != 1 {
+		t.Fatalf("expected one cover, got %d", covers)
+	}
+
+	// Deleting the cover promotes the first remaining image and removes files.
+	coverFile := mediaPath(images[2].URLs.Original)
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("%s/%d", base, ids[1]), token, nil), http.StatusNoContent)
+	if _, err := os.Stat(coverFile); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", coverFile, err)
+	}
+	images = decode[[]PropertyImage](t, e.do(http.MethodGet, base, token, nil))
+	if len(images) != 2 || !images[0].IsCover || images[0].ID != ids[2] {
+		t.Fatalf("expected image %d promoted to cover, got %+v", ids[2], images)
+	}
+
+	for _, img := range images {
+		e.expect(e.do(http.MethodDelete, fmt.Sprintf("%s/%d", base, img.ID), token, nil), http.StatusNoContent)
+	}
+	p := decode[Property](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/properties/%d", pid), token, nil))
+	if p.ImageURL != nil {
+		t.Errorf("expected imageUrl cleared, got %q", *p.ImageURL)
+	}
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// MIGRATIONS
+// ============================================================================
+
+// Migrations are embedded so the binary carries its own schema. Each version
+// is a pair of files: NNNN_name.up.sql and NNNN_name.down.sql.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const migrationsDir = "migrations"
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+type appliedMigration struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+type migrationState struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+	// ChecksumMismatch means the .up.sql file changed after it was applied.
+	ChecksumMismatch bool `json:"checksumMismatch,omitempty"`
+}
+
+// loadMigrations reads and pairs every migration file in fsys, sorted by version.
+func loadMigrations(fsys fs.FS) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*migration)
+	for _, entry := range entries {
+		m := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("unexpected file in %s: %s", migrationsDir, entry.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		content, err := fs.ReadFile(fsys, path.Join(migrationsDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(content)
+			sum := sha256.Sum256(content)
+			mig.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			mig.Down = string(content)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both .up.sql and .down.sql", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// splitStatements breaks a migration file into individual statements. The
+// MySQL driver runs one statement per Exec unless multiStatements is enabled,
+// which we avoid. Statements must end with a semicolon at the end of a line.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+func ensureMigrationsTable(conn *sql.DB) error {
+	_, err := conn.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum CHAR(64) NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	return err
+}
+
+func loadAppliedMigrations(ctx context.Context, conn *sql.DB) (map[int]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[a.Version] = a
+	}
+	return applied, rows.Err()
+}
+
+// migrationStatus lists every known migration with its applied state,
+// creating schema_migrations first on a fresh database.
+func migrationStatus(conn *sql.DB, fsys fs.FS) ([]migrationState, error) {
+	if err := ensureMigrationsTable(conn); err != nil {
+		return nil, err
+	}
+	return appliedStates(context.Background(), conn, fsys)
+}
+
+// appliedStates is migrationStatus without the table setup, so it only
+// reads.
+func appliedStates(ctx context.Context, conn *sql.DB, fsys fs.FS) ([]migrationState, error) {
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := loadAppliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]migrationState, 0, len(migrations))
+	known := make(map[int]bool)
+	for _, m := range migrations {
+		known[m.Version] = true
+		state := migrationState{Version: m.Version, Name: m.Name}
+		if a, ok := applied[m.Version]; ok {
+			appliedAt := a.AppliedAt
+			state.Applied = true
+			state.AppliedAt = &appliedAt
+			state.ChecksumMismatch = a.Checksum != m.Checksum
+		}
+		states = append(states, state)
+	}
+	for version, a := range applied {
+		if !known[version] {
+			return nil, fmt.Errorf("database has migration %04d_%s which this binary does not know about", version, a.Name)
+		}
+	}
+	return states, nil
+}
+
+// checkMigrations returns an error unless every migration is applied and
+// unchanged. The server refuses to start when this fails.
+func checkMigrations(conn *sql.DB, fsys fs.FS) error {
+	states, err := migrationStatus(conn, fsys)
+	if err != nil {
+		return err
+	}
+	return schemaError(states)
+}
+
+// checkMigrationsReadOnly is checkMigrations without creating
+// schema_migrations, for the readiness probe. ctx bounds the query so a slow
+// database cannot hold the probe past its deadline.
+func checkMigrationsReadOnly(ctx context.Context, conn *sql.DB, fsys fs.FS) error {
+	states, err := appliedStates(ctx, conn, fsys)
+	if err != nil {
+		return err
+	}
+	return schemaError(states)
+}
+
+func schemaError(states []migrationState) error {
+	var pending []string
+	for _, s := range states {
+		if s.ChecksumMismatch {
+			return fmt.Errorf("migration %04d_%s was modified after it was applied", s.Version, s.Name)
+		}
+		if !s.Applied {
+			pending = append(pending, fmt.Sprintf("%04d_%s", s.Version, s.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d pending migration(s): %s", len(pending), strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// legacySchemaVersion reports which migrations a database created by piping
+// the old 001_create_tables.sql and 003_tenant_portal.sql files into mysql
+// already has: 2 with the tenant portal, 1 without, 0 when the properties
+// table does not exist (a fresh database).
+func legacySchemaVersion(conn *sql.DB) int {
+	if _, err := conn.Exec("SELECT 1 FROM properties LIMIT 0"); err != nil {
+		return 0
+	}
+	if _, err := conn.Exec("SELECT password_hash FROM tenants LIMIT 0"); err != nil {
+		return 1
+	}
+	return 2
+}
+
+// migrateUp applies every pending migration in order and returns the ones
+// applied. A database that has tables but no recorded migrations predates
+// the runner; the migrations its schema already has are baselined first
+// rather than replayed on top of it.
+func migrateUp(conn *sql.DB, fsys fs.FS) ([]migration, error) {
+	states, err := migrationStatus(conn, fsys)
+	if err != nil {
+		return nil, err
+	}
+	if noneApplied(states) {
+		if version := legacySchemaVersion(conn); version > 0 {
+			baselined, err := migrateBaseline(conn, fsys, version)
+			for _, m := range baselined {
+				log.Printf("Existing schema predates schema_migrations; marked %04d_%s as applied", m.Version, m.Name)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if states, err = migrationStatus(conn, fsys); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, s := range states {
+		if s.ChecksumMismatch {
+			return nil, fmt.Errorf("migration %04d_%s was modified after it was applied", s.Version, s.Name)
+		}
+	}
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []migration
+	for i, m := range migrations {
+		if states[i].Applied {
+			continue
+		}
+		// MySQL commits DDL implicitly, so a failed migration can leave
+		// earlier statements applied. Each statement is reported on failure
+		// so it can be fixed up by hand.
+		for _, stmt := range splitStatements(m.Up) {
+			if _, err := conn.Exec(stmt); err != nil {
+				return done, fmt.Errorf("migration %04d_%s failed: %w\n%s", m.Version, m.Name, err, stmt)
+			}
+		}
+		if _, err := conn.Exec("INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)",
+			m.Version, m.Name, m.Checksum); err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+func noneApplied(states []migrationState) bool {
+	for _, s := range states {
+		if s.Applied {
+			return false
+		}
+	}
+	return true
+}
+
+// migrateDown rolls back the most recent steps applied migrations.
+func migrateDown(conn *sql.DB, fsys fs.FS, steps int) ([]migration, error) {
+	states, err := migrationStatus(conn, fsys)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []migration
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		if !states[i].Applied {
+			continue
+		}
+		m := migrations[i]
+		for _, stmt := range splitStatements(m.Down) {
+			if _, err := conn.Exec(stmt); err != nil {
+				return done, fmt.Errorf("rollback of %04d_%s failed: %w\n%s", m.Version, m.Name, err, stmt)
+			}
+		}
+		if _, err := conn.Exec("DELETE FROM schema_migrations WHERE version = ?", m.Version); err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// migrateBaseline records migrations up to version as applied without running
+// them, for databases that were created by piping the old SQL files into mysql.
+func migrateBaseline(conn *sql.DB, fsys fs.FS, version int) ([]migration, error) {
+	states, err := migrationStatus(conn, fsys)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []migration
+	for i, m := range migrations {
+		if m.Version > version || states[i].Applied {
+			continue
+		}
+		if _, err := conn.Exec("INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)",
+			m.Version, m.Name, m.Checksum); err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// runMigrateCommand implements `server migrate up|down [n]|status|baseline <version>`.
+func runMigrateCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up | down [steps] | status | baseline <version>")
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := migrateUp(db, migrationFiles)
+		for _, m := range done {
+			fmt.Printf("applied  %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("database is up to date")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+			steps = n
+		}
+		done, err := migrateDown(db, migrationFiles, steps)
+		for _, m := range done {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("nothing to roll back")
+		}
+		return err
+
+	case "status":
+		states, err := migrationStatus(db, migrationFiles)
+		if err != nil {
+			return err
+		}
+		for _, s := range states {
+			status := "pending"
+			if s.Applied {
+				status = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.ChecksumMismatch {
+				status += " (CHECKSUM MISMATCH)"
+			}
+			fmt.Fprintf(os.Stdout, "%04d_%-30s %s\n", s.Version, s.Name, status)
+		}
+		return nil
+
+	case "baseline":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: migrate baseline <version>")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		done, err := migrateBaseline(db, migrationFiles, version)
+		for _, m := range done {
+			fmt.Printf("marked   %04d_%s as applied\n", m.Version, m.Name)
+		}
+		return err
+
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func openMigrationTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	conn, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func testMigrationFS() fstest.MapFS {
+	return fstest.MapFS{
+		"migrations/0001_widgets.up.sql": {Data: []byte(`
+-- Widgets
+CREATE TABLE widgets (
+    id INTEGER PRIMARY KEY
+);
+CREATE TABLE gadgets (id INTEGER PRIMARY KEY);
+`)},
+		"migrations/0001_widgets.down.sql":   {Data: []byte("DROP TABLE gadgets;\nDROP TABLE widgets;\n")},
+		"migrations/0002_sprockets.up.sql":   {Data: []byte("CREATE TABLE sprockets (id INTEGER PRIMARY KEY);\n")},
+		"migrations/0002_sprockets.down.sql": {Data: []byte("DROP TABLE sprockets;\n")},
+	}
+}
+
+func tableExists(t *testing.T, conn *sql.DB, name string) bool {
+	t.Helper()
+	var n int
+	conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&n)
+	return n > 0
+}
+
+func TestEmbeddedMigrationsWellFormed(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		t.Fatalf("load embedded migrations: %v", err)
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Fatalf("migration versions must be contiguous from 1; got %d at position %d", m.Version, i)
+		}
+		if len(splitStatements(m.Up)) == 0 || len(splitStatements(m.Down)) == 0 {
+			t.Fatalf("migration %04d_%s has an empty up or down script", m.Version, m.Name)
+		}
+		if strings.Contains(strings.ToUpper(m.Up), "DROP TABLE") {
+			t.Fatalf("migration %04d_%s drops a table in its up script", m.Version, m.Name)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	got := splitStatements("-- comment\nCREATE TABLE a (\n  id INT\n);\n\nINSERT INTO a VALUES (1);\nSELECT 1")
+	if len(got) != 3 {
+		t.Fatalf("expected 3 statements, got %d: %q", len(got), got)
+	}
+	if !strings.HasPrefix(got[0], "CREATE TABLE a") || !strings.HasSuffix(got[0], ");") {
+		t.Fatalf("unexpected first statement %q", got[0])
+	}
+}
+
+func TestMigrateUpDownStatus(t *testing.T) {
+	conn := openMigrationTestDB(t)
+	fsys := testMigrationFS()
+
+	if err := checkMigrations(conn, fsys); err == nil || !strings.Contains(err.Error(), "2 pending") {
+		t.Fatalf("expected 2 pending migrations, got %v", err)
+	}
+
+	done, err := migrateUp(conn, fsys)
+	if err != nil || len(done) != 2 {
+		t.Fatalf("migrate up: applied %d, err %v", len(done), err)
+	}
+	for _, table := range []string{"widgets", "gadgets", "sprockets"} {
+		if !tableExists(t, conn, table) {
+			t.Fatalf("table %s missing after migrate up", table)
+		}
+	}
+	if err := checkMigrations(conn, fsys); err != nil {
+		t.Fatalf("expected current schema, got %v", err)
+	}
+
+	// Running up again is a no-op.
+	if done, err := migrateUp(conn, fsys); err != nil || len(done) != 0 {
+		t.Fatalf("second migrate up: applied %d, err %v", len(done), err)
+	}
+
+	done, err = migrateDown(conn, fsys, 1)
+	if err != nil || len(done) != 1 || done[0].Version != 2 {
+		t.Fatalf("migrate down: %+v, err %v", done, err)
+	}
+	if tableExists(t, conn, "sprockets") || !tableExists(t, conn, "widgets") {
+		t.Fatal("migrate down should only revert the latest migration")
+	}
+
+	states, err := migrationStatus(conn, fsys)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !states[0].Applied || states[1].Applied {
+		t.Fatalf("unexpected status: %+v", states)
+	}
+}
+
+func TestMigrationChecksumMismatch(t *testing.T) {
+	conn := openMigrationTestDB(t)
+	fsys := testMigrationFS()
+	if _, err := migrateUp(conn, fsys); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+
+	fsys["migrations/0001_widgets.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT);\n")}
+
+	if err := checkMigrations(conn, fsys); err == nil || !strings.Contains(err.Error(), "modified") {
+		t.Fatalf("expected checksum error, got %v", err)
+	}
+	if _, err := migrateUp(conn, fsys); err == nil {
+		t.Fatal("migrate up should refuse to run with a modified migration")
+	}
+}
+
+func TestMigrationUnknownVersion(t *testing.T) {
+	conn := openMigrationTestDB(t)
+	fsys := testMigrationFS()
+	if _, err := migrateUp(conn, fsys); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+
+	delete(fsys, "migrations/0002_sprockets.up.sql")
+	delete(fsys, "migrations/0002_sprockets.down.sql")
+
+	if err := checkMigrations(conn, fsys); err == nil || !strings.Contains(err.Error(), "does not know about") {
+		t.Fatalf("expected unknown version error, got %v", err)
+	}
+}
+
+// The readiness probe's deadline applies to the migrations query too.
+func TestCheckMigrationsReadOnlyHonorsContext(t *testing.T) {
+	conn := openMigrationTestDB(t)
+	fsys := testMigrationFS()
+	if _, err := migrateUp(conn, fsys); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkMigrationsReadOnly(context.Background(), conn, fsys); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := checkMigrationsReadOnly(ctx, conn, fsys); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestMigrateBaseline(t *testing.T) {
+	conn := openMigrationTestDB(t)
+	fsys := testMigrationFS()
+
+	// Simulate a database created by hand from the first migration.
+	conn.Exec("CREATE TABLE widgets (id INTEGER PRIMARY KEY)")
+	conn.Exec("CREATE TABLE gadgets (id INTEGER PRIMARY KEY)")
+
+	done, err := migrateBaseline(conn, fsys, 1)
+	if err != nil || len(done) != 1 {
+		t.Fatalf("baseline: %+v, err %v", done, err)
+	}
+	done, err = migrateUp(conn, fsys)
+	if err != nil || len(done) != 1 || done[0].Version != 2 {
+		t.Fatalf("migrate up after baseline: %+v, err %v", done, err)
+	}
+}
+
+// A database built from the old SQL files has tables but no
+// schema_migrations; migrate up baselines what it already has instead of
+// replaying 0001 and failing on 0002's duplicate column.
+func TestMigrateUpBaselinesLegacySchema(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_create_tables.up.sql":   {Data: []byte("CREATE TABLE properties (id INTEGER PRIMARY KEY);\nCREATE TABLE tenants (id INTEGER PRIMARY KEY);\n")},
+		"migrations/0001_create_tables.down.sql": {Data: []byte("DROP TABLE tenants;\nDROP TABLE properties;\n")},
+		"migrations/0002_tenant_portal.up.sql":   {Data: []byte("ALTER TABLE tenants ADD COLUMN password_hash TEXT;\n")},
+		"migrations/0002_tenant_portal.down.sql": {Data: []byte("ALTER TABLE tenants DROP COLUMN password_hash;\n")},
+		"migrations/0003_sprockets.up.sql":       {Data: []byte("CREATE TABLE sprockets (id INTEGER PRIMARY KEY);\n")},
+		"migrations/0003_sprockets.down.sql":     {Data: []byte("DROP TABLE sprockets;\n")},
+	}
+	for _, c := range []struct {
+		name    string
+		legacy  []string
+		applied []int
+	}{
+		{"tenant portal", []string{"CREATE TABLE properties (id INTEGER PRIMARY KEY)", "CREATE TABLE tenants (id INTEGER PRIMARY KEY, password_hash TEXT)"}, []int{3}},
+		{"core tables only", []string{"CREATE TABLE properties (id INTEGER PRIMARY KEY)", "CREATE TABLE tenants (id INTEGER PRIMARY KEY)"}, []int{2, 3}},
+		{"fresh database", nil, []int{1, 2, 3}},
+	} {
+		conn := openMigrationTestDB(t)
+		for _, stmt := range c.legacy {
+			if _, err := conn.Exec(stmt); err != nil {
+				t.Fatal(err)
+			}
+		}
+		done, err := migrateUp(conn, fsys)
+		if err != nil {
+			t.Fatalf("%s: migrate up: %v", c.name, err)
+		}
+		var versions []int
+		for _, m := range done {
+			versions = append(versions, m.Version)
+		}
+		if fmt.Sprint(versions) != fmt.Sprint(c.applied) {
+			t.Errorf("%s: applied %v, want %v", c.name, versions, c.applied)
+		}
+		if err := checkMigrations(conn, fsys); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		}
+	}
+}
+
+func TestLoadMigrationsRejectsUnpaired(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_lonely.up.sql": {Data: []byte("SELECT 1;\n")},
+	}
+	if _, err := loadMigrations(fsys); err == nil {
+		t.Fatal("expected an error for a migration without a down script")
+	}
+}
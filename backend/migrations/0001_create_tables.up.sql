@@ -1,14 +1,7 @@
--- Roses & Clovers Properties Database Schema
--- Run this file to create all required tables
-
--- Drop tables if they exist (for fresh setup)
-DROP TABLE IF EXISTS leases;
-DROP TABLE IF EXISTS tenants;
-DROP TABLE IF EXISTS properties;
-DROP TABLE IF EXISTS audit_log;
+-- Core tables: properties, tenants, leases and the audit log.
 
 -- Properties table
-CREATE TABLE properties (
+CREATE TABLE IF NOT EXISTS properties (
     id INT AUTO_INCREMENT PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
     address_line1 VARCHAR(255) NOT NULL,
@@ -36,7 +29,7 @@ CREATE TABLE properties (
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
 -- Tenants table
-CREATE TABLE tenants (
+CREATE TABLE IF NOT EXISTS tenants (
     id INT AUTO_INCREMENT PRIMARY KEY,
     first_name VARCHAR(100) NOT NULL,
     last_name VARCHAR(100) NOT NULL,
@@ -53,7 +46,7 @@ CREATE TABLE tenants (
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
 -- Leases table
-CREATE TABLE leases (
+CREATE TABLE IF NOT EXISTS leases (
     id INT AUTO_INCREMENT PRIMARY KEY,
     property_id INT NOT NULL,
     tenant_id INT NOT NULL,
@@ -77,7 +70,7 @@ CREATE TABLE leases (
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
 -- Audit log table (optional but recommended)
-CREATE TABLE audit_log (
+CREATE TABLE IF NOT EXISTS audit_log (
     id INT AUTO_INCREMENT PRIMARY KEY,
     action VARCHAR(50) NOT NULL,
     entity_type VARCHAR(50) NOT NULL,
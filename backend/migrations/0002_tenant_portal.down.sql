@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS maintenance_requests;
+ALTER TABLE tenants DROP COLUMN password_hash;
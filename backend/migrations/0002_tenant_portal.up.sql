@@ -1,5 +1,4 @@
--- Migration 003: Tenant Portal
--- Adds password auth for tenants, maintenance requests, and payment history
+-- Tenant portal: password auth for tenants, maintenance requests, and payment history.
 
 -- Add password_hash to tenants (nullable — existing tenants won't have a password until admin sets one)
 ALTER TABLE tenants ADD COLUMN password_hash VARCHAR(255) DEFAULT NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS application_references;
+DROP TABLE IF EXISTS application_co_applicants;
+DROP TABLE IF EXISTS rental_applications;
+DELETE FROM leases WHERE status = 'draft';
+ALTER TABLE leases MODIFY COLUMN status ENUM('upcoming', 'active', 'ended', 'terminated') NOT NULL DEFAULT 'upcoming';
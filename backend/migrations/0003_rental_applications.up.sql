@@ -0,0 +1,62 @@
+-- Rental applications: submitted by prospects from a listing, reviewed by
+-- admins, and converted into a tenant plus a draft lease once approved.
+
+-- Draft leases are created by application conversion and ignored by the
+-- lease status job until an admin finalises them.
+ALTER TABLE leases MODIFY COLUMN status ENUM('draft','upcoming','active','ended','terminated') NOT NULL DEFAULT 'upcoming';
+
+CREATE TABLE IF NOT EXISTS rental_applications (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    property_id INT NOT NULL,
+    status ENUM('submitted','screening','approved','denied') NOT NULL DEFAULT 'submitted',
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    phone VARCHAR(20) NOT NULL,
+    date_of_birth DATE DEFAULT NULL,
+    current_address VARCHAR(500) DEFAULT NULL,
+    monthly_income DECIMAL(10,2) NOT NULL DEFAULT 0,
+    employer_name VARCHAR(255) DEFAULT NULL,
+    job_title VARCHAR(255) DEFAULT NULL,
+    employment_start_date DATE DEFAULT NULL,
+    desired_move_in DATE NOT NULL,
+    message TEXT DEFAULT NULL,
+    admin_notes TEXT DEFAULT NULL,
+    tenant_id INT DEFAULT NULL,
+    lease_id INT DEFAULT NULL,
+    reviewed_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT fk_app_property FOREIGN KEY (property_id) REFERENCES properties(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_app_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE SET NULL,
+    CONSTRAINT fk_app_lease FOREIGN KEY (lease_id) REFERENCES leases(id) ON DELETE SET NULL,
+    INDEX idx_app_property (property_id),
+    INDEX idx_app_status (status),
+    INDEX idx_app_email (email)
+);
+
+-- Additional adults who will live in the unit
+CREATE TABLE IF NOT EXISTS application_co_applicants (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    application_id INT NOT NULL,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    email VARCHAR(255) DEFAULT NULL,
+    phone VARCHAR(20) DEFAULT NULL,
+    relationship VARCHAR(100) DEFAULT NULL,
+    monthly_income DECIMAL(10,2) NOT NULL DEFAULT 0,
+    CONSTRAINT fk_coapp_application FOREIGN KEY (application_id) REFERENCES rental_applications(id) ON DELETE CASCADE,
+    INDEX idx_coapp_application (application_id)
+);
+
+-- Personal and landlord references
+CREATE TABLE IF NOT EXISTS application_references (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    application_id INT NOT NULL,
+    name VARCHAR(200) NOT NULL,
+    relationship VARCHAR(100) DEFAULT NULL,
+    phone VARCHAR(20) DEFAULT NULL,
+    email VARCHAR(255) DEFAULT NULL,
+    CONSTRAINT fk_ref_application FOREIGN KEY (application_id) REFERENCES rental_applications(id) ON DELETE CASCADE,
+    INDEX idx_ref_application (application_id)
+);
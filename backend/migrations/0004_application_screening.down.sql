@@ -0,0 +1,13 @@
+ALTER TABLE rental_applications
+    DROP COLUMN application_fee,
+    DROP COLUMN fee_paid_at,
+    DROP COLUMN fee_reference,
+    DROP COLUMN screening_provider,
+    DROP COLUMN screening_report_id,
+    DROP COLUMN credit_score,
+    DROP COLUMN eviction_records,
+    DROP COLUMN criminal_records,
+    DROP COLUMN income_to_rent,
+    DROP COLUMN recommendation,
+    DROP COLUMN recommendation_reasons,
+    DROP COLUMN screened_at;
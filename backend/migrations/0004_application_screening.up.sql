@@ -0,0 +1,15 @@
+-- Application fees and tenant screening results, stored on the application.
+
+ALTER TABLE rental_applications
+    ADD COLUMN application_fee DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN fee_paid_at TIMESTAMP NULL DEFAULT NULL,
+    ADD COLUMN fee_reference VARCHAR(100) DEFAULT NULL,
+    ADD COLUMN screening_provider VARCHAR(50) DEFAULT NULL,
+    ADD COLUMN screening_report_id VARCHAR(100) DEFAULT NULL,
+    ADD COLUMN credit_score INT DEFAULT NULL,
+    ADD COLUMN eviction_records INT DEFAULT NULL,
+    ADD COLUMN criminal_records INT DEFAULT NULL,
+    ADD COLUMN income_to_rent DECIMAL(6,2) DEFAULT NULL,
+    ADD COLUMN recommendation ENUM('pass','fail') DEFAULT NULL,
+    ADD COLUMN recommendation_reasons JSON DEFAULT NULL,
+    ADD COLUMN screened_at TIMESTAMP NULL DEFAULT NULL;
@@ -0,0 +1,29 @@
+-- Leads from the public contact form, worked by admins in the lead inbox.
+
+CREATE TABLE IF NOT EXISTS leads (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    property_id INT DEFAULT NULL,
+    name VARCHAR(200) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    phone VARCHAR(20) DEFAULT NULL,
+    message TEXT NOT NULL,
+    status ENUM('new','contacted','showing_scheduled','converted','lost') NOT NULL DEFAULT 'new',
+    source VARCHAR(50) NOT NULL DEFAULT 'contact_form',
+    ip_address VARCHAR(45) DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT fk_lead_property FOREIGN KEY (property_id) REFERENCES properties(id) ON DELETE SET NULL,
+    INDEX idx_lead_status (status),
+    INDEX idx_lead_property (property_id),
+    INDEX idx_lead_created (created_at)
+);
+
+-- Follow-up notes, oldest first
+CREATE TABLE IF NOT EXISTS lead_notes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    lead_id INT NOT NULL,
+    note TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_lead_note_lead FOREIGN KEY (lead_id) REFERENCES leads(id) ON DELETE CASCADE,
+    INDEX idx_lead_note_lead (lead_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS showing_bookings;
+DROP TABLE IF EXISTS showing_slots;
@@ -0,0 +1,31 @@
+-- Showing slots published by admins and bookings made by prospects.
+
+CREATE TABLE IF NOT EXISTS showing_slots (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    property_id INT NOT NULL,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_slot_property FOREIGN KEY (property_id) REFERENCES properties(id) ON DELETE CASCADE,
+    INDEX idx_slot_property_start (property_id, starts_at),
+    INDEX idx_slot_start (starts_at)
+);
+
+-- active_slot_id mirrors slot_id while a booking stands and is cleared on
+-- cancellation; its unique index is what prevents double-booking a slot.
+CREATE TABLE IF NOT EXISTS showing_bookings (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    slot_id INT NOT NULL,
+    active_slot_id INT DEFAULT NULL,
+    name VARCHAR(200) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    phone VARCHAR(20) NOT NULL,
+    status ENUM('booked','cancelled') NOT NULL DEFAULT 'booked',
+    cancel_token CHAR(64) NOT NULL,
+    cancelled_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_booking_slot FOREIGN KEY (slot_id) REFERENCES showing_slots(id) ON DELETE CASCADE,
+    UNIQUE KEY uq_booking_active_slot (active_slot_id),
+    UNIQUE KEY uq_booking_cancel_token (cancel_token),
+    INDEX idx_booking_slot (slot_id)
+);
@@ -0,0 +1,19 @@
+-- Property photo galleries. Files live in the media store under
+-- storage_key; each image has original, card and detail variants.
+
+CREATE TABLE IF NOT EXISTS property_images (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    property_id INT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    original_ext VARCHAR(10) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    caption VARCHAR(500) DEFAULT NULL,
+    sort_order INT NOT NULL DEFAULT 0,
+    is_cover BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_image_property FOREIGN KEY (property_id) REFERENCES properties(id) ON DELETE CASCADE,
+    UNIQUE KEY uq_image_storage_key (storage_key),
+    INDEX idx_image_property_order (property_id, sort_order)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE payments DROP FOREIGN KEY fk_pay_unit, DROP INDEX idx_pay_unit, DROP COLUMN unit_id;
+ALTER TABLE maintenance_requests DROP FOREIGN KEY fk_mr_unit, DROP INDEX idx_mr_unit, DROP COLUMN unit_id;
+ALTER TABLE leases DROP FOREIGN KEY fk_lease_unit, DROP INDEX idx_lease_unit, DROP COLUMN unit_id;
+DROP TABLE IF EXISTS units;
@@ -0,0 +1,47 @@
+-- Buildings and units. A property is now a building; each rentable unit
+-- carries its own size, rent and availability, and leases, maintenance
+-- requests and payments point at the unit. The unit-level columns stay on
+-- properties as a rollup of the building's units so existing listing
+-- queries and clients keep working.
+
+CREATE TABLE IF NOT EXISTS units (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    property_id INT NOT NULL,
+    unit_number VARCHAR(50) NOT NULL,
+    bedrooms INT NOT NULL DEFAULT 1,
+    bathrooms DECIMAL(3,1) NOT NULL DEFAULT 1.0,
+    square_feet INT DEFAULT NULL,
+    monthly_rent DECIMAL(10,2) NOT NULL,
+    deposit_amount DECIMAL(10,2) DEFAULT NULL,
+    available BOOLEAN NOT NULL DEFAULT TRUE,
+    available_date DATE DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT fk_unit_property FOREIGN KEY (property_id) REFERENCES properties(id) ON DELETE CASCADE,
+    UNIQUE KEY uq_unit_number (property_id, unit_number),
+    INDEX idx_unit_available (available)
+);
+
+-- Every existing property becomes a single-unit building.
+INSERT INTO units (property_id, unit_number, bedrooms, bathrooms, square_feet, monthly_rent,
+    deposit_amount, available, available_date)
+SELECT id, '1', bedrooms, bathrooms, square_feet, monthly_rent, deposit_amount, available, available_date
+FROM properties;
+
+ALTER TABLE leases ADD COLUMN unit_id INT DEFAULT NULL;
+ALTER TABLE maintenance_requests ADD COLUMN unit_id INT DEFAULT NULL;
+ALTER TABLE payments ADD COLUMN unit_id INT DEFAULT NULL;
+
+UPDATE leases l JOIN units u ON u.property_id = l.property_id SET l.unit_id = u.id;
+UPDATE maintenance_requests mr JOIN units u ON u.property_id = mr.property_id SET mr.unit_id = u.id;
+UPDATE payments p JOIN units u ON u.property_id = p.property_id SET p.unit_id = u.id;
+
+ALTER TABLE leases
+    ADD CONSTRAINT fk_lease_unit FOREIGN KEY (unit_id) REFERENCES units(id) ON DELETE RESTRICT,
+    ADD INDEX idx_lease_unit (unit_id);
+ALTER TABLE maintenance_requests
+    ADD CONSTRAINT fk_mr_unit FOREIGN KEY (unit_id) REFERENCES units(id) ON DELETE RESTRICT,
+    ADD INDEX idx_mr_unit (unit_id);
+ALTER TABLE payments
+    ADD CONSTRAINT fk_pay_unit FOREIGN KEY (unit_id) REFERENCES units(id) ON DELETE RESTRICT,
+    ADD INDEX idx_pay_unit (unit_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS property_search_terms;
+ALTER TABLE properties DROP INDEX idx_property_location, DROP COLUMN latitude, DROP COLUMN longitude, DROP COLUMN pet_policy;
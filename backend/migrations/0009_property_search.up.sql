@@ -0,0 +1,19 @@
+-- Listing search. Properties get coordinates (filled by the geocoder) for
+-- radius search and a pet policy. property_search_terms is an inverted index
+-- over name, description and amenities maintained by the application, which
+-- keeps ranking identical on MySQL and the SQLite test database.
+
+ALTER TABLE properties
+    ADD COLUMN latitude DECIMAL(9,6) DEFAULT NULL,
+    ADD COLUMN longitude DECIMAL(9,6) DEFAULT NULL,
+    ADD COLUMN pet_policy ENUM('none', 'cats', 'dogs', 'cats_and_dogs') DEFAULT NULL,
+    ADD INDEX idx_property_location (latitude, longitude);
+
+CREATE TABLE IF NOT EXISTS property_search_terms (
+    property_id INT NOT NULL,
+    term VARCHAR(64) NOT NULL,
+    weight INT NOT NULL DEFAULT 1,
+    PRIMARY KEY (property_id, term),
+    CONSTRAINT fk_search_property FOREIGN KEY (property_id) REFERENCES properties(id) ON DELETE CASCADE,
+    INDEX idx_search_term (term)
+);
@@ -0,0 +1,26 @@
+-- Saved listing searches with email alerts. filters holds the public
+-- listing query parameters as a JSON object. saved_search_matches records
+-- which available properties a search has already been told about; a row is
+-- removed when the property stops matching so it alerts again when it
+-- comes back on the market.
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    filters JSON NOT NULL,
+    token CHAR(64) NOT NULL,
+    verified_at TIMESTAMP NULL DEFAULT NULL,
+    last_sent_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_saved_search_token (token),
+    INDEX idx_saved_search_verified (verified_at)
+);
+
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+    saved_search_id INT NOT NULL,
+    property_id INT NOT NULL,
+    notified_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (saved_search_id, property_id),
+    CONSTRAINT fk_match_search FOREIGN KEY (saved_search_id) REFERENCES saved_searches(id) ON DELETE CASCADE,
+    CONSTRAINT fk_match_property FOREIGN KEY (property_id) REFERENCES properties(id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+-- Listing syndication. Available properties are published in the XML and
+-- JSON listing feeds unless an admin opts them out.
+
+ALTER TABLE properties ADD COLUMN syndicate BOOLEAN NOT NULL DEFAULT TRUE;
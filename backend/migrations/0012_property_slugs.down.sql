@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS property_slug_redirects;
+ALTER TABLE properties DROP INDEX uq_property_slug, DROP COLUMN slug;
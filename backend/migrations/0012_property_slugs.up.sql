@@ -0,0 +1,16 @@
+-- Property slugs. Public pages are addressed by a slug made from the name
+-- and city; property_slug_redirects keeps the slugs a rename retired so old
+-- links still resolve. Existing properties are slugged by the search-index
+-- job.
+
+ALTER TABLE properties
+    ADD COLUMN slug VARCHAR(200) NULL DEFAULT NULL,
+    ADD UNIQUE KEY uq_property_slug (slug);
+
+CREATE TABLE IF NOT EXISTS property_slug_redirects (
+    slug VARCHAR(200) PRIMARY KEY,
+    property_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_slug_redirect_property (property_id),
+    CONSTRAINT fk_slug_redirect_property FOREIGN KEY (property_id) REFERENCES properties(id) ON DELETE CASCADE
+);
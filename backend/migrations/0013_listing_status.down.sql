@@ -0,0 +1 @@
+ALTER TABLE properties DROP INDEX idx_properties_listing_status, DROP COLUMN listing_status;
@@ -0,0 +1,7 @@
+-- Listing status, separate from available (which tracks whether a property
+-- is let). Only listed properties appear on the public site and in the
+-- feeds; deleting a property archives it instead of removing the row.
+
+ALTER TABLE properties
+    ADD COLUMN listing_status VARCHAR(20) NOT NULL DEFAULT 'listed',
+    ADD INDEX idx_properties_listing_status (listing_status);
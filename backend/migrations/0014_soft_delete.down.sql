@@ -0,0 +1,8 @@
+-- Rows still in the trash would reappear as live records.
+DELETE FROM payments WHERE deleted_at IS NOT NULL;
+DELETE FROM leases WHERE deleted_at IS NOT NULL;
+DELETE FROM tenants WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE payments DROP INDEX idx_payments_deleted_at, DROP COLUMN deleted_at;
+ALTER TABLE leases DROP INDEX idx_leases_deleted_at, DROP COLUMN deleted_at;
+ALTER TABLE tenants DROP INDEX idx_tenants_deleted_at, DROP COLUMN deleted_at;
@@ -0,0 +1,15 @@
+-- Soft delete for tenants, leases and payments. Deleting sets deleted_at
+-- and hides the row everywhere but the admin trash, where it can be
+-- restored; the trash-purge job removes rows older than TRASH_RETENTION.
+
+ALTER TABLE tenants
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_tenants_deleted_at (deleted_at);
+
+ALTER TABLE leases
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_leases_deleted_at (deleted_at);
+
+ALTER TABLE payments
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_payments_deleted_at (deleted_at);
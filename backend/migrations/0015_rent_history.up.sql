@@ -0,0 +1,23 @@
+-- Asking-rent history per property. Applied rows record each change to
+-- properties.monthly_rent with the rent it replaced; scheduled rows are
+-- future-dated changes the rent-changes job applies once they come due.
+-- Existing properties start their history with their current rent.
+
+CREATE TABLE IF NOT EXISTS rent_history (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    property_id INT NOT NULL,
+    effective_date DATE NOT NULL,
+    monthly_rent DECIMAL(10, 2) NOT NULL,
+    previous_rent DECIMAL(10, 2) NULL DEFAULT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'applied',
+    applied_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_rent_history_property (property_id, effective_date),
+    INDEX idx_rent_history_due (status, effective_date),
+    CONSTRAINT fk_rent_history_property FOREIGN KEY (property_id) REFERENCES properties(id) ON DELETE CASCADE
+);
+
+INSERT INTO rent_history (property_id, effective_date, monthly_rent, reason, status, applied_at)
+SELECT id, CURRENT_DATE, monthly_rent, 'Asking rent when history began', 'applied', CURRENT_TIMESTAMP
+FROM properties;
@@ -0,0 +1,6 @@
+ALTER TABLE properties
+    DROP FOREIGN KEY fk_properties_owner,
+    DROP INDEX idx_properties_owner,
+    DROP COLUMN owner_id;
+
+DROP TABLE IF EXISTS owners;
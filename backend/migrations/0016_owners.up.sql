@@ -0,0 +1,25 @@
+-- Owners (landlords) whose properties we manage, with how and what we pay
+-- them. Properties link to an owner; unowned properties are our own.
+
+CREATE TABLE IF NOT EXISTS owners (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    company VARCHAR(255) NULL DEFAULT NULL,
+    email VARCHAR(255) NOT NULL,
+    phone VARCHAR(50) NULL DEFAULT NULL,
+    mailing_address TEXT NULL,
+    payout_method VARCHAR(20) NOT NULL DEFAULT 'check',
+    payout_account_name VARCHAR(255) NULL DEFAULT NULL,
+    payout_routing_number VARCHAR(9) NULL DEFAULT NULL,
+    payout_account_number VARCHAR(17) NULL DEFAULT NULL,
+    management_fee_percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    notes TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_owners_email (email)
+);
+
+ALTER TABLE properties
+    ADD COLUMN owner_id INT NULL DEFAULT NULL,
+    ADD INDEX idx_properties_owner (owner_id),
+    ADD CONSTRAINT fk_properties_owner FOREIGN KEY (owner_id) REFERENCES owners(id);
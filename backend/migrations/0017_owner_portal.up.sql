@@ -0,0 +1,3 @@
+-- Owner portal logins. Owners without a password have no portal access.
+
+ALTER TABLE owners ADD COLUMN password_hash VARCHAR(255) NULL DEFAULT NULL;
@@ -0,0 +1,23 @@
+-- Property expenses for profit and loss reporting. A receipt is an uploaded
+-- file in media storage; an expense can point at the maintenance request
+-- it paid for.
+
+CREATE TABLE IF NOT EXISTS expenses (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    property_id INT NOT NULL,
+    maintenance_request_id INT NULL DEFAULT NULL,
+    category VARCHAR(30) NOT NULL DEFAULT 'other',
+    vendor VARCHAR(255) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    expense_date DATE NOT NULL,
+    description TEXT NULL,
+    receipt_key VARCHAR(255) NULL DEFAULT NULL,
+    receipt_name VARCHAR(255) NULL DEFAULT NULL,
+    receipt_content_type VARCHAR(100) NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_expenses_property_date (property_id, expense_date),
+    INDEX idx_expenses_date (expense_date),
+    CONSTRAINT fk_expenses_property FOREIGN KEY (property_id) REFERENCES properties(id),
+    CONSTRAINT fk_expenses_request FOREIGN KEY (maintenance_request_id) REFERENCES maintenance_requests(id)
+);
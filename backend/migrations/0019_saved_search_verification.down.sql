@@ -0,0 +1 @@
+ALTER TABLE saved_searches DROP COLUMN verification_sent_at;
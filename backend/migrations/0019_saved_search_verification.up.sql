@@ -0,0 +1,6 @@
+-- Verification emails are sent after the saved search is committed. Rows
+-- still NULL here are retried by the saved-search-alerts job.
+
+ALTER TABLE saved_searches ADD COLUMN verification_sent_at TIMESTAMP NULL DEFAULT NULL;
+
+UPDATE saved_searches SET verification_sent_at = created_at;
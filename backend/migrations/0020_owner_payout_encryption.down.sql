@@ -0,0 +1,7 @@
+-- Encrypted values don't fit the old columns; clear or decrypt them before
+-- rolling back, or MySQL refuses the narrower columns.
+
+ALTER TABLE owners
+    DROP COLUMN payout_account_last4,
+    MODIFY payout_routing_number VARCHAR(9) NULL DEFAULT NULL,
+    MODIFY payout_account_number VARCHAR(17) NULL DEFAULT NULL;
@@ -0,0 +1,12 @@
+-- Routing and account numbers are now stored encrypted (see owners.go), so
+-- the columns are widened to hold the ciphertext. The server encrypts any
+-- plaintext values left from before on startup. The account's last four
+-- digits are kept in the clear for display.
+
+ALTER TABLE owners
+    MODIFY payout_routing_number VARCHAR(255) NULL DEFAULT NULL,
+    MODIFY payout_account_number VARCHAR(255) NULL DEFAULT NULL,
+    ADD COLUMN payout_account_last4 VARCHAR(4) NULL DEFAULT NULL;
+
+UPDATE owners SET payout_account_last4 = RIGHT(payout_account_number, 4)
+WHERE payout_account_number IS NOT NULL;
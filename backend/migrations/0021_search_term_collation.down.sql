@@ -0,0 +1,5 @@
+-- Rolling back fails while the index holds terms that differ only by accent;
+-- empty property_search_terms first (the server rebuilds it on startup).
+
+ALTER TABLE property_search_terms
+    MODIFY term VARCHAR(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL;
@@ -0,0 +1,6 @@
+-- Search terms are compared byte for byte. Under the default accent- and
+-- case-insensitive collation "café" and "cafe" are the same key, so indexing
+-- a description that used both failed with a duplicate primary key.
+
+ALTER TABLE property_search_terms
+    MODIFY term VARCHAR(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL;
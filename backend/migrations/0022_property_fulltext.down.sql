@@ -0,0 +1,10 @@
+-- property_search_terms went stale while unused; emptying it makes the
+-- previous release's search-index job rebuild every property's terms.
+
+DELETE FROM property_search_terms;
+
+ALTER TABLE properties
+    DROP INDEX ft_property_amenities,
+    DROP INDEX ft_property_name,
+    DROP INDEX ft_property_search,
+    DROP COLUMN amenities_text;
@@ -0,0 +1,16 @@
+-- Listing text search moves to InnoDB FULLTEXT indexes, which MySQL keeps
+-- current on every write. The application-maintained property_search_terms
+-- table is no longer written; it stays so a rollback can refill it.
+-- FULLTEXT cannot index a JSON column, so amenities_text carries the
+-- amenities as plain text. InnoDB builds one FULLTEXT index per statement.
+
+ALTER TABLE properties
+    ADD COLUMN amenities_text TEXT GENERATED ALWAYS AS (CAST(amenities AS CHAR)) STORED;
+
+ALTER TABLE properties ADD FULLTEXT INDEX ft_property_search (name, description, amenities_text);
+
+-- Single-column indexes for ranking: a name match outweighs an amenity
+-- match, which outweighs one in the description.
+ALTER TABLE properties ADD FULLTEXT INDEX ft_property_name (name);
+
+ALTER TABLE properties ADD FULLTEXT INDEX ft_property_amenities (amenities_text);
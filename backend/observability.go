@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// ============================================================================
+// OBSERVABILITY - REQUEST IDS, ACCESS LOGS, METRICS
+// ============================================================================
+
+const requestIDHeader = "X-Request-ID"
+
+// accessLog receives one JSON line per request. Tests swap it out.
+var accessLog = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+type contextKey string
+
+const requestIDKey contextKey = "requestID"
+
+// Incoming request IDs are reused when they look sane so a trace can span
+// the frontend proxy and the API.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// requestIDFrom returns the ID assigned to r by observe, if any.
+func requestIDFrom(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey).(string)
+	return id
+}
+
+var (
+	metricsRegistry = prometheus.NewRegistry()
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by route pattern, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by route pattern and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		dbStatsCollector{},
+		businessCollector{},
+	)
+}
+
+// statusRecorder captures the status code and size written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += n
+	return n, err
+}
+
+// observe assigns a request ID, records metrics, and writes an access log
+// line for every request. Routes are labelled by their mux pattern so IDs
+// in paths do not blow up metric cardinality.
+func observe(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = generateToken()[:16]
+		}
+		w.Header().Set(requestIDHeader, id)
+		r = r.WithContext(context.WithValue(r.Context(), requestIDKey, id))
+
+		route := "unmatched"
+		if _, pattern := mux.Handler(r); pattern != "" {
+			route = pattern
+		}
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		elapsed := time.Since(start)
+		httpRequests.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Inc()
+		httpDuration.WithLabelValues(route, r.Method).Observe(elapsed.Seconds())
+
+		accessLog.LogAttrs(r.Context(), slog.LevelInfo, "request",
+			slog.String("requestId", id),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("route", route),
+			slog.Int("status", rec.status),
+			slog.Int("bytes", rec.bytes),
+			slog.Float64("durationMs", float64(elapsed.Microseconds())/1000),
+			slog.String("remoteAddr", r.RemoteAddr),
+			slog.String("userAgent", r.UserAgent()),
+		)
+	})
+}
+
+// metricsHandler serves the Prometheus registry. When METRICS_TOKEN is set,
+// scrapers must send it as a bearer token.
+func metricsHandler() http.Handler {
+	h := promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.MetricsToken != "" {
+			want := "Bearer " + cfg.MetricsToken
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) != 1 {
+				jsonError(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// ============================================================================
+// METRICS - COLLECTORS
+// ============================================================================
+
+var (
+	dbOpenDesc      = prometheus.NewDesc("db_open_connections", "Open connections to MySQL.", nil, nil)
+	dbInUseDesc     = prometheus.NewDesc("db_in_use_connections", "Connections currently in use.", nil, nil)
+	dbIdleDesc      = prometheus.NewDesc("db_idle_connections", "Idle connections in the pool.", nil, nil)
+	dbMaxOpenDesc   = prometheus.NewDesc("db_max_open_connections", "Configured maximum open connections.", nil, nil)
+	dbWaitCountDesc = prometheus.NewDesc("db_wait_count_total", "Times a query waited for a free connection.", nil, nil)
+	dbWaitDurDesc   = prometheus.NewDesc("db_wait_duration_seconds_total", "Time spent waiting for a free connection.", nil, nil)
+)
+
+// dbStatsCollector reports sql.DB pool stats at scrape time.
+type dbStatsCollector struct{}
+
+func (dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbOpenDesc
+	ch <- dbInUseDesc
+	ch <- dbIdleDesc
+	ch <- dbMaxOpenDesc
+	ch <- dbWaitCountDesc
+	ch <- dbWaitDurDesc
+}
+
+func (dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	if db == nil {
+		return
+	}
+	s := db.Stats()
+	ch <- prometheus.MustNewConstMetric(dbOpenDesc, prometheus.GaugeValue, float64(s.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(dbInUseDesc, prometheus.GaugeValue, float64(s.InUse))
+	ch <- prometheus.MustNewConstMetric(dbIdleDesc, prometheus.GaugeValue, float64(s.Idle))
+	ch <- prometheus.MustNewConstMetric(dbMaxOpenDesc, prometheus.GaugeValue, float64(s.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(dbWaitCountDesc, prometheus.CounterValue, float64(s.WaitCount))
+	ch <- prometheus.MustNewConstMetric(dbWaitDurDesc, prometheus.CounterValue, s.WaitDuration.Seconds())
+}
+
+// businessGauges are counted from MySQL on every scrape.
+var businessGauges = []struct {
+	desc  *prometheus.Desc
+	query string
+}{
+	{prometheus.NewDesc("rc_active_leases", "Leases with status active.", nil, nil),
+		"SELECT COUNT(*) FROM leases WHERE status = 'active' AND deleted_at IS NULL"},
+	{prometheus.NewDesc("rc_upcoming_leases", "Leases with status upcoming.", nil, nil),
+		"SELECT COUNT(*) FROM leases WHERE status = 'upcoming' AND deleted_at IS NULL"},
+	{prometheus.NewDesc("rc_available_properties", "Listed properties marked available.", nil, nil),
+		"SELECT COUNT(*) FROM properties WHERE available = TRUE AND listing_status = 'listed'"},
+	{prometheus.NewDesc("rc_open_maintenance_requests", "Maintenance requests that are open or in progress.", nil, nil),
+		"SELECT COUNT(*) FROM maintenance_requests WHERE status IN ('open', 'in_progress')"},
+}
+
+type businessCollector struct{}
+
+func (businessCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, g := range businessGauges {
+		ch <- g.desc
+	}
+}
+
+func (businessCollector) Collect(ch chan<- prometheus.Metric) {
+	if db == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for _, g := range businessGauges {
+		var n int
+		if err := db.QueryRowContext(ctx, g.query).Scan(&n); err != nil {
+			ch <- prometheus.NewInvalidMetric(g.desc, err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, float64(n))
+	}
+}
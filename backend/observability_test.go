@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDOnResponsesAndErrors(t *testing.T) {
+	e := newTestEnv(t)
+
+	rec := e.do(http.MethodGet, "/api/admin/me", "", nil)
+	e.expect(rec, http.StatusUnauthorized)
+	id := rec.Header().Get(requestIDHeader)
+	if id == "" {
+		t.Fatal("expected a generated request ID")
+	}
+	if body := decode[ErrorResponse](t, rec); body.RequestID != id {
+		t.Fatalf("error body request ID %q does not match header %q", body.RequestID, id)
+	}
+
+	// A well-formed incoming ID is reused; a malformed one is replaced.
+	req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+	req.Header.Set(requestIDHeader, "frontend-abc.123")
+	rec = httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get(requestIDHeader); got != "frontend-abc.123" {
+		t.Fatalf("expected incoming request ID to be reused, got %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/health", nil)
+	req.Header.Set(requestIDHeader, "bad id\nwith newline")
+	rec = httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get(requestIDHeader); got == "" || strings.Contains(got, " ") {
+		t.Fatalf("expected malformed request ID to be replaced, got %q", got)
+	}
+}
+
+func TestAccessLogIsStructured(t *testing.T) {
+	e := newTestEnv(t)
+	var buf bytes.Buffer
+	accessLog = slog.New(slog.NewJSONHandler(&buf, nil))
+
+	rec := e.do(http.MethodGet, "/api/properties/9999", "", nil)
+	e.expect(rec, http.StatusNotFound)
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("access log is not JSON: %q", buf.String())
+	}
+	if line["route"] != "/api/properties/" || line["path"] != "/api/properties/9999" || line["status"] != float64(404) {
+		t.Fatalf("unexpected access log line: %v", line)
+	}
+	if line["requestId"] != rec.Header().Get(requestIDHeader) {
+		t.Fatalf("access log request ID %v does not match header", line["requestId"])
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Metered", 1500)
+	tid := e.createTenant(token, "metered@example.com", "")
+	e.expect(e.createLease(token, pid, tid, day(-5), day(300)), http.StatusCreated)
+	e.do(http.MethodGet, "/api/properties/424242", "", nil)
+
+	rec := e.do(http.MethodGet, "/metrics", "", nil)
+	e.expect(rec, http.StatusOK)
+	body := rec.Body.String()
+	for _, want := range []string{
+		`http_requests_total{method="GET",route="/api/properties/",status="404"}`,
+		`http_request_duration_seconds_bucket{method="POST",route="/api/admin/leases"`,
+		"db_open_connections",
+		"rc_active_leases 1",
+		"rc_open_maintenance_requests 0",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+}
+
+func TestMetricsToken(t *testing.T) {
+	e := newTestEnv(t)
+	cfg.MetricsToken = "scrape-secret"
+
+	e.expect(e.do(http.MethodGet, "/metrics", "", nil), http.StatusUnauthorized)
+	e.expect(e.do(http.MethodGet, "/metrics", "wrong", nil), http.StatusUnauthorized)
+	e.expect(e.do(http.MethodGet, "/metrics", "scrape-secret", nil), http.StatusOK)
+}
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// The owner portal gives each owner a read-only view of their own
+// portfolio: properties, occupancy, leases, maintenance requests, payments
+// and a monthly statement. The list endpoints reuse the admin lists with
+// ownerId forced to the logged-in owner, so they accept the same filters.
+
+// Token store for owner sessions (maps token → owner ID)
+var (
+	ownerTokenStore = make(map[string]ownerSession)
+	ownerTokenMutex sync.RWMutex
+)
+
+type ownerSession struct {
+	OwnerID int
+	Expiry  time.Time
+}
+
+// OwnerOccupancy is the response of GET /api/owner/occupancy.
+type OwnerOccupancy struct {
+	TotalUnits    int                 `json:"totalUnits"`
+	OccupiedUnits int                 `json:"occupiedUnits"`
+	VacantUnits   int                 `json:"vacantUnits"`
+	OccupancyRate float64             `json:"occupancyRate"`
+	Buildings     []BuildingOccupancy `json:"buildings"`
+}
+
+// OwnerStatement is an owner's monthly statement. Rent is what was
+// collected in the month, not what was due.
+type OwnerStatement struct {
+	OwnerID              int             `json:"ownerId"`
+	OwnerName            string          `json:"ownerName"`
+	Month                string          `json:"month"`
+	PeriodStart          string          `json:"periodStart"`
+	PeriodEnd            string          `json:"periodEnd"`
+	ManagementFeePercent float64         `json:"managementFeePercent"`
+	GrossRent            float64         `json:"grossRent"`
+	ManagementFee        float64         `json:"managementFee"`
+	MaintenanceExpenses  float64         `json:"maintenanceExpenses"`
+	NetDistribution      float64         `json:"netDistribution"`
+	Properties           []StatementLine `json:"properties"`
+}
+
+// StatementLine is one property's share of an owner statement.
+type StatementLine struct {
+	PropertyID          int     `json:"propertyId"`
+	PropertyName        string  `json:"propertyName"`
+	GrossRent           float64 `json:"grossRent"`
+	ManagementFee       float64 `json:"managementFee"`
+	MaintenanceExpenses float64 `json:"maintenanceExpenses"`
+	Net                 float64 `json:"net"`
+}
+
+// ============================================================================
+// HANDLERS - OWNER AUTH
+// ============================================================================
+
+func ownerLoginHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req TenantLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.Email == "" || req.Password == "" {
+		jsonError(w, "Email and password are required", http.StatusBadRequest)
+		return
+	}
+
+	var ownerID int
+	var passwordHash sql.NullString
+	err := db.QueryRow("SELECT id, password_hash FROM owners WHERE email = ?", req.Email).
+		Scan(&ownerID, &passwordHash)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Invalid email or password", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		log.Printf("Error looking up owner: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if !passwordHash.Valid || passwordHash.String == "" {
+		jsonError(w, "Portal access not set up for this account. Contact your property manager.", http.StatusUnauthorized)
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash.String), []byte(req.Password)); err != nil {
+		jsonError(w, "Invalid email or password", http.StatusUnauthorized)
+		return
+	}
+
+	token := generateToken()
+	ownerTokenMutex.Lock()
+	ownerTokenStore[token] = ownerSession{OwnerID: ownerID, Expiry: time.Now().Add(cfg.OwnerTokenTTL)}
+	ownerTokenMutex.Unlock()
+
+	jsonResponse(w, LoginResponse{Token: token}, http.StatusOK)
+}
+
+func ownerLogoutHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	if authHeader != "" {
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			ownerTokenMutex.Lock()
+			delete(ownerTokenStore, parts[1])
+			ownerTokenMutex.Unlock()
+		}
+	}
+
+	jsonResponse(w, map[string]string{"message": "Logged out"}, http.StatusOK)
+}
+
+func ownerMeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ownerID, ok := requireOwnerAuth(w, r)
+	if !ok {
+		return
+	}
+	o, err := loadOwner(ownerID)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Owner not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting owner: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, OwnerProfile{Owner: o}, http.StatusOK)
+}
+
+// OwnerProfile is an Owner as the owner sees it in the portal. The shadowing
+// Notes is always nil, so staff notes about the owner are left out.
+type OwnerProfile struct {
+	Owner
+	Notes *string `json:"notes,omitempty"`
+}
+
+// requireOwnerAuth validates the owner token and returns (ownerID, true) or writes 401 and returns (0, false).
+func requireOwnerAuth(w http.ResponseWriter, r *http.Request) (int, bool) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		jsonError(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, false
+	}
+
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		jsonError(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, false
+	}
+
+	token := parts[1]
+
+	ownerTokenMutex.RLock()
+	session, exists := ownerTokenStore[token]
+	ownerTokenMutex.RUnlock()
+
+	if !exists || time.Now().After(session.Expiry) {
+		if exists {
+			ownerTokenMutex.Lock()
+			delete(ownerTokenStore, token)
+			ownerTokenMutex.Unlock()
+		}
+		jsonError(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, false
+	}
+
+	return session.OwnerID, true
+}
+
+// revokeOwnerSessions logs a deleted owner out of the portal.
+func revokeOwnerSessions(ownerID int) {
+	ownerTokenMutex.Lock()
+	defer ownerTokenMutex.Unlock()
+	for token, session := range ownerTokenStore {
+		if session.OwnerID == ownerID {
+			delete(ownerTokenStore, token)
+		}
+	}
+}
+
+// ============================================================================
+// HANDLERS - OWNER PORTAL
+// ============================================================================
+
+// ownerGET checks the method and the owner token for a read-only portal
+// endpoint.
+func ownerGET(w http.ResponseWriter, r *http.Request) (int, bool) {
+	if r.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return 0, false
+	}
+	return requireOwnerAuth(w, r)
+}
+
+// ownerScoped returns a copy of r whose ownerId filter is the logged-in
+// owner, whatever the client sent.
+func ownerScoped(r *http.Request, ownerID int) *http.Request {
+	scoped := r.Clone(r.Context())
+	q := scoped.URL.Query()
+	q.Set("ownerId", strconv.Itoa(ownerID))
+	scoped.URL.RawQuery = q.Encode()
+	return scoped
+}
+
+// ownerPropertiesHandler lists the owner's properties that aren't archived.
+func ownerPropertiesHandler(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerGET(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := db.Query(`
+		SELECT `+propertyColumns+`
+		FROM properties WHERE owner_id = ? AND listing_status <> ?
+		ORDER BY name, id
+	`, ownerID, listingArchived)
+	if err != nil {
+		log.Printf("Error querying owner properties: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	properties := []Property{}
+	for rows.Next() {
+		p, err := scanProperty(rows)
+		if err != nil {
+			log.Printf("Error scanning property: %v", err)
+			continue
+		}
+		properties = append(properties, p)
+	}
+
+	jsonResponse(w, properties, http.StatusOK)
+}
+
+func ownerOccupancyHandler(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerGET(w, r)
+	if !ok {
+		return
+	}
+
+	buildings, err := loadBuildingOccupancy(strconv.Itoa(ownerID))
+	if err != nil {
+		log.Printf("Error loading occupancy: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	occupancy := OwnerOccupancy{Buildings: buildings}
+	for _, b := range buildings {
+		occupancy.TotalUnits += b.TotalUnits
+		occupancy.OccupiedUnits += b.OccupiedUnits
+		occupancy.VacantUnits += b.VacantUnits
+	}
+	if occupancy.TotalUnits > 0 {
+		occupancy.OccupancyRate = roundCents(float64(occupancy.OccupiedUnits) * 100 / float64(occupancy.TotalUnits))
+	}
+
+	jsonResponse(w, occupancy, http.StatusOK)
+}
+
+func ownerLeasesHandler(w http.ResponseWriter, r *http.Request) {
+	if ownerID, ok := ownerGET(w, r); ok {
+		getLeases(w, ownerScoped(r, ownerID))
+	}
+}
+
+func ownerRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	if ownerID, ok := ownerGET(w, r); ok {
+		getAdminRequests(w, ownerScoped(r, ownerID))
+	}
+}
+
+func ownerPaymentsHandler(w http.ResponseWriter, r *http.Request) {
+	if ownerID, ok := ownerGET(w, r); ok {
+		getAdminPayments(w, ownerScoped(r, ownerID))
+	}
+}
+
+func ownerStatementHandler(w http.ResponseWriter, r *http.Request) {
+	if ownerID, ok := ownerGET(w, r); ok {
+		ownerStatementResponse(w, r, ownerID)
+	}
+}
+
+// ownerStatementResponse writes the statement for ?month=YYYY-MM, the
+// current month by default. Shared by the portal and the admin route.
+func ownerStatementResponse(w http.ResponseWriter, r *http.Request, ownerID int) {
+	month := time.Now()
+	if raw := r.URL.Query().Get("month"); raw != "" {
+		m, err := time.Parse("2006-01", raw)
+		if err != nil {
+			jsonError(w, "Month must be YYYY-MM", http.StatusBadRequest)
+			return
+		}
+		month = m
+	}
+
+	statement, err := buildOwnerStatement(ownerID, month)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Owner not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error building owner statement: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, statement, http.StatusOK)
+}
+
+// ============================================================================
+// OWNER STATEMENTS
+// ============================================================================
+
+// buildOwnerStatement totals the month containing month for an owner's
+// current properties. Gross rent is completed rent and late fee payments;
+// deposits are held, not distributed. The management fee is the owner's
+// percentage of each property's gross, rounded per property so the lines
+// add up to the totals. Maintenance expenses are repairs and expenses paid
+// against a maintenance request (see expenses.go). Archived properties
+// appear only when they had rent or maintenance in the month.
+func buildOwnerStatement(ownerID int, month time.Time) (OwnerStatement, error) {
+	o, err := loadOwner(ownerID)
+	if err != nil {
+		return OwnerStatement{}, err
+	}
+
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, -1)
+	s := OwnerStatement{
+		OwnerID:              o.ID,
+		OwnerName:            o.Name,
+		Month:                start.Format("2006-01"),
+		PeriodStart:          start.Format("2006-01-02"),
+		PeriodEnd:            end.Format("2006-01-02"),
+		ManagementFeePercent: o.ManagementFeePercent,
+		Properties:           []StatementLine{},
+	}
+
+	rows, err := db.Query(`
+		SELECT p.id, p.name, p.listing_status, COALESCE(SUM(pay.amount), 0),
+			   (SELECT COALESCE(SUM(e.amount), 0) FROM expenses e
+				WHERE e.property_id = p.id AND e.expense_date BETWEEN ? AND ? AND `+maintenanceExpenseClause+`)
+		FROM properties p
+		LEFT JOIN payments pay ON pay.property_id = p.id AND pay.deleted_at IS NULL
+			AND pay.status = 'completed' AND pay.payment_type IN ('rent', 'late_fee')
+			AND pay.payment_date BETWEEN ? AND ?
+		WHERE p.owner_id = ?
+		GROUP BY p.id, p.name, p.listing_status
+		ORDER BY p.name, p.id
+	`, s.PeriodStart, s.PeriodEnd, s.PeriodStart, s.PeriodEnd, ownerID)
+	if err != nil {
+		return s, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line StatementLine
+		var status string
+		if err := rows.Scan(&line.PropertyID, &line.PropertyName, &status, &line.GrossRent, &line.MaintenanceExpenses); err != nil {
+			return s, err
+		}
+		if status == listingArchived && line.GrossRent == 0 && line.MaintenanceExpenses == 0 {
+			continue
+		}
+		line.GrossRent = roundCents(line.GrossRent)
+		line.MaintenanceExpenses = roundCents(line.MaintenanceExpenses)
+		line.ManagementFee = roundCents(line.GrossRent * o.ManagementFeePercent / 100)
+		line.Net = roundCents(line.GrossRent - line.ManagementFee - line.MaintenanceExpenses)
+
+		s.GrossRent += line.GrossRent
+		s.ManagementFee += line.ManagementFee
+		s.MaintenanceExpenses += line.MaintenanceExpenses
+		s.Properties = append(s.Properties, line)
+	}
+	if err := rows.Err(); err != nil {
+		return s, err
+	}
+
+	s.GrossRent = roundCents(s.GrossRent)
+	s.ManagementFee = roundCents(s.ManagementFee)
+	s.MaintenanceExpenses = roundCents(s.MaintenanceExpenses)
+	s.NetDistribution = roundCents(s.GrossRent - s.ManagementFee - s.MaintenanceExpenses)
+	return s, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func (e *testEnv) ownerToken(email, password string) string {
+	e.t.Helper()
+	rec := e.do(http.MethodPost, "/api/owner/login", "", TenantLoginRequest{Email: email, Password: password})
+	e.expect(rec, http.StatusOK)
+	return decode[LoginResponse](e.t, rec).Token
+}
+
+func TestOwnerRoutesRequireAuth(t *testing.T) {
+	e := newTestEnv(t)
+	adminToken := e.adminToken()
+	e.createTenant(adminToken, "renter@example.com", "password123")
+	tenantToken := e.tenantToken("renter@example.com", "password123")
+
+	for _, path := range []string{
+		"/api/owner/me",
+		"/api/owner/properties",
+		"/api/owner/occupancy",
+		"/api/owner/leases",
+		"/api/owner/requests",
+		"/api/owner/payments",
+		"/api/owner/statement",
+	} {
+		e.expect(e.do(http.MethodGet, path, "", nil), http.StatusUnauthorized)
+		e.expect(e.do(http.MethodGet, path, adminToken, nil), http.StatusUnauthorized)
+		e.expect(e.do(http.MethodGet, path, tenantToken, nil), http.StatusUnauthorized)
+	}
+}
+
+func TestOwnerPortal(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	maple := e.createOwner(token, "Maple Holdings", "maple@example.com", 8)
+	birch := e.createOwner(token, "Birch Trust", "birch@example.com", 10)
+	if maple.PortalAccess {
+		t.Fatalf("expected no portal access without a password")
+	}
+	e.expect(e.do(http.MethodPost, "/api/owner/login", "", TenantLoginRequest{Email: "maple@example.com", Password: "x"}), http.StatusUnauthorized)
+
+	for _, o := range []Owner{maple, birch} {
+		rec := e.do(http.MethodPut, fmt.Sprintf("/api/admin/owners/%d", o.ID), token, ownerBody{Owner: o, Password: "owner-secret"})
+		e.expect(rec, http.StatusOK)
+		if !decode[Owner](t, rec).PortalAccess {
+			t.Fatalf("expected portal access for %s", o.Name)
+		}
+	}
+	e.expect(e.do(http.MethodPost, "/api/owner/login", "", TenantLoginRequest{Email: "maple@example.com", Password: "wrong"}), http.StatusUnauthorized)
+
+	mapleProperty := e.createListing(token, Property{Name: "Maple Court", OwnerID: &maple.ID})
+	e.createListing(token, Property{Name: "Maple Annex", OwnerID: &maple.ID})
+	e.createListing(token, Property{Name: "Maple Old", OwnerID: &maple.ID, ListingStatus: listingArchived})
+	birchProperty := e.createListing(token, Property{Name: "Birch House", OwnerID: &birch.ID})
+
+	ann := e.createTenant(token, "ann@example.com", "password123")
+	bob := e.createTenant(token, "bob@example.com", "password123")
+	rec := e.createLease(token, mapleProperty.ID, ann, day(-30), day(335))
+	e.expect(rec, http.StatusCreated)
+	lease := decode[Lease](t, rec)
+	e.expect(e.createLease(token, birchProperty.ID, bob, day(-30), day(335)), http.StatusCreated)
+	e.expect(e.do(http.MethodPost, "/api/admin/payments", token, Payment{LeaseID: lease.ID, Amount: 1500, PaymentDate: day(-1)}), http.StatusCreated)
+
+	maple.Notes = strPtr("Slow to approve repairs")
+	e.expect(e.do(http.MethodPut, fmt.Sprintf("/api/admin/owners/%d", maple.ID), token, ownerBody{Owner: maple}), http.StatusOK)
+
+	mapleToken := e.ownerToken("maple@example.com", "owner-secret")
+	rec = e.do(http.MethodGet, "/api/owner/me", mapleToken, nil)
+	e.expect(rec, http.StatusOK)
+	if strings.Contains(rec.Body.String(), "notes") {
+		t.Fatalf("owner sees staff notes: %s", rec.Body)
+	}
+	if me := decode[Owner](t, rec); me.ID != maple.ID {
+		t.Fatalf("unexpected owner %+v", me)
+	}
+	// Owner sessions are not admin or tenant sessions.
+	e.expect(e.do(http.MethodGet, "/api/admin/me", mapleToken, nil), http.StatusUnauthorized)
+	e.expect(e.do(http.MethodGet, "/api/tenant/me", mapleToken, nil), http.StatusUnauthorized)
+
+	count := func(path string) int {
+		t.Helper()
+		rec := e.do(http.MethodGet, path, mapleToken, nil)
+		e.expect(rec, http.StatusOK)
+		return len(decode[[]map[string]interface{}](t, rec))
+	}
+	for path, want := range map[string]int{
+		"/api/owner/properties": 2,
+		"/api/owner/leases":     1,
+		"/api/owner/payments":   1,
+		"/api/owner/requests":   0,
+		// Another owner's ID in the query is ignored.
+		fmt.Sprintf("/api/owner/leases?ownerId=%d", birch.ID):   1,
+		fmt.Sprintf("/api/owner/payments?ownerId=%d", birch.ID): 1,
+	} {
+		if got := count(path); got != want {
+			t.Errorf("%s: expected %d, got %d", path, want, got)
+		}
+	}
+
+	occupancy := decode[OwnerOccupancy](t, e.do(http.MethodGet, "/api/owner/occupancy", mapleToken, nil))
+	if occupancy.TotalUnits != 3 || occupancy.OccupiedUnits != 1 || len(occupancy.Buildings) != 3 {
+		t.Fatalf("unexpected occupancy %+v", occupancy)
+	}
+
+	e.expect(e.do(http.MethodPost, "/api/owner/logout", mapleToken, nil), http.StatusOK)
+	e.expect(e.do(http.MethodGet, "/api/owner/me", mapleToken, nil), http.StatusUnauthorized)
+}
+
+func TestOwnerStatement(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	owner := e.createOwner(token, "Maple Holdings", "maple@example.com", 8)
+	e.expect(e.do(http.MethodPut, fmt.Sprintf("/api/admin/owners/%d", owner.ID), token, ownerBody{Owner: owner, Password: "owner-secret"}), http.StatusOK)
+	court := e.createListing(token, Property{Name: "Maple Court", OwnerID: &owner.ID})
+	annex := e.createListing(token, Property{Name: "Maple Annex", OwnerID: &owner.ID})
+	other := e.createListing(token, Property{Name: "Our Own"})
+
+	tenant := e.createTenant(token, "ann@example.com", "password123")
+	lease := func(propertyID int) int {
+		rec := e.createLease(token, propertyID, tenant, "2026-01-01", "2026-12-31")
+		e.expect(rec, http.StatusCreated)
+		return decode[Lease](t, rec).ID
+	}
+	courtLease, annexLease, otherLease := lease(court.ID), lease(annex.ID), lease(other.ID)
+
+	for _, p := range []Payment{
+		{LeaseID: courtLease, Amount: 1500, PaymentDate: "2026-03-01"},
+		{LeaseID: courtLease, Amount: 50, PaymentDate: "2026-03-08", PaymentType: "late_fee"},
+		{LeaseID: courtLease, Amount: 1500, PaymentDate: "2026-03-01", PaymentType: "deposit"},
+		{LeaseID: courtLease, Amount: 1500, PaymentDate: "2026-02-28"},
+		{LeaseID: annexLease, Amount: 1200, PaymentDate: "2026-03-31"},
+		{LeaseID: annexLease, Amount: 1200, PaymentDate: "2026-03-15", Status: "pending"},
+		{LeaseID: otherLease, Amount: 900, PaymentDate: "2026-03-02"},
+	} {
+		e.expect(e.do(http.MethodPost, "/api/admin/payments", token, p), http.StatusCreated)
+	}
+
+	ownerToken := e.ownerToken("maple@example.com", "owner-secret")
+	rec := e.do(http.MethodGet, "/api/owner/statement?month=2026-03", ownerToken, nil)
+	e.expect(rec, http.StatusOK)
+	s := decode[OwnerStatement](t, rec)
+	if s.PeriodStart != "2026-03-01" || s.PeriodEnd != "2026-03-31" || len(s.Properties) != 2 {
+		t.Fatalf("unexpected statement %+v", s)
+	}
+	// 1550 + 1200 collected, 8% fee.
+	if s.GrossRent != 2750 || s.ManagementFee != 220 || s.MaintenanceExpenses != 0 || s.NetDistribution != 2530 {
+		t.Fatalf("unexpected totals %+v", s)
+	}
+	if line := s.Properties[1]; line.PropertyID != court.ID || line.GrossRent != 1550 || line.ManagementFee != 124 || line.Net != 1426 {
+		t.Fatalf("unexpected line %+v", line)
+	}
+
+	// Admins see the same statement.
+	admin := decode[OwnerStatement](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/owners/%d/statement?month=2026-03", owner.ID), token, nil))
+	if admin.NetDistribution != s.NetDistribution {
+		t.Fatalf("expected the admin statement to match, got %+v", admin)
+	}
+	e.expect(e.do(http.MethodGet, "/api/owner/statement?month=March", ownerToken, nil), http.StatusBadRequest)
+	e.expect(e.do(http.MethodGet, "/api/admin/owners/9999/statement", token, nil), http.StatusNotFound)
+}
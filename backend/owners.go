@@ -0,0 +1,552 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ============================================================================
+// MODELS - OWNERS
+// ============================================================================
+
+// Owner is a landlord whose properties we manage. The payout account number
+// and portal password are write-only: responses carry the account's last
+// four digits and whether the owner can log in to the owner portal. Bank
+// details are encrypted at rest (see sealPayout).
+type Owner struct {
+	ID             int     `json:"id"`
+	Name           string  `json:"name"`
+	Company        *string `json:"company,omitempty"`
+	Email          string  `json:"email"`
+	Phone          *string `json:"phone,omitempty"`
+	MailingAddress *string `json:"mailingAddress,omitempty"`
+	// PayoutMethod is "ach" (needs the routing and account numbers) or
+	// "check" (mailed to MailingAddress).
+	PayoutMethod        string  `json:"payoutMethod"`
+	PayoutAccountName   *string `json:"payoutAccountName,omitempty"`
+	PayoutRoutingNumber *string `json:"payoutRoutingNumber,omitempty"`
+	PayoutAccountLast4  *string `json:"payoutAccountLast4,omitempty"`
+	// ManagementFeePercent is our cut of the rent collected, 0-100.
+	ManagementFeePercent float64   `json:"managementFeePercent"`
+	Notes                *string   `json:"notes,omitempty"`
+	PortalAccess         bool      `json:"portalAccess"`
+	PropertyCount        int       `json:"propertyCount"`
+	CreatedAt            time.Time `json:"createdAt"`
+	UpdatedAt            time.Time `json:"updatedAt"`
+}
+
+// errBadOwnerID is ownerFilter rejecting a malformed ?ownerId=; handlers
+// report it as a 400.
+var errBadOwnerID = errors.New("ownerId must be a number")
+
+// ownerFilter narrows an admin list to one owner's properties when the
+// request has ?ownerId=. column is the list query's property ID column. A
+// value that is not a number is an error rather than ignored, which would
+// quietly widen the list to every owner.
+func ownerFilter(raw, column string) (string, []interface{}, error) {
+	if raw == "" {
+		return "", nil, nil
+	}
+	id, err := strconv.Atoi(raw)
+	if err != nil {
+		return "", nil, errBadOwnerID
+	}
+	return " AND " + column + " IN (SELECT id FROM properties WHERE owner_id = ?)", []interface{}{id}, nil
+}
+
+func ownerExists(id int) bool {
+	var n int
+	db.QueryRow("SELECT COUNT(*) FROM owners WHERE id = ?", id).Scan(&n)
+	return n > 0
+}
+
+// validateOwner checks an owner before it is saved. hasAccount says whether
+// an account number is on file or being set.
+func validateOwner(o *Owner, hasAccount bool) string {
+	o.Name = strings.TrimSpace(o.Name)
+	o.Email = strings.TrimSpace(o.Email)
+	if o.PayoutMethod == "" {
+		o.PayoutMethod = "check"
+	}
+	if o.Name == "" || o.Email == "" {
+		return "Name and email are required"
+	}
+	if _, err := mail.ParseAddress(o.Email); err != nil {
+		return "Invalid email address"
+	}
+	if o.ManagementFeePercent < 0 || o.ManagementFeePercent > 100 {
+		return "Management fee must be between 0 and 100 percent"
+	}
+	switch o.PayoutMethod {
+	case "ach":
+		if o.PayoutRoutingNumber == nil || !allDigits(*o.PayoutRoutingNumber, 9, 9) || !hasAccount {
+			return "ACH payouts need a 9-digit routing number and an account number"
+		}
+	case "check":
+		if o.MailingAddress == nil || strings.TrimSpace(*o.MailingAddress) == "" {
+			return "Check payouts need a mailing address"
+		}
+	default:
+		return "Payout method must be ach or check"
+	}
+	return ""
+}
+
+// allDigits reports whether s is between min and max digits long.
+func allDigits(s string, min, max int) bool {
+	if len(s) < min || len(s) > max {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ============================================================================
+// HANDLERS - ADMIN OWNERS
+// ============================================================================
+
+func adminOwnersHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		getOwners(w)
+	case http.MethodPost:
+		createOwner(w, r)
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func adminOwnerByIDHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+
+	id, action, err := extractIDAndAction(r.URL.Path, "/api/admin/owners/")
+	if err != nil {
+		jsonError(w, "Invalid owner ID", http.StatusBadRequest)
+		return
+	}
+
+	if action == "statement" {
+		if r.Method != http.MethodGet {
+			jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		ownerStatementResponse(w, r, id)
+		return
+	}
+	if action != "" {
+		jsonError(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		getOwnerByID(w, id)
+	case http.MethodPut:
+		updateOwner(w, r, id)
+	case http.MethodDelete:
+		deleteOwner(w, id)
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func getOwners(w http.ResponseWriter) {
+	rows, err := db.Query(`SELECT ` + ownerColumns + ` FROM owners o ORDER BY o.name, o.id`)
+	if err != nil {
+		log.Printf("Error querying owners: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	owners := []Owner{}
+	for rows.Next() {
+		o, err := scanOwner(rows)
+		if err != nil {
+			log.Printf("Error scanning owner: %v", err)
+			continue
+		}
+		owners = append(owners, o)
+	}
+
+	jsonResponse(w, owners, http.StatusOK)
+}
+
+func getOwnerByID(w http.ResponseWriter, id int) {
+	o, err := loadOwner(id)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Owner not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting owner: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, o, http.StatusOK)
+}
+
+// ownerBody is an owner as sent by the admin, with the write-only account
+// number and portal password.
+type ownerBody struct {
+	Owner
+	PayoutAccountNumber string `json:"payoutAccountNumber"`
+	Password            string `json:"password"`
+}
+
+// passwordHash hashes a new portal password; nil when none was sent.
+func (b *ownerBody) passwordHash() (*string, error) {
+	if b.Password == "" {
+		return nil, nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(b.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	h := string(hash)
+	return &h, nil
+}
+
+// sealedPayout encrypts the routing and account numbers for storage.
+// Unset values come back nil and empty.
+func (b *ownerBody) sealedPayout() (routing *string, account, last4 string, err error) {
+	if b.PayoutRoutingNumber != nil {
+		sealed, err := sealPayout(*b.PayoutRoutingNumber)
+		if err != nil {
+			return nil, "", "", err
+		}
+		routing = &sealed
+	}
+	if b.PayoutAccountNumber != "" {
+		if account, err = sealPayout(b.PayoutAccountNumber); err != nil {
+			return nil, "", "", err
+		}
+		last4 = b.PayoutAccountNumber[len(b.PayoutAccountNumber)-4:]
+	}
+	return routing, account, last4, nil
+}
+
+func (b *ownerBody) validate(accountOnFile bool) string {
+	if b.PayoutAccountNumber != "" && !allDigits(b.PayoutAccountNumber, 4, 17) {
+		return "Account number must be 4 to 17 digits"
+	}
+	return validateOwner(&b.Owner, accountOnFile || b.PayoutAccountNumber != "")
+}
+
+func emailTakenByOwner(email string, exceptID int) bool {
+	var n int
+	db.QueryRow("SELECT COUNT(*) FROM owners WHERE email = ? AND id <> ?", email, exceptID).Scan(&n)
+	return n > 0
+}
+
+func createOwner(w http.ResponseWriter, r *http.Request) {
+	var body ownerBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if msg := body.validate(false); msg != "" {
+		jsonError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if emailTakenByOwner(body.Email, 0) {
+		jsonError(w, "An owner with this email already exists", http.StatusConflict)
+		return
+	}
+	o := body.Owner
+	passwordHash, err := body.passwordHash()
+	if err != nil {
+		jsonError(w, "Failed to hash password", http.StatusInternalServerError)
+		return
+	}
+	routing, account, last4, err := body.sealedPayout()
+	if err != nil {
+		log.Printf("Error encrypting payout details: %v", err)
+		jsonError(w, "Failed to create owner", http.StatusInternalServerError)
+		return
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO owners (name, company, email, phone, mailing_address, payout_method,
+			payout_account_name, payout_routing_number, payout_account_number, management_fee_percent, notes,
+			password_hash, payout_account_last4)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?, ?, NULLIF(?, ''))
+	`, o.Name, o.Company, o.Email, o.Phone, o.MailingAddress, o.PayoutMethod,
+		o.PayoutAccountName, routing, account, o.ManagementFeePercent, o.Notes,
+		passwordHash, last4)
+	if err != nil {
+		log.Printf("Error creating owner: %v", err)
+		jsonError(w, "Failed to create owner", http.StatusInternalServerError)
+		return
+	}
+	id, _ := result.LastInsertId()
+
+	o, err = loadOwner(int(id))
+	if err != nil {
+		log.Printf("Error getting owner: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, o, http.StatusCreated)
+}
+
+// updateOwner replaces an owner's details. An omitted account number or
+// password keeps the one on file.
+func updateOwner(w http.ResponseWriter, r *http.Request, id int) {
+	var body ownerBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	var onFile sql.NullString
+	err := db.QueryRow("SELECT payout_account_number FROM owners WHERE id = ?", id).Scan(&onFile)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Owner not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting owner: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if msg := body.validate(onFile.Valid); msg != "" {
+		jsonError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if emailTakenByOwner(body.Email, id) {
+		jsonError(w, "An owner with this email already exists", http.StatusConflict)
+		return
+	}
+	o := body.Owner
+	passwordHash, err := body.passwordHash()
+	if err != nil {
+		jsonError(w, "Failed to hash password", http.StatusInternalServerError)
+		return
+	}
+	routing, account, last4, err := body.sealedPayout()
+	if err != nil {
+		log.Printf("Error encrypting payout details: %v", err)
+		jsonError(w, "Failed to update owner", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = db.Exec(`
+		UPDATE owners SET name=?, company=?, email=?, phone=?, mailing_address=?, payout_method=?,
+			payout_account_name=?, payout_routing_number=?,
+			payout_account_number=COALESCE(NULLIF(?, ''), payout_account_number),
+			payout_account_last4=COALESCE(NULLIF(?, ''), payout_account_last4),
+			management_fee_percent=?, notes=?, password_hash=COALESCE(?, password_hash)
+		WHERE id=?
+	`, o.Name, o.Company, o.Email, o.Phone, o.MailingAddress, o.PayoutMethod,
+		o.PayoutAccountName, routing, account, last4,
+		o.ManagementFeePercent, o.Notes, passwordHash, id)
+	if err != nil {
+		log.Printf("Error updating owner: %v", err)
+		jsonError(w, "Failed to update owner", http.StatusInternalServerError)
+		return
+	}
+
+	getOwnerByID(w, id)
+}
+
+// deleteOwner removes an owner who no longer has any properties, archived
+// ones included.
+func deleteOwner(w http.ResponseWriter, id int) {
+	var propertyCount int
+	db.QueryRow("SELECT COUNT(*) FROM properties WHERE owner_id = ?", id).Scan(&propertyCount)
+	if propertyCount > 0 {
+		jsonError(w, "Cannot delete an owner with properties; reassign them first", http.StatusConflict)
+		return
+	}
+
+	result, err := db.Exec("DELETE FROM owners WHERE id = ?", id)
+	if err != nil {
+		log.Printf("Error deleting owner: %v", err)
+		jsonError(w, "Failed to delete owner", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		jsonError(w, "Owner not found", http.StatusNotFound)
+		return
+	}
+	revokeOwnerSessions(id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ============================================================================
+// SCAN HELPERS - OWNERS
+// ============================================================================
+
+const ownerColumns = `o.id, o.name, o.company, o.email, o.phone, o.mailing_address, o.payout_method,
+	o.payout_account_name, o.payout_routing_number, o.payout_account_last4,
+	o.management_fee_percent, o.notes, o.created_at, o.updated_at, o.password_hash IS NOT NULL,
+	(SELECT COUNT(*) FROM properties p WHERE p.owner_id = o.id AND p.listing_status <> 'archived')`
+
+func loadOwner(id int) (Owner, error) {
+	rows, err := db.Query(`SELECT `+ownerColumns+` FROM owners o WHERE o.id = ?`, id)
+	if err != nil {
+		return Owner{}, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return Owner{}, err
+		}
+		return Owner{}, sql.ErrNoRows
+	}
+	return scanOwner(rows)
+}
+
+func scanOwner(rows *sql.Rows) (Owner, error) {
+	var o Owner
+	var company, phone, address, accountName, routing, last4, notes sql.NullString
+	err := rows.Scan(&o.ID, &o.Name, &company, &o.Email, &phone, &address, &o.PayoutMethod,
+		&accountName, &routing, &last4, &o.ManagementFeePercent, &notes, &o.CreatedAt, &o.UpdatedAt,
+		&o.PortalAccess, &o.PropertyCount)
+	if err != nil {
+		return o, err
+	}
+	if routing.Valid {
+		plain, err := openPayout(routing.String)
+		if err != nil {
+			return o, fmt.Errorf("owner %d routing number: %w", o.ID, err)
+		}
+		routing.String = plain
+	}
+	o.Company = nullStringPtr(company)
+	o.Phone = nullStringPtr(phone)
+	o.MailingAddress = nullStringPtr(address)
+	o.PayoutAccountName = nullStringPtr(accountName)
+	o.PayoutRoutingNumber = nullStringPtr(routing)
+	o.Notes = nullStringPtr(notes)
+	o.PayoutAccountLast4 = nullStringPtr(last4)
+	return o, nil
+}
+
+// ============================================================================
+// PAYOUT DETAILS ENCRYPTION
+// ============================================================================
+
+// Routing and account numbers are stored AES-GCM encrypted under
+// cfg.PayoutEncryptionKey as "v1:" + base64(nonce + ciphertext). Only the
+// account's last four digits are kept in the clear, for display.
+
+const sealedPayoutPrefix = "v1:"
+
+// payoutCipher is set up from cfg.PayoutEncryptionKey at startup.
+var payoutCipher cipher.AEAD
+
+func newPayoutCipher(key string) (cipher.AEAD, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, errors.New("must be base64")
+	}
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("must decode to 32 bytes, got %d", len(raw))
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func sealPayout(plain string) (string, error) {
+	nonce := make([]byte, payoutCipher.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := payoutCipher.Seal(nonce, nonce, []byte(plain), nil)
+	return sealedPayoutPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func openPayout(stored string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, sealedPayoutPrefix))
+	if err != nil || !strings.HasPrefix(stored, sealedPayoutPrefix) || len(raw) < payoutCipher.NonceSize() {
+		return "", errors.New("not an encrypted payout value")
+	}
+	n := payoutCipher.NonceSize()
+	plain, err := payoutCipher.Open(nil, raw[:n], raw[n:], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// encryptPlaintextPayouts encrypts bank details saved before they were
+// encrypted at rest. It runs at startup and does nothing once every row is
+// encrypted.
+func encryptPlaintextPayouts() error {
+	rows, err := db.Query(`
+		SELECT id, payout_routing_number, payout_account_number FROM owners
+		WHERE payout_routing_number NOT LIKE 'v1:%' OR payout_account_number NOT LIKE 'v1:%'
+	`)
+	if err != nil {
+		return err
+	}
+	type plaintext struct {
+		id               int
+		routing, account sql.NullString
+	}
+	var pending []plaintext
+	for rows.Next() {
+		var p plaintext
+		if err := rows.Scan(&p.id, &p.routing, &p.account); err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, p := range pending {
+		for _, v := range []*sql.NullString{&p.routing, &p.account} {
+			if !v.Valid || strings.HasPrefix(v.String, sealedPayoutPrefix) {
+				continue
+			}
+			sealed, err := sealPayout(v.String)
+			if err != nil {
+				return err
+			}
+			v.String = sealed
+		}
+		if _, err := db.Exec("UPDATE owners SET payout_routing_number = ?, payout_account_number = ? WHERE id = ?",
+			p.routing, p.account, p.id); err != nil {
+			return err
+		}
+	}
+	if len(pending) > 0 {
+		log.Printf("Encrypted payout details for %d owners", len(pending))
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// createOwner creates an owner paid by check and returns it.
+func (e *testEnv) createOwner(token, name, email string, feePercent float64) Owner {
+	e.t.Helper()
+	rec := e.do(http.MethodPost, "/api/admin/owners", token, Owner{
+		Name:                 name,
+		Email:                email,
+		MailingAddress:       strPtr("1 Owner Way, Portland, OR 97201"),
+		ManagementFeePercent: feePercent,
+	})
+	e.expect(rec, http.StatusCreated)
+	return decode[Owner](e.t, rec)
+}
+
+func TestOwnerCRUD(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	for _, bad := range []map[string]interface{}{
+		{"email": "no-name@example.com", "mailingAddress": "x"},
+		{"name": "Bad Email", "email": "nope", "mailingAddress": "x"},
+		{"name": "Greedy", "email": "g@example.com", "mailingAddress": "x", "managementFeePercent": 120},
+		{"name": "No Address", "email": "n@example.com"},
+		{"name": "No Account", "email": "a@example.com", "payoutMethod": "ach", "payoutRoutingNumber": "123456789"},
+		{"name": "Short Routing", "email": "a@example.com", "payoutMethod": "ach", "payoutRoutingNumber": "1234", "payoutAccountNumber": "12345678"},
+		{"name": "Wire", "email": "w@example.com", "payoutMethod": "wire"},
+	} {
+		e.expect(e.do(http.MethodPost, "/api/admin/owners", token, bad), http.StatusBadRequest)
+	}
+
+	rec := e.do(http.MethodPost, "/api/admin/owners", token, map[string]interface{}{
+		"name":                 "Maple Holdings",
+		"email":                "maple@example.com",
+		"payoutMethod":         "ach",
+		"payoutAccountName":    "Maple Holdings LLC",
+		"payoutRoutingNumber":  "123456789",
+		"payoutAccountNumber":  "000123456789",
+		"managementFeePercent": 8.5,
+	})
+	e.expect(rec, http.StatusCreated)
+	owner := decode[Owner](t, rec)
+	if owner.PayoutAccountLast4 == nil || *owner.PayoutAccountLast4 != "6789" || owner.ManagementFeePercent != 8.5 {
+		t.Fatalf("unexpected owner %+v", owner)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "000123456789") {
+		t.Fatalf("account number leaked: %s", body)
+	}
+	// Bank details are encrypted at rest.
+	var routing, account string
+	db.QueryRow("SELECT payout_routing_number, payout_account_number FROM owners WHERE id = ?", owner.ID).Scan(&routing, &account)
+	if !strings.HasPrefix(routing, "v1:") || !strings.HasPrefix(account, "v1:") || strings.Contains(account, "6789") {
+		t.Fatalf("expected encrypted payout details, got %q %q", routing, account)
+	}
+	if owner.PayoutRoutingNumber == nil || *owner.PayoutRoutingNumber != "123456789" {
+		t.Fatalf("expected the routing number back, got %v", owner.PayoutRoutingNumber)
+	}
+	e.expect(e.do(http.MethodPost, "/api/admin/owners", token, map[string]interface{}{
+		"name": "Copy", "email": "maple@example.com", "mailingAddress": "x",
+	}), http.StatusConflict)
+
+	// The account number on file is kept when omitted.
+	path := fmt.Sprintf("/api/admin/owners/%d", owner.ID)
+	owner.ManagementFeePercent = 10
+	rec = e.do(http.MethodPut, path, token, owner)
+	e.expect(rec, http.StatusOK)
+	if got := decode[Owner](t, rec); got.ManagementFeePercent != 10 || got.PayoutAccountLast4 == nil || *got.PayoutAccountLast4 != "6789" {
+		t.Fatalf("unexpected updated owner %+v", got)
+	}
+
+	pid := e.createListing(token, Property{Name: "Maple Court", OwnerID: &owner.ID}).ID
+	if got := decode[Owner](t, e.do(http.MethodGet, path, token, nil)); got.PropertyCount != 1 {
+		t.Fatalf("expected one property, got %+v", got)
+	}
+	e.expect(e.do(http.MethodDelete, path, token, nil), http.StatusConflict)
+
+	// Anonymous visitors don't learn who owns what.
+	for _, public := range []string{"/api/properties", fmt.Sprintf("/api/properties/%d", pid)} {
+		rec := e.do(http.MethodGet, public, "", nil)
+		e.expect(rec, http.StatusOK)
+		for _, field := range []string{`"ownerId"`, `"listingStatus"`, `"syndicate"`} {
+			if strings.Contains(rec.Body.String(), field) {
+				t.Fatalf("%s exposes %s: %s", public, field, rec.Body.String())
+			}
+		}
+	}
+
+	// Editing without ownerId keeps the owner; 0 unlinks.
+	p := decode[Property](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/properties/%d", pid), token, nil))
+	p.OwnerID = nil
+	if got := decode[Property](t, e.do(http.MethodPut, fmt.Sprintf("/api/admin/properties/%d", pid), token, p)); got.OwnerID == nil || *got.OwnerID != owner.ID {
+		t.Fatalf("expected the owner to be kept, got %v", got.OwnerID)
+	}
+	missing := 9999
+	p.OwnerID = &missing
+	e.expect(e.do(http.MethodPut, fmt.Sprintf("/api/admin/properties/%d", pid), token, p), http.StatusBadRequest)
+	none := 0
+	p.OwnerID = &none
+	if got := decode[Property](t, e.do(http.MethodPut, fmt.Sprintf("/api/admin/properties/%d", pid), token, p)); got.OwnerID != nil {
+		t.Fatalf("expected the owner to be unlinked, got %v", *got.OwnerID)
+	}
+
+	e.expect(e.do(http.MethodDelete, path, token, nil), http.StatusNoContent)
+	e.expect(e.do(http.MethodGet, path, token, nil), http.StatusNotFound)
+}
+
+func TestEncryptPlaintextPayouts(t *testing.T) {
+	e := newTestEnv(t)
+	db.Exec(`INSERT INTO owners (name, email, payout_method, payout_routing_number, payout_account_number, payout_account_last4)
+		VALUES ('Old Oak', 'oak@example.com', 'ach', '123456789', '000123456789', '6789')`)
+	if err := encryptPlaintextPayouts(); err != nil {
+		t.Fatalf("encryptPlaintextPayouts: %v", err)
+	}
+	var routing, account string
+	db.QueryRow("SELECT payout_routing_number, payout_account_number FROM owners WHERE email = 'oak@example.com'").Scan(&routing, &account)
+	if plain, err := openPayout(account); err != nil || plain != "000123456789" || !strings.HasPrefix(routing, "v1:") {
+		t.Fatalf("expected the legacy values encrypted, got %q %q (%v)", routing, account, err)
+	}
+	// Already encrypted values are left alone.
+	if err := encryptPlaintextPayouts(); err != nil {
+		t.Fatalf("encryptPlaintextPayouts: %v", err)
+	}
+	var again string
+	db.QueryRow("SELECT payout_account_number FROM owners WHERE email = 'oak@example.com'").Scan(&again)
+	if again != account {
+		t.Fatal("expected a second run to change nothing")
+	}
+
+	owners := decode[[]Owner](t, e.do(http.MethodGet, "/api/admin/owners", e.adminToken(), nil))
+	if len(owners) != 1 || *owners[0].PayoutRoutingNumber != "123456789" || *owners[0].PayoutAccountLast4 != "6789" {
+		t.Fatalf("unexpected owners %+v", owners)
+	}
+}
+
+func TestOwnerFilters(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	maple := e.createOwner(token, "Maple Holdings", "maple@example.com", 8)
+	birch := e.createOwner(token, "Birch Trust", "birch@example.com", 10)
+	mapleProperty := e.createListing(token, Property{Name: "Maple Court", OwnerID: &maple.ID})
+	birchProperty := e.createListing(token, Property{Name: "Birch House", OwnerID: &birch.ID})
+	e.createListing(token, Property{Name: "Our Own"})
+
+	mapleTenant := e.createTenant(token, "ann@example.com", "password123")
+	birchTenant := e.createTenant(token, "bob@example.com", "password123")
+	rec := e.createLease(token, mapleProperty.ID, mapleTenant, day(-30), day(335))
+	e.expect(rec, http.StatusCreated)
+	lease := decode[Lease](t, rec)
+	e.expect(e.createLease(token, birchProperty.ID, birchTenant, day(10), day(375)), http.StatusCreated)
+	e.expect(e.do(http.MethodPost, "/api/admin/payments", token, Payment{LeaseID: lease.ID, Amount: 1500, PaymentDate: day(-1)}), http.StatusCreated)
+
+	count := func(path string) int {
+		t.Helper()
+		rec := e.do(http.MethodGet, path, token, nil)
+		e.expect(rec, http.StatusOK)
+		return len(decode[[]map[string]interface{}](t, rec))
+	}
+	for path, want := range map[string]int{
+		"/api/admin/properties":                                     3,
+		fmt.Sprintf("/api/admin/properties?ownerId=%d", maple.ID):   1,
+		fmt.Sprintf("/api/admin/tenants?ownerId=%d", maple.ID):      1,
+		fmt.Sprintf("/api/admin/leases?ownerId=%d", birch.ID):       1,
+		fmt.Sprintf("/api/admin/payments?ownerId=%d", maple.ID):     1,
+		fmt.Sprintf("/api/admin/payments?ownerId=%d", birch.ID):     0,
+		fmt.Sprintf("/api/admin/requests?ownerId=%d", maple.ID):     0,
+		fmt.Sprintf("/api/admin/leads?ownerId=%d", maple.ID):        0,
+		fmt.Sprintf("/api/admin/applications?ownerId=%d", maple.ID): 0,
+		fmt.Sprintf("/api/admin/showings?ownerId=%d", maple.ID):     0,
+	} {
+		if got := count(path); got != want {
+			t.Errorf("%s: expected %d, got %d", path, want, got)
+		}
+	}
+
+	stats := decode[DashboardStats](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/dashboard/stats?ownerId=%d", maple.ID), token, nil))
+	if stats.TotalProperties != 1 || stats.TotalTenants != 1 || stats.ActiveLeases != 1 || stats.UpcomingLeases != 0 ||
+		stats.MonthlyRevenue != 1500 || len(stats.Buildings) != 1 || stats.OccupiedUnits != 1 {
+		t.Fatalf("unexpected owner stats %+v", stats)
+	}
+	all := decode[DashboardStats](t, e.do(http.MethodGet, "/api/admin/dashboard/stats", token, nil))
+	if all.TotalProperties != 3 || all.TotalTenants != 2 || all.UpcomingLeases != 1 {
+		t.Fatalf("unexpected portfolio stats %+v", all)
+	}
+
+	// A malformed ownerId is rejected rather than ignored, which would
+	// answer with the whole portfolio's figures.
+	for _, path := range []string{
+		"/api/admin/dashboard/stats", "/api/admin/properties", "/api/admin/tenants", "/api/admin/leases",
+		"/api/admin/payments", "/api/admin/requests", "/api/admin/leads", "/api/admin/applications",
+		"/api/admin/showings", "/api/admin/showings.ics", "/api/admin/expenses", "/api/admin/reports/vacancy",
+		"/api/admin/reports/pnl?from=2026-01-01&to=2026-01-31", "/api/admin/reports/rent-roll", "/api/admin/reports/receivables",
+	} {
+		sep := "?"
+		if strings.Contains(path, "?") {
+			sep = "&"
+		}
+		e.expect(e.do(http.MethodGet, path+sep+"ownerId=abc", token, nil), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// RATE LIMITING
+// ============================================================================
+
+// rateLimiter counts hits per key in fixed windows. It is in-memory, so
+// limits are per process and reset on restart; that is enough to blunt form
+// spam without another moving part.
+type rateLimiter struct {
+	mu      sync.Mutex
+	windows map[string]rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{windows: make(map[string]rateWindow)}
+}
+
+// allow records a hit for key and reports whether it is within limit. When it
+// is not, retryAfter says how long until the window resets.
+func (l *rateLimiter) allow(key string, limit int, window time.Duration, now time.Time) (ok bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop expired windows once the map grows, so one-off visitors do not
+	// accumulate forever.
+	if len(l.windows) > 10000 {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= window {
+				delete(l.windows, k)
+			}
+		}
+	}
+
+	w := l.windows[key]
+	if now.Sub(w.start) >= window {
+		w = rateWindow{start: now}
+	}
+	if w.count >= limit {
+		return false, w.start.Add(window).Sub(now)
+	}
+	w.count++
+	l.windows[key] = w
+	return true, 0
+}
+
+// clientIP returns the caller's address, honouring X-Forwarded-For only when
+// cfg.TrustProxy is set.
+func clientIP(r *http.Request) string {
+	if cfg.TrustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			first, _, _ := strings.Cut(fwd, ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ============================================================================
+// AGED RECEIVABLES REPORT
+// ============================================================================
+
+// Rent falls due on each lease's PaymentDueDay (see rentDueDates). Completed
+// rent payments settle the oldest charges first; whatever is left is aged
+// by how many days past its due date it is on the as-of date. Drafts and
+// leases in the trash are left out, but ended leases are kept so former
+// tenants who still owe are listed. Late fees aren't charged by the system,
+// so only rent is aged.
+
+// Aging buckets an amount owed by days past due.
+type Aging struct {
+	Current    float64 `json:"current"`
+	Days1To30  float64 `json:"days1To30"`
+	Days31To60 float64 `json:"days31To60"`
+	Days61To90 float64 `json:"days61To90"`
+	Over90     float64 `json:"over90"`
+	Total      float64 `json:"total"`
+}
+
+func (a *Aging) addDue(amount float64, daysPastDue int) {
+	switch {
+	case daysPastDue <= 0:
+		a.Current += amount
+	case daysPastDue <= 30:
+		a.Days1To30 += amount
+	case daysPastDue <= 60:
+		a.Days31To60 += amount
+	case daysPastDue <= 90:
+		a.Days61To90 += amount
+	default:
+		a.Over90 += amount
+	}
+	a.Total += amount
+}
+
+func (a *Aging) add(b Aging) {
+	a.Current += b.Current
+	a.Days1To30 += b.Days1To30
+	a.Days31To60 += b.Days31To60
+	a.Days61To90 += b.Days61To90
+	a.Over90 += b.Over90
+	a.Total += b.Total
+}
+
+func (a *Aging) round() {
+	for _, x := range []*float64{&a.Current, &a.Days1To30, &a.Days31To60, &a.Days61To90, &a.Over90, &a.Total} {
+		*x = roundCents(*x)
+	}
+}
+
+// LeaseReceivable is a lease with rent outstanding.
+type LeaseReceivable struct {
+	LeaseID       int     `json:"leaseId"`
+	LeaseStatus   string  `json:"leaseStatus"`
+	PropertyID    int     `json:"propertyId"`
+	PropertyName  string  `json:"propertyName"`
+	UnitNumber    *string `json:"unitNumber,omitempty"`
+	TenantID      int     `json:"tenantId"`
+	TenantName    string  `json:"tenantName"`
+	TenantEmail   string  `json:"tenantEmail"`
+	TenantPhone   *string `json:"tenantPhone,omitempty"`
+	MonthlyRent   float64 `json:"monthlyRent"`
+	PaymentDueDay int     `json:"paymentDueDay"`
+	// OldestDueDate is the due date of the oldest unpaid charge.
+	OldestDueDate string `json:"oldestDueDate"`
+	DaysPastDue   int    `json:"daysPastDue"`
+	Aging
+}
+
+// PropertyReceivables totals a property's outstanding rent.
+type PropertyReceivables struct {
+	PropertyID   int    `json:"propertyId"`
+	PropertyName string `json:"propertyName"`
+	Aging
+}
+
+// TenantReceivables totals a tenant's outstanding rent across leases.
+type TenantReceivables struct {
+	TenantID   int    `json:"tenantId"`
+	TenantName string `json:"tenantName"`
+	Aging
+}
+
+// ReceivablesReport is the response of GET /api/admin/reports/receivables.
+type ReceivablesReport struct {
+	AsOf       string                `json:"asOf"`
+	Totals     Aging                 `json:"totals"`
+	Leases     []LeaseReceivable     `json:"leases"`
+	Properties []PropertyReceivables `json:"properties"`
+	Tenants    []TenantReceivables   `json:"tenants"`
+}
+
+func receivablesReportHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	asOf := time.Now().Format("2006-01-02")
+	if raw := q.Get("asOf"); raw != "" {
+		asOf = raw
+	}
+	if _, err := time.Parse("2006-01-02", asOf); err != nil {
+		jsonError(w, "asOf must be YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	report, err := buildReceivablesReport(asOf, q)
+	if err != nil {
+		if errors.Is(err, errBadOwnerID) {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error building receivables report: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, report, http.StatusOK)
+}
+
+// buildReceivablesReport ages every lease's unpaid rent as of asOf. The
+// leases, properties and tenants are each listed largest balance first.
+// Supports propertyId, tenantId and ownerId filters.
+func buildReceivablesReport(asOf string, filters url.Values) (ReceivablesReport, error) {
+	report := ReceivablesReport{
+		AsOf:       asOf,
+		Leases:     []LeaseReceivable{},
+		Properties: []PropertyReceivables{},
+		Tenants:    []TenantReceivables{},
+	}
+	asOfDate, _ := time.Parse("2006-01-02", asOf)
+
+	query := `
+		SELECT l.id, l.status, l.property_id, p.name, u.unit_number,
+			   l.tenant_id, CONCAT(t.first_name, ' ', t.last_name), t.email, t.phone,
+			   l.start_date, l.end_date, l.monthly_rent, l.payment_due_day,
+			   (SELECT COALESCE(SUM(pay.amount), 0) FROM payments pay
+				WHERE pay.lease_id = l.id AND pay.deleted_at IS NULL AND pay.status = 'completed'
+				AND pay.payment_type = 'rent' AND pay.payment_date <= ?)
+		FROM leases l
+		JOIN properties p ON p.id = l.property_id
+		JOIN tenants t ON t.id = l.tenant_id
+		LEFT JOIN units u ON u.id = l.unit_id
+		WHERE l.deleted_at IS NULL AND l.status <> 'draft' AND l.start_date <= ?
+	`
+	args := []interface{}{asOf, asOf}
+	for param, column := range map[string]string{"propertyId": "l.property_id", "tenantId": "l.tenant_id"} {
+		if id, err := strconv.Atoi(filters.Get(param)); err == nil {
+			query += " AND " + column + " = ?"
+			args = append(args, id)
+		}
+	}
+	clause, ownerArgs, err := ownerFilter(filters.Get("ownerId"), "l.property_id")
+	if err != nil {
+		return report, err
+	}
+	query += clause
+	args = append(args, ownerArgs...)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lr LeaseReceivable
+		var unitNumber, phone sql.NullString
+		var start, end string
+		var paid float64
+		if err := rows.Scan(&lr.LeaseID, &lr.LeaseStatus, &lr.PropertyID, &lr.PropertyName, &unitNumber,
+			&lr.TenantID, &lr.TenantName, &lr.TenantEmail, &phone,
+			&start, &end, &lr.MonthlyRent, &lr.PaymentDueDay, &paid); err != nil {
+			return report, err
+		}
+		startDate, err1 := time.Parse("2006-01-02", dateOnly(start))
+		endDate, err2 := time.Parse("2006-01-02", dateOnly(end))
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		lr.UnitNumber = nullStringPtr(unitNumber)
+		lr.TenantPhone = nullStringPtr(phone)
+
+		// Payments settle the oldest charges first.
+		for _, due := range rentDueDates(startDate, endDate, asOfDate, lr.PaymentDueDay) {
+			owed := lr.MonthlyRent
+			applied := min(paid, owed)
+			paid -= applied
+			owed = roundCents(owed - applied)
+			if owed <= 0 {
+				continue
+			}
+			days := int(asOfDate.Sub(due).Hours() / 24)
+			if lr.OldestDueDate == "" {
+				lr.OldestDueDate = due.Format("2006-01-02")
+				lr.DaysPastDue = days
+			}
+			lr.addDue(owed, days)
+		}
+		if lr.Total <= 0 {
+			continue
+		}
+		lr.round()
+		report.Leases = append(report.Leases, lr)
+	}
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	properties := map[int]*PropertyReceivables{}
+	tenants := map[int]*TenantReceivables{}
+	for _, lr := range report.Leases {
+		if properties[lr.PropertyID] == nil {
+			properties[lr.PropertyID] = &PropertyReceivables{PropertyID: lr.PropertyID, PropertyName: lr.PropertyName}
+		}
+		properties[lr.PropertyID].add(lr.Aging)
+		if tenants[lr.TenantID] == nil {
+			tenants[lr.TenantID] = &TenantReceivables{TenantID: lr.TenantID, TenantName: lr.TenantName}
+		}
+		tenants[lr.TenantID].add(lr.Aging)
+		report.Totals.add(lr.Aging)
+	}
+	report.Totals.round()
+	for _, p := range properties {
+		p.round()
+		report.Properties = append(report.Properties, *p)
+	}
+	for _, t := range tenants {
+		t.round()
+		report.Tenants = append(report.Tenants, *t)
+	}
+
+	sort.SliceStable(report.Leases, func(i, j int) bool {
+		a, b := report.Leases[i], report.Leases[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.LeaseID < b.LeaseID
+	})
+	sort.Slice(report.Properties, func(i, j int) bool {
+		a, b := report.Properties[i], report.Properties[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.PropertyID < b.PropertyID
+	})
+	sort.Slice(report.Tenants, func(i, j int) bool {
+		a, b := report.Tenants[i], report.Tenants[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.TenantID < b.TenantID
+	})
+	return report, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestReceivablesReport(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	owner := e.createOwner(token, "Maple Holdings", "maple@example.com", 8)
+	house := e.createListing(token, Property{Name: "Aspen House", OwnerID: &owner.ID})
+	cottage := e.createListing(token, Property{Name: "Birch Cottage"})
+	building := e.createBuilding(token, "Cedar Flats", fourplexUnits())
+	ann := e.createTenant(token, "ann@example.com", "password123")
+	bob := e.createTenant(token, "bob@example.com", "password123")
+
+	lease := func(propertyID, tenantID int, unitID *int, start, end string, rent float64, dueDay int) int {
+		t.Helper()
+		rec := e.do(http.MethodPost, "/api/admin/leases", token, Lease{
+			PropertyID: propertyID, UnitID: unitID, TenantID: tenantID,
+			StartDate: start, EndDate: end, MonthlyRent: rent, PaymentDueDay: dueDay,
+		})
+		e.expect(rec, http.StatusCreated)
+		return decode[Lease](t, rec).ID
+	}
+	pay := func(leaseID int, amount float64, date string) {
+		t.Helper()
+		e.expect(e.do(http.MethodPost, "/api/admin/payments", token, Payment{LeaseID: leaseID, Amount: amount, PaymentDate: date}), http.StatusCreated)
+	}
+
+	// Five months due by May 15, two and a half paid.
+	houseLease := lease(house.ID, ann, nil, "2026-01-01", "2026-12-31", 1000, 1)
+	pay(houseLease, 1000, "2026-01-02")
+	pay(houseLease, 1500, "2026-03-10")
+	e.expect(e.do(http.MethodPost, "/api/admin/payments", token, Payment{LeaseID: houseLease, Amount: 1000, PaymentDate: "2026-04-01", Status: "pending"}), http.StatusCreated)
+	// After the report date.
+	pay(houseLease, 1000, "2026-05-20")
+
+	// An ended lease with January unpaid.
+	cottageLease := lease(cottage.ID, bob, nil, "2025-10-01", "2026-01-31", 800, 1)
+	pay(cottageLease, 2400, "2025-12-01")
+
+	// Due on the report date.
+	lease(building.ID, ann, &building.Units[0].ID, "2026-05-15", "2027-05-14", 1200, 15)
+	// Paid up.
+	paid := lease(building.ID, bob, &building.Units[1].ID, "2026-04-01", "2027-03-31", 900, 1)
+	pay(paid, 1800, "2026-05-01")
+
+	rec := e.do(http.MethodGet, "/api/admin/reports/receivables?asOf=2026-05-15", token, nil)
+	e.expect(rec, http.StatusOK)
+	report := decode[ReceivablesReport](t, rec)
+
+	want := Aging{Current: 1200, Days1To30: 1000, Days31To60: 1000, Days61To90: 500, Over90: 800, Total: 4500}
+	if report.Totals != want {
+		t.Fatalf("unexpected totals %+v", report.Totals)
+	}
+	if len(report.Leases) != 3 {
+		t.Fatalf("expected three leases in arrears, got %+v", report.Leases)
+	}
+	h := report.Leases[0]
+	if h.LeaseID != houseLease || h.OldestDueDate != "2026-03-01" || h.DaysPastDue != 75 ||
+		h.Aging != (Aging{Days1To30: 1000, Days31To60: 1000, Days61To90: 500, Total: 2500}) {
+		t.Fatalf("unexpected house lease %+v", h)
+	}
+	if c := report.Leases[2]; c.LeaseID != cottageLease || c.LeaseStatus != "ended" || c.Over90 != 800 || c.TenantEmail != "bob@example.com" {
+		t.Fatalf("unexpected cottage lease %+v", c)
+	}
+
+	if len(report.Tenants) != 2 || report.Tenants[0].TenantID != ann || report.Tenants[0].Total != 3700 || report.Tenants[1].Total != 800 {
+		t.Fatalf("unexpected tenant totals %+v", report.Tenants)
+	}
+	if len(report.Properties) != 3 || report.Properties[0].PropertyID != house.ID || report.Properties[1].PropertyID != building.ID {
+		t.Fatalf("unexpected property totals %+v", report.Properties)
+	}
+
+	owned := decode[ReceivablesReport](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/reports/receivables?asOf=2026-05-15&ownerId=%d", owner.ID), token, nil))
+	if len(owned.Leases) != 1 || owned.Totals.Total != 2500 {
+		t.Fatalf("unexpected owner receivables %+v", owned)
+	}
+	mine := decode[ReceivablesReport](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/reports/receivables?asOf=2026-05-15&tenantId=%d", bob), token, nil))
+	if mine.Totals.Total != 800 {
+		t.Fatalf("unexpected tenant receivables %+v", mine)
+	}
+	e.expect(e.do(http.MethodGet, "/api/admin/reports/receivables?asOf=yesterday", token, nil), http.StatusBadRequest)
+}
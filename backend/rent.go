@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// RENT HISTORY
+// ============================================================================
+
+// Every change to a property's asking rent (properties.monthly_rent) is
+// recorded in rent_history with the rent it replaced. Admins can also
+// schedule a change for a future date on a single-unit property; the
+// rent-changes job applies it once the date arrives. Multi-unit buildings
+// are priced per unit, so their history follows the unit rollup.
+const (
+	rentChangeScheduled = "scheduled"
+	rentChangeApplied   = "applied"
+	rentChangeSkipped   = "skipped" // came due after the property gained units
+)
+
+// RentChange is one entry in a property's rent history.
+type RentChange struct {
+	ID            int        `json:"id"`
+	PropertyID    int        `json:"propertyId"`
+	EffectiveDate string     `json:"effectiveDate"`
+	MonthlyRent   float64    `json:"monthlyRent"`
+	PreviousRent  *float64   `json:"previousRent,omitempty"`
+	Reason        string     `json:"reason"`
+	Status        string     `json:"status"`
+	AppliedAt     *time.Time `json:"appliedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// RentHistory is the response of GET /api/admin/properties/:id/rent-history.
+type RentHistory struct {
+	PropertyID  int     `json:"propertyId"`
+	CurrentRent float64 `json:"currentRent"`
+	// History is newest first; Scheduled is soonest first.
+	History   []RentChange `json:"history"`
+	Scheduled []RentChange `json:"scheduled"`
+}
+
+const rentChangeColumns = `id, property_id, effective_date, monthly_rent, previous_rent,
+	reason, status, applied_at, created_at`
+
+func scanRentChange(rows *sql.Rows) (RentChange, error) {
+	var c RentChange
+	var previous sql.NullFloat64
+	var appliedAt sql.NullTime
+	err := rows.Scan(&c.ID, &c.PropertyID, &c.EffectiveDate, &c.MonthlyRent, &previous,
+		&c.Reason, &c.Status, &appliedAt, &c.CreatedAt)
+	c.EffectiveDate = dateOnly(c.EffectiveDate)
+	if previous.Valid {
+		c.PreviousRent = &previous.Float64
+	}
+	if appliedAt.Valid {
+		c.AppliedAt = &appliedAt.Time
+	}
+	return c, err
+}
+
+// recordRentChange adds a history entry when the property's asking rent no
+// longer matches the last one recorded. Call it after syncPropertyFromUnits,
+// in the same transaction as the change.
+func recordRentChange(ex sqlExecutor, propertyID int, reason string) error {
+	var rent float64
+	if err := ex.QueryRow("SELECT monthly_rent FROM properties WHERE id = ?", propertyID).Scan(&rent); err != nil {
+		return err
+	}
+
+	var previous sql.NullFloat64
+	err := ex.QueryRow(`
+		SELECT monthly_rent FROM rent_history
+		WHERE property_id = ? AND status = ?
+		ORDER BY applied_at DESC, id DESC LIMIT 1
+	`, propertyID, rentChangeApplied).Scan(&previous)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if previous.Valid && previous.Float64 == rent {
+		return nil
+	}
+
+	_, err = ex.Exec(`
+		INSERT INTO rent_history (property_id, effective_date, monthly_rent, previous_rent, reason, status, applied_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, propertyID, time.Now().Format("2006-01-02"), rent, previous, reason, rentChangeApplied, time.Now().UTC())
+	return err
+}
+
+// applyScheduledRentChanges applies scheduled rent changes whose effective
+// date has arrived. Runs as a background job.
+func applyScheduledRentChanges(ctx context.Context) error {
+	today := time.Now().Format("2006-01-02")
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, property_id, monthly_rent FROM rent_history
+		WHERE status = ? AND effective_date <= ?
+		ORDER BY effective_date, id
+	`, rentChangeScheduled, today)
+	if err != nil {
+		return fmt.Errorf("loading due rent changes: %w", err)
+	}
+	type dueChange struct {
+		id, propertyID int
+		rent           float64
+	}
+	var due []dueChange
+	for rows.Next() {
+		var c dueChange
+		if err := rows.Scan(&c.id, &c.propertyID, &c.rent); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning rent change: %w", err)
+		}
+		due = append(due, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("loading due rent changes: %w", err)
+	}
+
+	for _, c := range due {
+		if err := applyRentChange(ctx, c.id, c.propertyID, c.rent); err != nil {
+			return fmt.Errorf("applying rent change %d: %w", c.id, err)
+		}
+	}
+	return nil
+}
+
+// applyRentChange sets the rent of a single-unit property's unit and marks
+// the scheduled change applied. A property that has gained units since the
+// change was scheduled is left alone and the change is marked skipped.
+func applyRentChange(ctx context.Context, changeID, propertyID int, rent float64) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var unitCount int
+	var previous float64
+	err = tx.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM units WHERE property_id = ?), monthly_rent
+		FROM properties WHERE id = ?
+	`, propertyID, propertyID).Scan(&unitCount, &previous)
+	if err != nil {
+		return err
+	}
+
+	if unitCount != 1 {
+		log.Printf("Skipping rent change %d: property %d now has %d units", changeID, propertyID, unitCount)
+		_, err = tx.Exec("UPDATE rent_history SET status = ? WHERE id = ?", rentChangeSkipped, changeID)
+	} else {
+		_, err = tx.Exec("UPDATE units SET monthly_rent = ? WHERE property_id = ?", rent, propertyID)
+		if err == nil {
+			err = syncPropertyFromUnits(tx, propertyID)
+		}
+		if err == nil {
+			_, err = tx.Exec("UPDATE rent_history SET status = ?, previous_rent = ?, applied_at = ? WHERE id = ?",
+				rentChangeApplied, previous, time.Now().UTC(), changeID)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ============================================================================
+// HANDLERS - ADMIN RENT HISTORY
+// ============================================================================
+
+// propertyRentHistoryHandler serves /api/admin/properties/:id/rent-history
+// and /api/admin/properties/:id/rent-history/:changeId. rest is the part of
+// the path after "rent-history".
+func propertyRentHistoryHandler(w http.ResponseWriter, r *http.Request, propertyID int, rest string) {
+	var exists int
+	db.QueryRow("SELECT COUNT(*) FROM properties WHERE id = ?", propertyID).Scan(&exists)
+	if exists == 0 {
+		jsonError(w, "Property not found", http.StatusNotFound)
+		return
+	}
+
+	if rest == "" {
+		switch r.Method {
+		case http.MethodGet:
+			getRentHistory(w, propertyID)
+		case http.MethodPost:
+			scheduleRentChange(w, r, propertyID)
+		default:
+			jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	changeID, err := strconv.Atoi(rest)
+	if err != nil {
+		jsonError(w, "Invalid rent change ID", http.StatusBadRequest)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cancelRentChange(w, propertyID, changeID)
+}
+
+func getRentHistory(w http.ResponseWriter, propertyID int) {
+	history := RentHistory{PropertyID: propertyID, History: []RentChange{}, Scheduled: []RentChange{}}
+	if err := db.QueryRow("SELECT monthly_rent FROM properties WHERE id = ?", propertyID).Scan(&history.CurrentRent); err != nil {
+		log.Printf("Error getting property rent: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := db.Query(`SELECT `+rentChangeColumns+` FROM rent_history
+		WHERE property_id = ?
+		ORDER BY effective_date DESC, id DESC
+	`, propertyID)
+	if err != nil {
+		log.Printf("Error querying rent history: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c, err := scanRentChange(rows)
+		if err != nil {
+			log.Printf("Error scanning rent change: %v", err)
+			continue
+		}
+		if c.Status == rentChangeScheduled {
+			history.Scheduled = append([]RentChange{c}, history.Scheduled...)
+		} else {
+			history.History = append(history.History, c)
+		}
+	}
+
+	jsonResponse(w, history, http.StatusOK)
+}
+
+// scheduleRentChange queues a future asking-rent change. Changes that take
+// effect today are made by editing the property.
+func scheduleRentChange(w http.ResponseWriter, r *http.Request, propertyID int) {
+	var c RentChange
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	c.Reason = strings.TrimSpace(c.Reason)
+	if c.Reason == "" {
+		c.Reason = "Scheduled change"
+	}
+	effective, err := time.Parse("2006-01-02", c.EffectiveDate)
+	switch {
+	case c.MonthlyRent <= 0:
+		jsonError(w, "Monthly rent must be positive", http.StatusBadRequest)
+		return
+	case err != nil:
+		jsonError(w, "Effective date must be YYYY-MM-DD", http.StatusBadRequest)
+		return
+	case c.EffectiveDate <= time.Now().Format("2006-01-02"):
+		jsonError(w, "Effective date must be in the future; edit the property to change rent now", http.StatusBadRequest)
+		return
+	case len(c.Reason) > 255:
+		jsonError(w, "Reason must be at most 255 characters", http.StatusBadRequest)
+		return
+	}
+
+	var unitCount int
+	db.QueryRow("SELECT COUNT(*) FROM units WHERE property_id = ?", propertyID).Scan(&unitCount)
+	if unitCount != 1 {
+		jsonError(w, "Rent changes can only be scheduled for single-unit properties; multi-unit buildings are priced per unit", http.StatusConflict)
+		return
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO rent_history (property_id, effective_date, monthly_rent, reason, status)
+		VALUES (?, ?, ?, ?, ?)
+	`, propertyID, effective.Format("2006-01-02"), c.MonthlyRent, c.Reason, rentChangeScheduled)
+	if err != nil {
+		log.Printf("Error scheduling rent change: %v", err)
+		jsonError(w, "Failed to schedule rent change", http.StatusInternalServerError)
+		return
+	}
+	id, _ := result.LastInsertId()
+
+	rows, err := db.Query(`SELECT `+rentChangeColumns+` FROM rent_history WHERE id = ?`, id)
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	c, err = scanRentChange(rows)
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, c, http.StatusCreated)
+}
+
+// cancelRentChange removes a change that has not been applied yet.
+func cancelRentChange(w http.ResponseWriter, propertyID, changeID int) {
+	result, err := db.Exec("DELETE FROM rent_history WHERE id = ? AND property_id = ? AND status = ?",
+		changeID, propertyID, rentChangeScheduled)
+	if err != nil {
+		log.Printf("Error cancelling rent change: %v", err)
+		jsonError(w, "Failed to cancel rent change", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		jsonError(w, "Scheduled rent change not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
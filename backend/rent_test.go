@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestRentHistoryRecordsChanges(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Cottage", 1500)
+	path := fmt.Sprintf("/api/admin/properties/%d", pid)
+
+	p := decode[Property](t, e.do(http.MethodGet, path, token, nil))
+	p.Name = "Renamed Cottage"
+	e.expect(e.do(http.MethodPut, path, token, p), http.StatusOK) // same rent: no entry
+	p.MonthlyRent = 1600
+	e.expect(e.do(http.MethodPut, path, token, p), http.StatusOK)
+
+	rec := e.do(http.MethodGet, path+"/rent-history", token, nil)
+	e.expect(rec, http.StatusOK)
+	h := decode[RentHistory](t, rec)
+	if h.CurrentRent != 1600 || len(h.History) != 2 || len(h.Scheduled) != 0 {
+		t.Fatalf("unexpected rent history %+v", h)
+	}
+	latest := h.History[0]
+	if latest.MonthlyRent != 1600 || latest.PreviousRent == nil || *latest.PreviousRent != 1500 || latest.Reason != "Edited" || latest.EffectiveDate != day(0) {
+		t.Fatalf("unexpected latest change %+v", latest)
+	}
+	if first := h.History[1]; first.MonthlyRent != 1500 || first.PreviousRent != nil || first.Reason != "Listed" {
+		t.Fatalf("unexpected first change %+v", first)
+	}
+
+	e.expect(e.do(http.MethodGet, "/api/admin/properties/9999/rent-history", token, nil), http.StatusNotFound)
+}
+
+func TestScheduledRentChanges(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Cottage", 1500)
+	path := fmt.Sprintf("/api/admin/properties/%d/rent-history", pid)
+
+	for _, bad := range []RentChange{
+		{EffectiveDate: day(0), MonthlyRent: 1700},
+		{EffectiveDate: "next month", MonthlyRent: 1700},
+		{EffectiveDate: day(30), MonthlyRent: 0},
+	} {
+		e.expect(e.do(http.MethodPost, path, token, bad), http.StatusBadRequest)
+	}
+
+	rec := e.do(http.MethodPost, path, token, RentChange{EffectiveDate: day(30), MonthlyRent: 1700, Reason: "Annual increase"})
+	e.expect(rec, http.StatusCreated)
+	increase := decode[RentChange](t, rec)
+	if increase.Status != rentChangeScheduled || increase.AppliedAt != nil {
+		t.Fatalf("unexpected scheduled change %+v", increase)
+	}
+	rec = e.do(http.MethodPost, path, token, RentChange{EffectiveDate: day(60), MonthlyRent: 1800})
+	e.expect(rec, http.StatusCreated)
+	later := decode[RentChange](t, rec)
+
+	// Not due yet.
+	if err := applyScheduledRentChanges(context.Background()); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	h := decode[RentHistory](t, e.do(http.MethodGet, path, token, nil))
+	if h.CurrentRent != 1500 || len(h.Scheduled) != 2 || h.Scheduled[0].ID != increase.ID {
+		t.Fatalf("expected two pending changes, soonest first, got %+v", h)
+	}
+
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("%s/%d", path, later.ID), token, nil), http.StatusNoContent)
+	if _, err := db.Exec("UPDATE rent_history SET effective_date = ? WHERE id = ?", day(0), increase.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyScheduledRentChanges(context.Background()); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	p := decode[Property](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/properties/%d", pid), token, nil))
+	if p.MonthlyRent != 1700 || p.Units[0].MonthlyRent != 1700 {
+		t.Fatalf("expected the property and its unit at 1700, got %v and %v", p.MonthlyRent, p.Units[0].MonthlyRent)
+	}
+	h = decode[RentHistory](t, e.do(http.MethodGet, path, token, nil))
+	if len(h.Scheduled) != 0 || len(h.History) != 2 {
+		t.Fatalf("unexpected history after applying %+v", h)
+	}
+	applied := h.History[0]
+	if applied.ID != increase.ID || applied.Status != rentChangeApplied || applied.AppliedAt == nil ||
+		applied.PreviousRent == nil || *applied.PreviousRent != 1500 || applied.Reason != "Annual increase" {
+		t.Fatalf("unexpected applied change %+v", applied)
+	}
+
+	// Applied changes can't be cancelled.
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("%s/%d", path, increase.ID), token, nil), http.StatusNotFound)
+}
+
+func TestScheduledRentChangesNeedASingleUnit(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	building := e.createBuilding(token, "Fourplex", fourplexUnits())
+	path := fmt.Sprintf("/api/admin/properties/%d/rent-history", building.ID)
+	e.expect(e.do(http.MethodPost, path, token, RentChange{EffectiveDate: day(30), MonthlyRent: 1200}), http.StatusConflict)
+
+	// Unit edits move the building's asking rent and are recorded too.
+	units := building.Units
+	units[0].MonthlyRent = 1000
+	e.expect(e.do(http.MethodPut, fmt.Sprintf("/api/admin/properties/%d/units/%d", building.ID, units[0].ID), token, units[0]), http.StatusOK)
+	h := decode[RentHistory](t, e.do(http.MethodGet, path, token, nil))
+	if len(h.History) != 2 || h.History[0].MonthlyRent != 1000 || h.History[0].Reason != "Unit edited" {
+		t.Fatalf("unexpected building history %+v", h)
+	}
+
+	// A property that gains a unit before its change comes due is skipped.
+	pid := e.createProperty(token, "Cottage", 1500)
+	path = fmt.Sprintf("/api/admin/properties/%d/rent-history", pid)
+	e.expect(e.do(http.MethodPost, path, token, RentChange{EffectiveDate: day(30), MonthlyRent: 1700}), http.StatusCreated)
+	e.expect(e.do(http.MethodPost, fmt.Sprintf("/api/admin/properties/%d/units", pid), token, Unit{UnitNumber: "2", Bedrooms: 1, Bathrooms: 1, MonthlyRent: 1300}), http.StatusCreated)
+	if _, err := db.Exec("UPDATE rent_history SET effective_date = ? WHERE status = ?", day(0), rentChangeScheduled); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyScheduledRentChanges(context.Background()); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	h = decode[RentHistory](t, e.do(http.MethodGet, path, token, nil))
+	if h.History[0].Status != rentChangeSkipped || h.CurrentRent == 1700 {
+		t.Fatalf("expected the change to be skipped, got %+v", h)
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ============================================================================
+// RENT ROLL
+// ============================================================================
+
+// The rent roll is a point-in-time snapshot: one row per unit with the lease
+// in force on the as-of date. A lease is in force from its start date
+// through its end date unless it is a draft or in the trash. Archived
+// properties are listed only when one of their units was let on the date.
+
+// RentRollRow is one unit of the rent roll.
+type RentRollRow struct {
+	PropertyID   int    `json:"propertyId"`
+	PropertyName string `json:"propertyName"`
+	UnitID       int    `json:"unitId"`
+	UnitNumber   string `json:"unitNumber"`
+	// MarketRent is the unit's current asking rent.
+	MarketRent float64 `json:"marketRent"`
+	Vacant     bool    `json:"vacant"`
+	// The lease fields are absent when the unit is vacant.
+	LeaseID    *int    `json:"leaseId,omitempty"`
+	TenantID   *int    `json:"tenantId,omitempty"`
+	TenantName *string `json:"tenantName,omitempty"`
+	LeaseStart *string `json:"leaseStart,omitempty"`
+	LeaseEnd   *string `json:"leaseEnd,omitempty"`
+	// MonthlyRent is the lease's rent, 0 when vacant.
+	MonthlyRent float64 `json:"monthlyRent"`
+	// DepositHeld is completed deposit payments on the lease to date.
+	DepositHeld float64 `json:"depositHeld"`
+	// Balance is rent due to date less rent paid; negative is a credit.
+	Balance float64 `json:"balance"`
+}
+
+// RentRoll is the response of GET /api/admin/reports/rent-roll.
+type RentRoll struct {
+	AsOf          string        `json:"asOf"`
+	TotalUnits    int           `json:"totalUnits"`
+	OccupiedUnits int           `json:"occupiedUnits"`
+	VacantUnits   int           `json:"vacantUnits"`
+	OccupancyRate float64       `json:"occupancyRate"`
+	ScheduledRent float64       `json:"scheduledRent"`
+	DepositsHeld  float64       `json:"depositsHeld"`
+	TotalBalance  float64       `json:"totalBalance"`
+	Units         []RentRollRow `json:"units"`
+}
+
+// rentDueDates returns the dates a lease's rent fell due from its start
+// through asOf: the PaymentDueDay of each month, moved to the last day of
+// shorter months, within the lease term. There is no proration; a lease
+// starting after the due day first owes rent the following month.
+func rentDueDates(start, end, asOf time.Time, dueDay int) []time.Time {
+	if dueDay < 1 {
+		dueDay = 1
+	}
+	if asOf.Before(end) {
+		end = asOf
+	}
+	var dates []time.Time
+	for m := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(end); m = m.AddDate(0, 1, 0) {
+		last := m.AddDate(0, 1, -1).Day()
+		d := m.AddDate(0, 0, min(dueDay, last)-1)
+		if !d.Before(start) && !d.After(end) {
+			dates = append(dates, d)
+		}
+	}
+	return dates
+}
+
+func rentRollReportHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	asOf := time.Now().Format("2006-01-02")
+	if raw := q.Get("asOf"); raw != "" {
+		asOf = raw
+	}
+	if _, err := time.Parse("2006-01-02", asOf); err != nil {
+		jsonError(w, "asOf must be YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	roll, err := buildRentRoll(asOf, q.Get("propertyId"), q.Get("ownerId"))
+	if err != nil {
+		if errors.Is(err, errBadOwnerID) {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error building rent roll: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	switch q.Get("format") {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="rent-roll-%s.csv"`, asOf))
+		cw := csv.NewWriter(w)
+		cw.WriteAll(rentRollRecords(roll))
+	case "xlsx":
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="rent-roll-%s.xlsx"`, asOf))
+		if err := writeRentRollXLSX(w, roll); err != nil {
+			log.Printf("Error writing rent roll spreadsheet: %v", err)
+		}
+	default:
+		jsonResponse(w, roll, http.StatusOK)
+	}
+}
+
+// buildRentRoll lists every unit as of asOf, optionally narrowed to one
+// property or owner, sorted by property and unit.
+func buildRentRoll(asOf, propertyID, ownerID string) (RentRoll, error) {
+	roll := RentRoll{AsOf: asOf, Units: []RentRollRow{}}
+	asOfDate, _ := time.Parse("2006-01-02", asOf)
+
+	where, args := "", []interface{}{}
+	if id, err := strconv.Atoi(propertyID); err == nil {
+		where += " AND p.id = ?"
+		args = append(args, id)
+	}
+	clause, ownerArgs, err := ownerFilter(ownerID, "p.id")
+	if err != nil {
+		return roll, err
+	}
+	where += clause
+	args = append(args, ownerArgs...)
+
+	leases, err := loadLeasesInForce(asOf, asOfDate, where, args)
+	if err != nil {
+		return roll, err
+	}
+
+	rows, err := db.Query(`
+		SELECT p.id, p.name, p.listing_status, u.id, u.unit_number, u.monthly_rent
+		FROM units u
+		JOIN properties p ON p.id = u.property_id
+		WHERE 1=1`+where, args...)
+	if err != nil {
+		return roll, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row RentRollRow
+		var status string
+		if err := rows.Scan(&row.PropertyID, &row.PropertyName, &status, &row.UnitID, &row.UnitNumber, &row.MarketRent); err != nil {
+			return roll, err
+		}
+		l, let := leases[row.UnitID]
+		if !let {
+			if status == listingArchived {
+				continue
+			}
+			row.Vacant = true
+			roll.Units = append(roll.Units, row)
+			continue
+		}
+		row.LeaseID, row.TenantID, row.TenantName = &l.id, &l.tenantID, &l.tenantName
+		row.LeaseStart, row.LeaseEnd = &l.start, &l.end
+		row.MonthlyRent = l.rent
+		row.DepositHeld = roundCents(l.deposits)
+		row.Balance = roundCents(l.rent*float64(l.dueCount) - l.rentPaid)
+		roll.Units = append(roll.Units, row)
+	}
+	if err := rows.Err(); err != nil {
+		return roll, err
+	}
+
+	sort.SliceStable(roll.Units, func(i, j int) bool {
+		a, b := roll.Units[i], roll.Units[j]
+		if a.PropertyName != b.PropertyName {
+			return a.PropertyName < b.PropertyName
+		}
+		return a.UnitNumber < b.UnitNumber
+	})
+	for _, row := range roll.Units {
+		roll.TotalUnits++
+		if row.Vacant {
+			roll.VacantUnits++
+			continue
+		}
+		roll.OccupiedUnits++
+		roll.ScheduledRent += row.MonthlyRent
+		roll.DepositsHeld += row.DepositHeld
+		roll.TotalBalance += row.Balance
+	}
+	if roll.TotalUnits > 0 {
+		roll.OccupancyRate = roundCents(float64(roll.OccupiedUnits) * 100 / float64(roll.TotalUnits))
+	}
+	roll.ScheduledRent = roundCents(roll.ScheduledRent)
+	roll.DepositsHeld = roundCents(roll.DepositsHeld)
+	roll.TotalBalance = roundCents(roll.TotalBalance)
+	return roll, nil
+}
+
+// leaseInForce is a lease let on the rent roll date, with its payments to
+// that date.
+type leaseInForce struct {
+	id, tenantID           int
+	tenantName, start, end string
+	rent                   float64
+	dueCount               int
+	deposits, rentPaid     float64
+}
+
+// loadLeasesInForce maps unit IDs to the lease in force on asOf. where and
+// args narrow the properties as in buildRentRoll.
+func loadLeasesInForce(asOf string, asOfDate time.Time, where string, args []interface{}) (map[int]leaseInForce, error) {
+	rows, err := db.Query(`
+		SELECT l.id, l.unit_id, l.tenant_id, CONCAT(t.first_name, ' ', t.last_name),
+			   l.start_date, l.end_date, l.monthly_rent, l.payment_due_day,
+			   (SELECT COALESCE(SUM(pay.amount), 0) FROM payments pay
+				WHERE pay.lease_id = l.id AND pay.deleted_at IS NULL AND pay.status = 'completed'
+				AND pay.payment_type = 'deposit' AND pay.payment_date <= ?),
+			   (SELECT COALESCE(SUM(pay.amount), 0) FROM payments pay
+				WHERE pay.lease_id = l.id AND pay.deleted_at IS NULL AND pay.status = 'completed'
+				AND pay.payment_type = 'rent' AND pay.payment_date <= ?)
+		FROM leases l
+		JOIN tenants t ON t.id = l.tenant_id
+		JOIN properties p ON p.id = l.property_id
+		WHERE l.deleted_at IS NULL AND l.status <> 'draft' AND l.unit_id IS NOT NULL
+		AND l.start_date <= ? AND l.end_date >= ?`+where+`
+		ORDER BY l.start_date, l.id
+	`, append([]interface{}{asOf, asOf, asOf, asOf}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leases := map[int]leaseInForce{}
+	for rows.Next() {
+		var l leaseInForce
+		var unitID, dueDay int
+		if err := rows.Scan(&l.id, &unitID, &l.tenantID, &l.tenantName, &l.start, &l.end, &l.rent, &dueDay,
+			&l.deposits, &l.rentPaid); err != nil {
+			return nil, err
+		}
+		l.start, l.end = dateOnly(l.start), dateOnly(l.end)
+		start, err1 := time.Parse("2006-01-02", l.start)
+		end, err2 := time.Parse("2006-01-02", l.end)
+		if err1 == nil && err2 == nil {
+			l.dueCount = len(rentDueDates(start, end, asOfDate, dueDay))
+		}
+		// Leases on a unit shouldn't overlap; if they do, the latest wins.
+		leases[unitID] = l
+	}
+	return leases, rows.Err()
+}
+
+// rentRollRecords lays the rent roll out as spreadsheet rows: a header,
+// one row per unit and a closing totals row.
+func rentRollRecords(roll RentRoll) [][]string {
+	money := func(x float64) string { return strconv.FormatFloat(x, 'f', 2, 64) }
+	str := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	records := [][]string{{
+		"Property", "Unit", "Status", "Tenant", "Lease start", "Lease end",
+		"Monthly rent", "Market rent", "Deposit held", "Balance",
+	}}
+	for _, row := range roll.Units {
+		status := "Occupied"
+		if row.Vacant {
+			status = "Vacant"
+		}
+		records = append(records, []string{
+			row.PropertyName, row.UnitNumber, status, str(row.TenantName), str(row.LeaseStart), str(row.LeaseEnd),
+			money(row.MonthlyRent), money(row.MarketRent), money(row.DepositHeld), money(row.Balance),
+		})
+	}
+	records = append(records, []string{
+		"Total", fmt.Sprintf("%d units", roll.TotalUnits),
+		fmt.Sprintf("%d occupied (%s%%)", roll.OccupiedUnits, strconv.FormatFloat(roll.OccupancyRate, 'f', -1, 64)),
+		"", "", "", money(roll.ScheduledRent), "", money(roll.DepositsHeld), money(roll.TotalBalance),
+	})
+	return records
+}
+
+// writeRentRollXLSX writes the rent roll records as a single-sheet
+// workbook, with amounts as numbers so they can be summed.
+func writeRentRollXLSX(w io.Writer, roll RentRoll) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := "Rent Roll " + roll.AsOf
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+
+	moneyStyle, err := f.NewStyle(&excelize.Style{NumFmt: 4}) // #,##0.00
+	if err != nil {
+		return err
+	}
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+
+	records := rentRollRecords(roll)
+	for i, record := range records {
+		for j, value := range record {
+			cell, _ := excelize.CoordinatesToCellName(j+1, i+1)
+			// Amount columns are numbers below the header.
+			if n, err := strconv.ParseFloat(value, 64); err == nil && i > 0 && j >= 6 {
+				f.SetCellFloat(sheet, cell, n, -1, 64)
+				f.SetCellStyle(sheet, cell, cell, moneyStyle)
+				continue
+			}
+			f.SetCellStr(sheet, cell, value)
+		}
+	}
+	last, _ := excelize.CoordinatesToCellName(len(records[0]), 1)
+	f.SetCellStyle(sheet, "A1", last, bold)
+	f.SetColWidth(sheet, "A", "A", 28)
+	f.SetColWidth(sheet, "B", "J", 14)
+	f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+
+	_, err = f.WriteTo(w)
+	return err
+}
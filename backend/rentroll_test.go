@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestRentDueDates(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	for _, tc := range []struct {
+		start, end, asOf string
+		dueDay           int
+		want             []string
+	}{
+		{"2026-01-01", "2026-12-31", "2026-03-15", 1, []string{"2026-01-01", "2026-02-01", "2026-03-01"}},
+		// Starting after the due day skips to next month.
+		{"2026-01-10", "2026-12-31", "2026-03-15", 5, []string{"2026-02-05", "2026-03-05"}},
+		// The 31st falls on the last day of shorter months.
+		{"2026-01-01", "2026-12-31", "2026-03-31", 31, []string{"2026-01-31", "2026-02-28", "2026-03-31"}},
+		// Nothing is due after the lease ends.
+		{"2026-01-01", "2026-02-15", "2026-06-01", 1, []string{"2026-01-01", "2026-02-01"}},
+		{"2026-05-01", "2027-04-30", "2026-03-01", 1, nil},
+	} {
+		got := rentDueDates(date(tc.start), date(tc.end), date(tc.asOf), tc.dueDay)
+		if len(got) != len(tc.want) {
+			t.Errorf("%+v: got %v", tc, got)
+			continue
+		}
+		for i := range got {
+			if got[i].Format("2006-01-02") != tc.want[i] {
+				t.Errorf("%+v: got %v", tc, got)
+				break
+			}
+		}
+	}
+}
+
+func TestRentRoll(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	building := e.createBuilding(token, "Cedar Flats", fourplexUnits())
+	house := e.createListing(token, Property{Name: "Aspen House", MonthlyRent: 2000})
+	ann := e.createTenant(token, "ann@example.com", "password123")
+	bob := e.createTenant(token, "bob@example.com", "password123")
+
+	lease := func(propertyID, tenantID int, unitID *int, start, end string, rent float64) int {
+		t.Helper()
+		rec := e.do(http.MethodPost, "/api/admin/leases", token, Lease{
+			PropertyID: propertyID, UnitID: unitID, TenantID: tenantID,
+			StartDate: start, EndDate: end, MonthlyRent: rent, PaymentDueDay: 1,
+		})
+		e.expect(rec, http.StatusCreated)
+		return decode[Lease](t, rec).ID
+	}
+	houseLease := lease(house.ID, ann, nil, "2026-01-01", "2026-12-31", 2000)
+	lease(building.ID, bob, &building.Units[0].ID, "2026-01-01", "2026-12-31", 1100)
+	// Signed for later; vacant on the rent roll date.
+	lease(building.ID, bob, &building.Units[1].ID, "2026-06-01", "2027-05-31", 1200)
+
+	for _, p := range []Payment{
+		{LeaseID: houseLease, Amount: 2000, PaymentDate: "2026-01-01", PaymentType: "deposit"},
+		{LeaseID: houseLease, Amount: 2000, PaymentDate: "2026-01-01"},
+		{LeaseID: houseLease, Amount: 2000, PaymentDate: "2026-02-03"},
+		{LeaseID: houseLease, Amount: 500, PaymentDate: "2026-03-05"},
+		// After the rent roll date.
+		{LeaseID: houseLease, Amount: 1500, PaymentDate: "2026-03-20"},
+	} {
+		e.expect(e.do(http.MethodPost, "/api/admin/payments", token, p), http.StatusCreated)
+	}
+
+	rec := e.do(http.MethodGet, "/api/admin/reports/rent-roll?asOf=2026-03-15", token, nil)
+	e.expect(rec, http.StatusOK)
+	roll := decode[RentRoll](t, rec)
+	if roll.TotalUnits != 4 || roll.OccupiedUnits != 2 || roll.VacantUnits != 2 || roll.OccupancyRate != 50 {
+		t.Fatalf("unexpected totals %+v", roll)
+	}
+	if roll.ScheduledRent != 3100 || roll.DepositsHeld != 2000 {
+		t.Fatalf("unexpected scheduled rent or deposits %+v", roll)
+	}
+
+	h := roll.Units[0]
+	if h.PropertyID != house.ID || h.Vacant || h.LeaseID == nil || *h.LeaseID != houseLease || h.TenantName == nil ||
+		*h.LeaseStart != "2026-01-01" || h.MonthlyRent != 2000 || h.DepositHeld != 2000 {
+		t.Fatalf("unexpected house row %+v", h)
+	}
+	// Three months due, 4500 paid by the 15th.
+	if h.Balance != 1500 {
+		t.Fatalf("expected a 1500 balance, got %v", h.Balance)
+	}
+	// Cedar Flats unit 1 has paid nothing for three months.
+	if c := roll.Units[1]; c.Vacant || c.Balance != 3300 {
+		t.Fatalf("unexpected building row %+v", c)
+	}
+	if c := roll.Units[2]; !c.Vacant || c.LeaseID != nil || c.MonthlyRent != 0 || c.MarketRent == 0 {
+		t.Fatalf("expected a vacant unit, got %+v", c)
+	}
+
+	// Before any lease started everything is vacant.
+	early := decode[RentRoll](t, e.do(http.MethodGet, "/api/admin/reports/rent-roll?asOf=2025-12-31", token, nil))
+	if early.OccupiedUnits != 0 || early.ScheduledRent != 0 {
+		t.Fatalf("unexpected early rent roll %+v", early)
+	}
+	one := decode[RentRoll](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/reports/rent-roll?asOf=2026-03-15&propertyId=%d", house.ID), token, nil))
+	if one.TotalUnits != 1 {
+		t.Fatalf("expected one unit, got %+v", one)
+	}
+	e.expect(e.do(http.MethodGet, "/api/admin/reports/rent-roll?asOf=03/15/2026", token, nil), http.StatusBadRequest)
+
+	rec = e.do(http.MethodGet, "/api/admin/reports/rent-roll?asOf=2026-03-15&format=csv", token, nil)
+	e.expect(rec, http.StatusOK)
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 6 || records[1][0] != "Aspen House" || records[5][0] != "Total" || records[5][6] != "3100.00" {
+		t.Fatalf("unexpected csv %v", records)
+	}
+
+	rec = e.do(http.MethodGet, "/api/admin/reports/rent-roll?asOf=2026-03-15&format=xlsx", token, nil)
+	e.expect(rec, http.StatusOK)
+	f, err := excelize.OpenReader(rec.Body)
+	if err != nil {
+		t.Fatalf("open xlsx: %v", err)
+	}
+	defer f.Close()
+	sheet := f.GetSheetName(0)
+	if name, _ := f.GetCellValue(sheet, "A2"); name != "Aspen House" {
+		t.Fatalf("unexpected first row %q", name)
+	}
+	if balance, _ := f.GetCellValue(sheet, "J2", excelize.Options{RawCellValue: true}); balance != "1500" {
+		t.Fatalf("expected a numeric balance, got %q", balance)
+	}
+}
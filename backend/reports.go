@@ -0,0 +1,255 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// HANDLERS - ADMIN REPORTS
+// ============================================================================
+
+// adminReportsHandler serves /api/admin/reports/:name.
+func adminReportsHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/reports/"), "/") {
+	case "vacancy":
+		vacancyReportHandler(w, r)
+	case "pnl":
+		pnlReportHandler(w, r)
+	case "rent-roll":
+		rentRollReportHandler(w, r)
+	case "receivables":
+		receivablesReportHandler(w, r)
+	default:
+		jsonError(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// roundCents rounds a dollar amount to the cent.
+func roundCents(x float64) float64 {
+	return math.Round(x*100) / 100
+}
+
+// median returns the middle value of xs, or the mean of the two middle
+// values. xs is sorted in place.
+func median(xs []float64) float64 {
+	sort.Float64s(xs)
+	n := len(xs)
+	if n%2 == 1 {
+		return xs[n/2]
+	}
+	return (xs[n/2-1] + xs[n/2]) / 2
+}
+
+// ============================================================================
+// VACANCY REPORT
+// ============================================================================
+
+// The vacancy report lists every available unit that isn't archived or
+// under an active lease: how long it has been empty, the rent that has cost
+// at its current asking rent, and what comparable units in the portfolio
+// are let for. Units are reported rather than properties because a
+// building's units are let separately; a single-unit property is one row.
+
+// VacantUnit is one row of the vacancy report.
+type VacantUnit struct {
+	PropertyID   int     `json:"propertyId"`
+	PropertyName string  `json:"propertyName"`
+	UnitID       int     `json:"unitId"`
+	UnitNumber   string  `json:"unitNumber"`
+	PropertyType string  `json:"propertyType"`
+	City         string  `json:"city"`
+	Bedrooms     int     `json:"bedrooms"`
+	SquareFeet   *int    `json:"squareFeet,omitempty"`
+	MonthlyRent  float64 `json:"monthlyRent"`
+	// VacantSince is the end date of the unit's last lease, or the day the
+	// unit was added when it has never been leased (NeverLeased).
+	VacantSince string `json:"vacantSince"`
+	NeverLeased bool   `json:"neverLeased"`
+	DaysVacant  int    `json:"daysVacant"`
+	// LostRent is DaysVacant at the current asking rent, pro rata.
+	LostRent    float64      `json:"lostRent"`
+	Comparables []Comparable `json:"comparables"`
+	// MedianRentPerSqft is over comparables with a known size; absent when
+	// there are none. SuggestedRent applies it to this unit's size.
+	MedianRentPerSqft *float64 `json:"medianRentPerSqft,omitempty"`
+	SuggestedRent     *float64 `json:"suggestedRent,omitempty"`
+}
+
+// Comparable is an actively leased unit of the same property type and
+// bedroom count in the same city.
+type Comparable struct {
+	LeaseID      int      `json:"leaseId"`
+	PropertyID   int      `json:"propertyId"`
+	PropertyName string   `json:"propertyName"`
+	UnitNumber   string   `json:"unitNumber"`
+	MonthlyRent  float64  `json:"monthlyRent"`
+	SquareFeet   *int     `json:"squareFeet,omitempty"`
+	RentPerSqft  *float64 `json:"rentPerSqft,omitempty"`
+	propertyType string
+	city         string
+	bedrooms     int
+}
+
+// VacancyReport is the response of GET /api/admin/reports/vacancy.
+type VacancyReport struct {
+	AsOf          string       `json:"asOf"`
+	VacantUnits   int          `json:"vacantUnits"`
+	TotalLostRent float64      `json:"totalLostRent"`
+	Units         []VacantUnit `json:"units"`
+}
+
+func vacancyReportHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := buildVacancyReport(time.Now(), r.URL.Query().Get("ownerId"))
+	if err != nil {
+		if errors.Is(err, errBadOwnerID) {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error building vacancy report: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, report, http.StatusOK)
+}
+
+// buildVacancyReport lists vacant units, costliest first. Given an owner,
+// only their units are listed; comparables still come from the whole
+// portfolio.
+func buildVacancyReport(now time.Time, ownerID string) (VacancyReport, error) {
+	today := now.Format("2006-01-02")
+	report := VacancyReport{AsOf: today, Units: []VacantUnit{}}
+	clause, ownerArgs, err := ownerFilter(ownerID, "p.id")
+	if err != nil {
+		return report, err
+	}
+
+	rows, err := db.Query(`
+		SELECT p.id, p.name, u.id, u.unit_number, p.property_type, p.city,
+			   u.bedrooms, u.square_feet, u.monthly_rent, u.created_at,
+			   (SELECT MAX(l.end_date) FROM leases l
+				WHERE l.unit_id = u.id AND l.deleted_at IS NULL AND l.status <> 'draft' AND l.end_date < ?)
+		FROM units u
+		JOIN properties p ON p.id = u.property_id
+		WHERE u.available = TRUE AND p.listing_status <> ?
+		AND NOT EXISTS (SELECT 1 FROM leases l WHERE l.unit_id = u.id AND l.status = 'active' AND l.deleted_at IS NULL)
+	`+clause, append([]interface{}{today, listingArchived}, ownerArgs...)...)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v VacantUnit
+		var sqft sql.NullInt64
+		var createdAt time.Time
+		var lastEnd sql.NullString
+		if err := rows.Scan(&v.PropertyID, &v.PropertyName, &v.UnitID, &v.UnitNumber, &v.PropertyType, &v.City,
+			&v.Bedrooms, &sqft, &v.MonthlyRent, &createdAt, &lastEnd); err != nil {
+			return report, err
+		}
+		if sqft.Valid {
+			n := int(sqft.Int64)
+			v.SquareFeet = &n
+		}
+		if lastEnd.Valid {
+			v.VacantSince = dateOnly(lastEnd.String)
+		} else {
+			v.VacantSince = createdAt.Format("2006-01-02")
+			v.NeverLeased = true
+		}
+		if since, err := time.Parse("2006-01-02", v.VacantSince); err == nil {
+			midnight, _ := time.Parse("2006-01-02", today)
+			v.DaysVacant = max(0, int(midnight.Sub(since).Hours()/24))
+		}
+		v.LostRent = roundCents(v.MonthlyRent * 12 / 365 * float64(v.DaysVacant))
+		report.Units = append(report.Units, v)
+	}
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	comparables, err := loadLeasedComparables()
+	if err != nil {
+		return report, err
+	}
+	for i := range report.Units {
+		v := &report.Units[i]
+		v.Comparables = []Comparable{}
+		var perSqft []float64
+		for _, c := range comparables {
+			if c.propertyType != v.PropertyType || c.bedrooms != v.Bedrooms || !strings.EqualFold(c.city, v.City) {
+				continue
+			}
+			v.Comparables = append(v.Comparables, c)
+			if c.SquareFeet != nil {
+				perSqft = append(perSqft, c.MonthlyRent/float64(*c.SquareFeet))
+			}
+		}
+		if len(perSqft) > 0 {
+			m := roundCents(median(perSqft))
+			v.MedianRentPerSqft = &m
+			if v.SquareFeet != nil {
+				suggested := roundCents(m * float64(*v.SquareFeet))
+				v.SuggestedRent = &suggested
+			}
+		}
+		report.TotalLostRent += v.LostRent
+	}
+	report.VacantUnits = len(report.Units)
+	report.TotalLostRent = roundCents(report.TotalLostRent)
+
+	sort.SliceStable(report.Units, func(i, j int) bool {
+		return report.Units[i].LostRent > report.Units[j].LostRent
+	})
+	return report, nil
+}
+
+// loadLeasedComparables returns every unit under an active lease, at the
+// lease's rent.
+func loadLeasedComparables() ([]Comparable, error) {
+	rows, err := db.Query(`
+		SELECT l.id, p.id, p.name, u.unit_number, l.monthly_rent, u.square_feet,
+			   p.property_type, p.city, u.bedrooms
+		FROM leases l
+		JOIN units u ON u.id = l.unit_id
+		JOIN properties p ON p.id = l.property_id
+		WHERE l.status = 'active' AND l.deleted_at IS NULL
+		ORDER BY l.monthly_rent, l.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comparables []Comparable
+	for rows.Next() {
+		var c Comparable
+		var sqft sql.NullInt64
+		if err := rows.Scan(&c.LeaseID, &c.PropertyID, &c.PropertyName, &c.UnitNumber, &c.MonthlyRent, &sqft,
+			&c.propertyType, &c.city, &c.bedrooms); err != nil {
+			return nil, err
+		}
+		if sqft.Valid && sqft.Int64 > 0 {
+			n := int(sqft.Int64)
+			perSqft := roundCents(c.MonthlyRent / float64(n))
+			c.SquareFeet, c.RentPerSqft = &n, &perSqft
+		}
+		comparables = append(comparables, c)
+	}
+	return comparables, rows.Err()
+}
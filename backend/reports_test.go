@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestVacancyReport(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	tenant := e.createTenant(token, "renter@example.com", "password123")
+	sqft := func(n int) *int { return &n }
+
+	// Leased comparables at 1500/month: 1.50 and 3.00 per sq ft.
+	for _, p := range []Property{
+		{Name: "Comp Large", Bedrooms: 2, SquareFeet: sqft(1000)},
+		{Name: "Comp Small", Bedrooms: 2, SquareFeet: sqft(500)},
+		{Name: "Comp No Size", Bedrooms: 2},
+		{Name: "Other City", Bedrooms: 2, SquareFeet: sqft(100), City: "Seattle", State: "WA", Zip: "98101"},
+		{Name: "Bigger", Bedrooms: 3, SquareFeet: sqft(100)},
+	} {
+		comp := e.createListing(token, p)
+		e.expect(e.createLease(token, comp.ID, tenant, day(-30), day(335)), http.StatusCreated)
+	}
+
+	vacant := e.createListing(token, Property{Name: "Empty Flat", Bedrooms: 2, SquareFeet: sqft(800), MonthlyRent: 1825})
+	e.expect(e.createLease(token, vacant.ID, tenant, day(-400), day(-20)), http.StatusCreated)
+	fresh := e.createListing(token, Property{Name: "New Build", Bedrooms: 1, MonthlyRent: 1000})
+	e.createListing(token, Property{Name: "Archived", Bedrooms: 2, ListingStatus: listingArchived})
+
+	rec := e.do(http.MethodGet, "/api/admin/reports/vacancy", token, nil)
+	e.expect(rec, http.StatusOK)
+	report := decode[VacancyReport](t, rec)
+	if report.VacantUnits != 2 || len(report.Units) != 2 {
+		t.Fatalf("expected two vacant units, got %+v", report)
+	}
+
+	v := report.Units[0]
+	if v.PropertyID != vacant.ID || v.VacantSince != day(-20) || v.NeverLeased || v.DaysVacant != 20 {
+		t.Fatalf("unexpected vacancy %+v", v)
+	}
+	if v.LostRent != 1200 { // 1825 * 12 / 365 * 20
+		t.Fatalf("expected 1200 of lost rent, got %v", v.LostRent)
+	}
+	if len(v.Comparables) != 3 {
+		t.Fatalf("expected three comparables, got %+v", v.Comparables)
+	}
+	if v.MedianRentPerSqft == nil || *v.MedianRentPerSqft != 2.25 || v.SuggestedRent == nil || *v.SuggestedRent != 1800 {
+		t.Fatalf("unexpected median %v and suggestion %v", v.MedianRentPerSqft, v.SuggestedRent)
+	}
+
+	n := report.Units[1]
+	if n.PropertyID != fresh.ID || !n.NeverLeased || n.DaysVacant != 0 || len(n.Comparables) != 0 || n.MedianRentPerSqft != nil {
+		t.Fatalf("unexpected never-leased unit %+v", n)
+	}
+	if report.TotalLostRent != 1200 {
+		t.Fatalf("expected 1200 in total, got %v", report.TotalLostRent)
+	}
+
+	e.expect(e.do(http.MethodGet, "/api/admin/reports/nope", token, nil), http.StatusNotFound)
+}
@@ -0,0 +1,534 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"math"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// MODELS - SAVED SEARCHES
+// ============================================================================
+
+// SavedSearch is a listing query a prospect wants alerts for. The token is
+// only ever sent by email; it verifies the address and unsubscribes.
+type SavedSearch struct {
+	ID         int               `json:"id"`
+	Email      string            `json:"email"`
+	Filters    map[string]string `json:"filters"`
+	Verified   bool              `json:"verified"`
+	LastSentAt *time.Time        `json:"lastSentAt,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	token      string
+}
+
+// savedSearchFilters are the listing query parameters a saved search may
+// hold. "available" is implied: alerts are only for available properties.
+var savedSearchFilters = []string{
+	"beds", "maxBeds", "minBaths", "minSqft", "maxSqft", "minRent", "maxRent",
+	"type", "search", "q", "amenities", "availableBy", "pets", "near", "lat", "lng", "radius",
+}
+
+// savedSearchVerifyWindow is how long an unverified search waits for its
+// link to be clicked before the alerts job deletes it.
+const savedSearchVerifyWindow = 7 * 24 * time.Hour
+
+// savedSearchLimiter throttles new saved searches per client IP, sharing the
+// contact form's limits.
+var savedSearchLimiter = newRateLimiter()
+
+// ============================================================================
+// HANDLERS - PUBLIC SAVED SEARCHES
+// ============================================================================
+
+// savedSearchesHandler serves POST /api/saved-searches.
+func savedSearchesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if ok, retry := savedSearchLimiter.allow(clientIP(r), cfg.Contact.RateLimit, cfg.Contact.RateWindow, time.Now()); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+		jsonError(w, "Too many saved searches, please try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	var body struct {
+		Email   string            `json:"email"`
+		Filters map[string]string `json:"filters"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	body.Email = strings.TrimSpace(body.Email)
+	if _, err := mail.ParseAddress(body.Email); err != nil {
+		jsonError(w, "Invalid email address", http.StatusBadRequest)
+		return
+	}
+
+	s := SavedSearch{Email: body.Email, Filters: map[string]string{}}
+	allowed := map[string]bool{}
+	for _, f := range savedSearchFilters {
+		allowed[f] = true
+	}
+	for k, v := range body.Filters {
+		if !allowed[k] {
+			jsonError(w, fmt.Sprintf("Unknown filter %q", k), http.StatusBadRequest)
+			return
+		}
+		if v = strings.TrimSpace(v); v != "" {
+			s.Filters[k] = v
+		}
+	}
+	// Reject filters the listing search would reject, so the alert job never
+	// trips over them later.
+	if _, err := parsePropertySearch(r.Context(), savedSearchQuery(s.Filters)); err != nil {
+		writeSearchError(w, err)
+		return
+	}
+
+	filtersJSON, _ := json.Marshal(s.Filters)
+	s.token = generateToken()
+
+	result, err := db.Exec("INSERT INTO saved_searches (email, filters, token) VALUES (?, ?, ?)",
+		s.Email, string(filtersJSON), s.token)
+	if err != nil {
+		log.Printf("Error saving search: %v", err)
+		jsonError(w, "Failed to save search", http.StatusInternalServerError)
+		return
+	}
+	id, _ := result.LastInsertId()
+	s.ID = int(id)
+
+	// The search is saved before mailing so no connection is held open for
+	// the send. If it fails, the alerts job retries it.
+	if err := sendVerificationEmail(r.Context(), s); err != nil {
+		log.Printf("Error sending saved search verification: %v", err)
+	}
+
+	s.CreatedAt = time.Now()
+	jsonResponse(w, s, http.StatusCreated)
+}
+
+// savedSearchByTokenHandler serves the links in saved search emails:
+// GET /api/saved-searches/:token shows the search,
+// /api/saved-searches/:token/verify confirms the address and
+// /api/saved-searches/:token/unsubscribe deletes it. Verify accepts GET so
+// it works straight from an email client. Unsubscribe only deletes on POST,
+// since mail scanners and link prefetchers follow GET links; a GET shows a
+// page asking to confirm, and mail clients can POST to it directly
+// (List-Unsubscribe-Post).
+func savedSearchByTokenHandler(w http.ResponseWriter, r *http.Request) {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/saved-searches/"), "/")
+	token, action, _ := strings.Cut(rest, "/")
+	if token == "" {
+		jsonError(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	switch action {
+	case "":
+		if r.Method != http.MethodGet {
+			jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+	case "verify", "unsubscribe":
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+	default:
+		jsonError(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	s, err := loadSavedSearch(token)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Saved search not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading saved search: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	switch action {
+	case "verify":
+		if !s.Verified {
+			if err := verifySavedSearch(r.Context(), s); err != nil {
+				log.Printf("Error verifying saved search: %v", err)
+				jsonError(w, "Failed to verify saved search", http.StatusInternalServerError)
+				return
+			}
+			s.Verified = true
+		}
+	case "unsubscribe":
+		if r.Method == http.MethodGet {
+			w.Header().Set("Cache-Control", "no-store")
+			writeUnsubscribePage(w, unsubscribeConfirmPage, s)
+			return
+		}
+		if _, err := db.Exec("DELETE FROM saved_searches WHERE id = ?", s.ID); err != nil {
+			log.Printf("Error deleting saved search: %v", err)
+			jsonError(w, "Failed to unsubscribe", http.StatusInternalServerError)
+			return
+		}
+		// The confirmation page's form wants a page back; mail clients and
+		// API callers don't.
+		if strings.Contains(r.Header.Get("Accept"), "text/html") {
+			writeUnsubscribePage(w, unsubscribeDonePage, s)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	jsonResponse(w, s, http.StatusOK)
+}
+
+var (
+	unsubscribeConfirmPage = template.Must(template.New("confirm").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Unsubscribe</title></head>
+<body>
+<p>Stop emailing {{.Email}} about new listings matching {{.Filters}}?</p>
+<form method="post"><button type="submit">Unsubscribe</button></form>
+</body>
+</html>
+`))
+	unsubscribeDonePage = template.Must(template.New("done").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Unsubscribed</title></head>
+<body>
+<p>{{.Email}} will no longer get alerts for {{.Filters}}.</p>
+</body>
+</html>
+`))
+)
+
+func writeUnsubscribePage(w http.ResponseWriter, page *template.Template, s SavedSearch) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	page.Execute(w, struct{ Email, Filters string }{s.Email, describeFilters(s.Filters)})
+}
+
+// verifySavedSearch marks the search verified and records what already
+// matches, so alerts only cover properties that become available later.
+func verifySavedSearch(ctx context.Context, s SavedSearch) error {
+	current, err := savedSearchMatches(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE saved_searches SET verified_at = ? WHERE id = ?", time.Now().UTC(), s.ID); err != nil {
+		return err
+	}
+	for _, p := range current {
+		if _, err := tx.Exec("INSERT INTO saved_search_matches (saved_search_id, property_id) VALUES (?, ?)", s.ID, p.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ============================================================================
+// ALERTS
+// ============================================================================
+
+// sendSavedSearchAlerts emails each verified search a digest of properties
+// that have started matching since the last run, and drops searches whose
+// verification link was never used.
+func sendSavedSearchAlerts(ctx context.Context) error {
+	if _, err := db.ExecContext(ctx, "DELETE FROM saved_searches WHERE verified_at IS NULL AND created_at < ?",
+		time.Now().Add(-savedSearchVerifyWindow).UTC()); err != nil {
+		return err
+	}
+	var errs []error
+	if err := resendVerificationEmails(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, email, filters, token, verified_at, last_sent_at, created_at
+		FROM saved_searches WHERE verified_at IS NOT NULL ORDER BY id
+	`)
+	if err != nil {
+		return err
+	}
+	var searches []SavedSearch
+	for rows.Next() {
+		s, err := scanSavedSearch(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		searches = append(searches, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	sent := 0
+	for _, s := range searches {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		ok, err := alertSavedSearch(ctx, s)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("saved search %d: %w", s.ID, err))
+		}
+		if ok {
+			sent++
+		}
+	}
+	if sent > 0 {
+		log.Printf("Saved searches: sent %d digests", sent)
+	}
+	return errors.Join(errs...)
+}
+
+// sendVerificationEmail mails the verification link and records that it
+// went out. Searches without verification_sent_at are retried by
+// resendVerificationEmails.
+func sendVerificationEmail(ctx context.Context, s SavedSearch) error {
+	if err := mailer.Send(ctx, verificationEmail(s)); err != nil {
+		return err
+	}
+	_, err := db.ExecContext(ctx, "UPDATE saved_searches SET verification_sent_at = ? WHERE id = ?", time.Now().UTC(), s.ID)
+	return err
+}
+
+// resendVerificationEmails retries verification emails that failed to send
+// when the search was saved.
+func resendVerificationEmails(ctx context.Context) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, email, filters, token, verified_at, last_sent_at, created_at
+		FROM saved_searches WHERE verified_at IS NULL AND verification_sent_at IS NULL ORDER BY id
+	`)
+	if err != nil {
+		return err
+	}
+	var pending []SavedSearch
+	for rows.Next() {
+		s, err := scanSavedSearch(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, s := range pending {
+		if err := sendVerificationEmail(ctx, s); err != nil {
+			errs = append(errs, fmt.Errorf("saved search %d verification: %w", s.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// alertSavedSearch sends one search's digest if anything new matches. The
+// new matches are only recorded once the email has gone out.
+func alertSavedSearch(ctx context.Context, s SavedSearch) (sent bool, err error) {
+	current, err := savedSearchMatches(ctx, s)
+	if err != nil {
+		return false, err
+	}
+
+	notified := map[int]bool{}
+	rows, err := db.QueryContext(ctx, "SELECT property_id FROM saved_search_matches WHERE saved_search_id = ?", s.ID)
+	if err != nil {
+		return false, err
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return false, err
+		}
+		notified[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	var fresh []Property
+	for _, p := range current {
+		if !notified[p.ID] {
+			fresh = append(fresh, p)
+		}
+		delete(notified, p.ID)
+	}
+	// Whatever is left no longer matches (let, unlisted or edited); forget it
+	// so it alerts again if it comes back.
+	for id := range notified {
+		if _, err := db.ExecContext(ctx, "DELETE FROM saved_search_matches WHERE saved_search_id = ? AND property_id = ?", s.ID, id); err != nil {
+			return false, err
+		}
+	}
+	if len(fresh) == 0 {
+		return false, nil
+	}
+
+	if err := mailer.Send(ctx, digestEmail(s, fresh)); err != nil {
+		return false, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return true, err
+	}
+	defer tx.Rollback()
+	now := time.Now().UTC()
+	for _, p := range fresh {
+		if _, err := tx.Exec("INSERT INTO saved_search_matches (saved_search_id, property_id, notified_at) VALUES (?, ?, ?)", s.ID, p.ID, now); err != nil {
+			return true, err
+		}
+	}
+	if _, err := tx.Exec("UPDATE saved_searches SET last_sent_at = ? WHERE id = ?", now, s.ID); err != nil {
+		return true, err
+	}
+	return true, tx.Commit()
+}
+
+// savedSearchMatches runs the search against available properties.
+func savedSearchMatches(ctx context.Context, s SavedSearch) ([]Property, error) {
+	q := savedSearchQuery(s.Filters)
+	q.Set("available", "true")
+	search, err := parsePropertySearch(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return search.run(db)
+}
+
+func savedSearchQuery(filters map[string]string) url.Values {
+	q := url.Values{}
+	for k, v := range filters {
+		q.Set(k, v)
+	}
+	return q
+}
+
+// ============================================================================
+// EMAILS
+// ============================================================================
+
+func savedSearchLink(s SavedSearch, action string) string {
+	return fmt.Sprintf("%s/api/saved-searches/%s/%s", strings.TrimSuffix(cfg.Mail.APIURL, "/"), s.token, action)
+}
+
+// describeFilters renders filters as "beds=2, maxRent=1800" for emails.
+func describeFilters(filters map[string]string) string {
+	if len(filters) == 0 {
+		return "all available listings"
+	}
+	keys := make([]string, 0, len(filters))
+	for k := range filters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + filters[k]
+	}
+	return strings.Join(parts, ", ")
+}
+
+func verificationEmail(s SavedSearch) MailMessage {
+	return MailMessage{
+		To:      s.Email,
+		Subject: "Confirm your listing alert",
+		Body: fmt.Sprintf(`Confirm that you want an email when a rental matching your search (%s) becomes available:
+
+%s
+
+If you did not ask for this, ignore this email and nothing will be sent.
+`, describeFilters(s.Filters), savedSearchLink(s, "verify")),
+	}
+}
+
+func digestEmail(s SavedSearch, properties []Property) MailMessage {
+	var b strings.Builder
+	fmt.Fprintf(&b, "New listings matching your search (%s):\n\n", describeFilters(s.Filters))
+	for _, p := range properties {
+		fmt.Fprintf(&b, "%s - %s, %s\n", p.Name, p.AddressLine1, p.City)
+		fmt.Fprintf(&b, "  $%.0f/month, %d bed, %g bath\n", p.MonthlyRent, p.Bedrooms, p.Bathrooms)
+		fmt.Fprintf(&b, "  %s\n\n", propertyURL(p))
+	}
+	fmt.Fprintf(&b, "Unsubscribe: %s\n", savedSearchLink(s, "unsubscribe"))
+
+	subject := "1 new listing matches your search"
+	if len(properties) > 1 {
+		subject = fmt.Sprintf("%d new listings match your search", len(properties))
+	}
+	return MailMessage{To: s.Email, Subject: subject, Body: b.String(), Headers: map[string]string{
+		"List-Unsubscribe":      "<" + savedSearchLink(s, "unsubscribe") + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}}
+}
+
+// ============================================================================
+// SCAN HELPERS - SAVED SEARCHES
+// ============================================================================
+
+func loadSavedSearch(token string) (SavedSearch, error) {
+	rows, err := db.Query(`
+		SELECT id, email, filters, token, verified_at, last_sent_at, created_at
+		FROM saved_searches WHERE token = ?
+	`, token)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return SavedSearch{}, err
+		}
+		return SavedSearch{}, sql.ErrNoRows
+	}
+	return scanSavedSearch(rows)
+}
+
+func scanSavedSearch(rows *sql.Rows) (SavedSearch, error) {
+	var s SavedSearch
+	var filtersJSON string
+	var verifiedAt, lastSentAt sql.NullTime
+
+	err := rows.Scan(&s.ID, &s.Email, &filtersJSON, &s.token, &verifiedAt, &lastSentAt, &s.CreatedAt)
+	if err != nil {
+		return s, err
+	}
+	s.Filters = map[string]string{}
+	json.Unmarshal([]byte(filtersJSON), &s.Filters)
+	s.Verified = verifiedAt.Valid
+	if lastSentAt.Valid {
+		s.LastSentAt = &lastSentAt.Time
+	}
+	return s, nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingMailer keeps sent messages for assertions.
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []MailMessage
+}
+
+func (*recordingMailer) Name() string { return "recording" }
+
+func (m *recordingMailer) Send(ctx context.Context, msg MailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// take returns and clears the messages sent so far.
+func (m *recordingMailer) take() []MailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sent := m.sent
+	m.sent = nil
+	return sent
+}
+
+func (e *testEnv) captureMail() *recordingMailer {
+	m := &recordingMailer{}
+	prev := mailer
+	mailer = m
+	e.t.Cleanup(func() { mailer = prev })
+	return m
+}
+
+var savedSearchLinkRE = regexp.MustCompile(`/api/saved-searches/[0-9a-f]+/(verify|unsubscribe)`)
+
+func TestSavedSearchAlerts(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	outbox := e.captureMail()
+	e.createListing(token, Property{Name: "Already Listed", Bedrooms: 2, MonthlyRent: 1500})
+
+	rec := e.do(http.MethodPost, "/api/saved-searches", "", map[string]interface{}{
+		"email":   "prospect@example.com",
+		"filters": map[string]string{"beds": "2", "maxRent": "1800"},
+	})
+	e.expect(rec, http.StatusCreated)
+	if s := decode[SavedSearch](t, rec); s.Verified {
+		t.Fatal("expected a new search to be unverified")
+	}
+	mails := outbox.take()
+	if len(mails) != 1 || mails[0].To != "prospect@example.com" {
+		t.Fatalf("expected one verification email, got %+v", mails)
+	}
+	verify := savedSearchLinkRE.FindString(mails[0].Body)
+	if !strings.HasSuffix(verify, "/verify") {
+		t.Fatalf("no verification link in %q", mails[0].Body)
+	}
+
+	alerts := func() []MailMessage {
+		t.Helper()
+		if err := sendSavedSearchAlerts(context.Background()); err != nil {
+			t.Fatalf("sendSavedSearchAlerts: %v", err)
+		}
+		return outbox.take()
+	}
+
+	// Nothing goes out before the address is verified.
+	e.createListing(token, Property{Name: "Early Bird", Bedrooms: 2, MonthlyRent: 1600})
+	if got := alerts(); len(got) != 0 {
+		t.Fatalf("expected no alerts before verification, got %d", len(got))
+	}
+
+	rec = e.do(http.MethodGet, verify, "", nil)
+	e.expect(rec, http.StatusOK)
+	if s := decode[SavedSearch](t, rec); !s.Verified || s.Filters["maxRent"] != "1800" {
+		t.Fatalf("unexpected verified search: %+v", s)
+	}
+	// Listings that matched at verification time are not news.
+	if got := alerts(); len(got) != 0 {
+		t.Fatalf("expected no alerts for existing listings, got %+v", got)
+	}
+
+	fresh := e.createListing(token, Property{Name: "Fresh Flat", Bedrooms: 2, MonthlyRent: 1700})
+	e.createListing(token, Property{Name: "Too Pricey", Bedrooms: 2, MonthlyRent: 3000})
+	got := alerts()
+	if len(got) != 1 || !strings.Contains(got[0].Body, "Fresh Flat") || strings.Contains(got[0].Body, "Too Pricey") {
+		t.Fatalf("expected a digest with Fresh Flat only, got %+v", got)
+	}
+	if !strings.Contains(got[0].Body, "/properties/") || !strings.Contains(got[0].Body, "/unsubscribe") {
+		t.Fatalf("digest missing listing or unsubscribe link: %q", got[0].Body)
+	}
+	if h := got[0].Headers; !strings.HasSuffix(h["List-Unsubscribe"], "/unsubscribe>") || h["List-Unsubscribe-Post"] != "List-Unsubscribe=One-Click" {
+		t.Fatalf("digest missing one-click unsubscribe headers: %+v", h)
+	}
+	if again := alerts(); len(again) != 0 {
+		t.Fatalf("expected no repeat digest, got %d", len(again))
+	}
+
+	// Coming back on the market alerts again.
+	path := fmt.Sprintf("/api/admin/properties/%d", fresh.ID)
+	fresh.Available = false
+	e.expect(e.do(http.MethodPut, path, token, fresh), http.StatusOK)
+	if got := alerts(); len(got) != 0 {
+		t.Fatalf("expected no alert for a let property, got %d", len(got))
+	}
+	fresh.Available = true
+	e.expect(e.do(http.MethodPut, path, token, fresh), http.StatusOK)
+	if got := alerts(); len(got) != 1 || !strings.Contains(got[0].Body, "Fresh Flat") {
+		t.Fatalf("expected a digest when Fresh Flat is relisted, got %+v", got)
+	}
+
+	// Following the link only asks; link scanners mustn't unsubscribe anyone.
+	unsubscribe := strings.TrimSuffix(verify, "verify") + "unsubscribe"
+	rec = e.do(http.MethodGet, unsubscribe, "", nil)
+	e.expect(rec, http.StatusOK)
+	if !strings.Contains(rec.Body.String(), `<form method="post">`) {
+		t.Fatalf("expected a confirmation form, got %q", rec.Body.String())
+	}
+	e.expect(e.do(http.MethodGet, strings.TrimSuffix(verify, "/verify"), "", nil), http.StatusOK)
+	e.expect(e.do(http.MethodPost, unsubscribe, "", nil), http.StatusNoContent)
+	e.expect(e.do(http.MethodGet, strings.TrimSuffix(verify, "/verify"), "", nil), http.StatusNotFound)
+}
+
+func TestSavedSearchValidation(t *testing.T) {
+	e := newTestEnv(t)
+	outbox := e.captureMail()
+
+	for _, body := range []map[string]interface{}{
+		{"email": "not-an-email", "filters": map[string]string{}},
+		{"email": "a@example.com", "filters": map[string]string{"available": "false"}},
+		{"email": "a@example.com", "filters": map[string]string{"pets": "ferrets"}},
+		{"email": "a@example.com", "filters": map[string]string{"near": "12345"}},
+	} {
+		e.expect(e.do(http.MethodPost, "/api/saved-searches", "", body), http.StatusBadRequest)
+	}
+	if sent := outbox.take(); len(sent) != 0 {
+		t.Fatalf("expected no mail for rejected searches, got %d", len(sent))
+	}
+	e.expect(e.do(http.MethodGet, "/api/saved-searches/nope/verify", "", nil), http.StatusNotFound)
+
+	// Unverified searches expire.
+	db.Exec(`INSERT INTO saved_searches (email, filters, token, created_at) VALUES ('old@example.com', '{}', 'stale', '2020-01-01 00:00:00')`)
+	if err := sendSavedSearchAlerts(context.Background()); err != nil {
+		t.Fatalf("sendSavedSearchAlerts: %v", err)
+	}
+	e.expect(e.do(http.MethodGet, "/api/saved-searches/stale", "", nil), http.StatusNotFound)
+}
+
+// failingMailer refuses every message.
+type failingMailer struct{}
+
+func (failingMailer) Name() string { return "failing" }
+
+func (failingMailer) Send(ctx context.Context, msg MailMessage) error {
+	return fmt.Errorf("smtp unavailable")
+}
+
+func TestSavedSearchVerificationRetry(t *testing.T) {
+	e := newTestEnv(t)
+	outbox := e.captureMail()
+	mailer = failingMailer{}
+
+	// The search is kept even though the email didn't go out.
+	e.expect(e.do(http.MethodPost, "/api/saved-searches", "", map[string]interface{}{
+		"email": "prospect@example.com", "filters": map[string]string{"beds": "2"},
+	}), http.StatusCreated)
+	if err := sendSavedSearchAlerts(context.Background()); err == nil || !strings.Contains(err.Error(), "smtp unavailable") {
+		t.Fatalf("expected the retry to fail, got %v", err)
+	}
+
+	mailer = outbox
+	if err := sendSavedSearchAlerts(context.Background()); err != nil {
+		t.Fatalf("sendSavedSearchAlerts: %v", err)
+	}
+	if sent := outbox.take(); len(sent) != 1 || !savedSearchLinkRE.MatchString(sent[0].Body) {
+		t.Fatalf("expected the verification email to be resent, got %+v", sent)
+	}
+	if err := sendSavedSearchAlerts(context.Background()); err != nil {
+		t.Fatalf("sendSavedSearchAlerts: %v", err)
+	}
+	if sent := outbox.take(); len(sent) != 0 {
+		t.Fatalf("expected no further resends, got %d", len(sent))
+	}
+}
+
+func TestFileMailerWritesEML(t *testing.T) {
+	dir := t.TempDir()
+	m, err := newMailer(MailConfig{Provider: mailerFile, From: "listings@example.com", Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(context.Background(), MailMessage{To: "a@example.com", Subject: "Hi", Body: "line one\nline two"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("expected one .eml file, got %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	for _, want := range []string{"From: listings@example.com\r\n", "To: a@example.com\r\n", "Subject: Hi\r\n", "\r\n\r\nline one\r\nline two"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("missing %q in %q", want, data)
+		}
+	}
+
+	if _, err := newMailer(MailConfig{Provider: "pigeon"}); err == nil {
+		t.Fatal("expected an unknown provider to be rejected")
+	}
+}
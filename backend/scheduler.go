@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// BACKGROUND JOBS
+// ============================================================================
+
+// scheduledJob runs every Interval until the scheduler's context is cancelled.
+// Run should honour ctx so shutdown is not held up by a slow query.
+type scheduledJob struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// jobStatus records the outcome of a job's most recent runs.
+type jobStatus struct {
+	LastRun     time.Time `json:"lastRun"`
+	LastSuccess time.Time `json:"lastSuccess"`
+	LastError   string    `json:"lastError,omitempty"`
+}
+
+var (
+	jobStatuses = make(map[string]jobStatus)
+	jobMutex    sync.RWMutex
+)
+
+// backgroundJobs lists everything the server runs on a timer.
+func backgroundJobs() []scheduledJob {
+	return []scheduledJob{
+		{Name: "lease-statuses", Interval: cfg.Jobs.LeaseStatusInterval, Run: updateLeaseStatuses},
+		{Name: "search-index", Interval: cfg.Jobs.SearchIndexInterval, Run: refreshSearchIndex},
+		{Name: "saved-search-alerts", Interval: cfg.Jobs.SavedSearchInterval, Run: sendSavedSearchAlerts},
+		{Name: "trash-purge", Interval: cfg.Jobs.TrashPurgeInterval, Run: purgeTrash},
+		{Name: "rent-changes", Interval: cfg.Jobs.RentChangeInterval, Run: applyScheduledRentChanges},
+	}
+}
+
+// startScheduler runs each job once immediately and then on its interval.
+// The returned function blocks until every job has returned after ctx is
+// cancelled.
+func startScheduler(ctx context.Context, jobs []scheduledJob) (wait func()) {
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job scheduledJob) {
+			defer wg.Done()
+			ticker := time.NewTicker(job.Interval)
+			defer ticker.Stop()
+
+			for {
+				runJob(ctx, job)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(job)
+	}
+	return wg.Wait
+}
+
+func runJob(ctx context.Context, job scheduledJob) {
+	err := job.Run(ctx)
+
+	jobMutex.Lock()
+	status := jobStatuses[job.Name]
+	status.LastRun = time.Now()
+	if err != nil {
+		status.LastError = err.Error()
+	} else {
+		status.LastSuccess = status.LastRun
+		status.LastError = ""
+	}
+	jobStatuses[job.Name] = status
+	jobMutex.Unlock()
+
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Background job %s failed: %v", job.Name, err)
+	}
+}
+
+// snapshotJobStatuses returns a copy of every job's status.
+func snapshotJobStatuses() map[string]jobStatus {
+	jobMutex.RLock()
+	defer jobMutex.RUnlock()
+	out := make(map[string]jobStatus, len(jobStatuses))
+	for name, status := range jobStatuses {
+		out[name] = status
+	}
+	return out
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchedulerRunsAndStops(t *testing.T) {
+	var runs atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	wait := startScheduler(ctx, []scheduledJob{{
+		Name:     "test-tick",
+		Interval: 10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	}})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runs.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	wait()
+
+	if runs.Load() < 3 {
+		t.Fatalf("expected the job to run repeatedly, ran %d times", runs.Load())
+	}
+	stopped := runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	if runs.Load() != stopped {
+		t.Fatal("job kept running after the scheduler was cancelled")
+	}
+
+	status := snapshotJobStatuses()["test-tick"]
+	if status.LastSuccess.IsZero() || status.LastError != "" {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
+func TestSchedulerRecordsFailures(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	wait := startScheduler(ctx, []scheduledJob{{
+		Name:     "test-fail",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			defer close(done)
+			return errors.New("boom")
+		},
+	}})
+	<-done
+	cancel()
+	wait()
+
+	status := snapshotJobStatuses()["test-fail"]
+	if status.LastError != "boom" || !status.LastSuccess.IsZero() || status.LastRun.IsZero() {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFakeScreeningProviderIsDeterministic(t *testing.T) {
+	p := fakeScreeningProvider{}
+	a, _ := p.Screen(context.Background(), ScreeningSubject{Email: "Jo@Example.com"})
+	b, _ := p.Screen(context.Background(), ScreeningSubject{Email: "jo@example.com"})
+	if a != b {
+		t.Fatalf("expected identical reports, got %+v and %+v", a, b)
+	}
+	if a.CreditScore < 640 || a.CreditScore > 849 || a.EvictionRecords != 0 || a.CriminalRecords != 0 {
+		t.Fatalf("unexpected clean report: %+v", a)
+	}
+
+	tagged, _ := p.Screen(context.Background(), ScreeningSubject{Email: "jo+lowcredit+eviction+criminal@example.com"})
+	if tagged.CreditScore != 540 || tagged.EvictionRecords != 1 || tagged.CriminalRecords != 1 {
+		t.Fatalf("tags not applied: %+v", tagged)
+	}
+}
+
+func TestRecommendScreening(t *testing.T) {
+	c := ScreeningConfig{MinIncomeRatio: 3, MinCreditScore: 620}
+	tests := []struct {
+		name      string
+		report    ScreeningReport
+		income    float64
+		want      string
+		reasonHas string
+	}{
+		{"pass", ScreeningReport{CreditScore: 700}, 4500, "pass", ""},
+		{"low income", ScreeningReport{CreditScore: 700}, 4000, "fail", "Income is 2.67x"},
+		{"low credit", ScreeningReport{CreditScore: 600}, 6000, "fail", "Credit score 600"},
+		{"eviction", ScreeningReport{CreditScore: 700, EvictionRecords: 1}, 6000, "fail", "eviction"},
+		{"criminal needs review", ScreeningReport{CreditScore: 700, CriminalRecords: 1}, 6000, "pass", "review individually"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got, reasons := recommendScreening(tt.report, tt.income, 1500, c)
+			if got != tt.want {
+				t.Fatalf("expected %s, got %s (%v)", tt.want, got, reasons)
+			}
+			if tt.reasonHas != "" && !strings.Contains(strings.Join(reasons, "; "), tt.reasonHas) {
+				t.Fatalf("expected reason containing %q, got %v", tt.reasonHas, reasons)
+			}
+		})
+	}
+}
+
+func TestApplicationFeeAndScreening(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Screened Place", 2000)
+
+	// validApplication has 6000 + 2500 household income, 4.25x this rent.
+	app := e.submitApplication(pid, "casey+eviction@example.com")
+	if app.ApplicationFee != cfg.Screening.ApplicationFee || app.FeePaidAt != nil {
+		t.Fatalf("expected unpaid fee of %.2f, got %+v", cfg.Screening.ApplicationFee, app)
+	}
+	path := fmt.Sprintf("/api/admin/applications/%d", app.ID)
+
+	e.expect(e.do(http.MethodPost, path+"/screen", token, nil), http.StatusConflict)
+
+	rec := e.do(http.MethodPost, path+"/fee", token, map[string]string{"reference": "cash #12"})
+	e.expect(rec, http.StatusOK)
+	if paid := decode[RentalApplication](t, rec); paid.FeePaidAt == nil || *paid.FeeReference != "cash #12" {
+		t.Fatalf("fee not recorded: %+v", paid)
+	}
+	e.expect(e.do(http.MethodPost, path+"/fee", token, map[string]string{}), http.StatusConflict)
+
+	rec = e.do(http.MethodPost, path+"/screen", token, nil)
+	e.expect(rec, http.StatusOK)
+	screened := decode[RentalApplication](t, rec)
+	s := screened.Screening
+	if screened.Status != "screening" || s == nil {
+		t.Fatalf("expected screening result, got %+v", screened)
+	}
+	if s.Provider != "fake" || s.EvictionRecords != 1 || s.IncomeToRent != 4.25 || s.Recommendation != "fail" {
+		t.Fatalf("unexpected screening result: %+v", s)
+	}
+
+	// The stored summary is returned with the application detail.
+	detail := decode[RentalApplication](t, e.do(http.MethodGet, path, token, nil))
+	if detail.Screening == nil || detail.Screening.ReportID != s.ReportID || len(detail.Screening.Reasons) != 1 {
+		t.Fatalf("screening not persisted: %+v", detail.Screening)
+	}
+
+	// Waived fees skip the payment requirement.
+	cfg.Screening.ApplicationFee = 0
+	clean := e.submitApplication(pid, "casey@example.com")
+	rec = e.do(http.MethodPost, fmt.Sprintf("/api/admin/applications/%d/screen", clean.ID), token, nil)
+	e.expect(rec, http.StatusOK)
+	if got := decode[RentalApplication](t, rec).Screening.Recommendation; got != "pass" {
+		t.Fatalf("expected pass, got %s", got)
+	}
+}
@@ -0,0 +1,669 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// GEOCODING
+// ============================================================================
+
+// Geocoder turns a property address into coordinates for radius search.
+type Geocoder interface {
+	Name() string
+	// Geocode reports ok=false when the address cannot be placed.
+	Geocode(ctx context.Context, addr GeoAddress) (point GeoPoint, ok bool, err error)
+}
+
+// GeoAddress is the part of a property address a geocoder needs.
+type GeoAddress struct {
+	Line1 string
+	City  string
+	State string
+	Zip   string
+}
+
+type GeoPoint struct {
+	Lat float64
+	Lng float64
+}
+
+const (
+	geocoderOffline   = "offline"
+	geocoderNominatim = "nominatim"
+)
+
+// geocoder is selected from cfg.Geocoder.Provider at startup.
+var geocoder Geocoder = offlineGeocoder{}
+
+func newGeocoder(c GeocoderConfig) (Geocoder, error) {
+	switch c.Provider {
+	case geocoderOffline:
+		return offlineGeocoder{}, nil
+	case geocoderNominatim:
+		if c.URL == "" {
+			return nil, fmt.Errorf("provider %q needs a URL", geocoderNominatim)
+		}
+		if c.UserAgent == "" {
+			// Nominatim's usage policy requires an identifying User-Agent.
+			return nil, fmt.Errorf("provider %q needs a user agent", geocoderNominatim)
+		}
+		return &nominatimGeocoder{
+			baseURL:   strings.TrimSuffix(c.URL, "/"),
+			userAgent: c.UserAgent,
+			client:    &http.Client{Timeout: c.Timeout},
+			cache:     map[GeoAddress]nominatimResult{},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q (supported: %s, %s)", c.Provider, geocoderOffline, geocoderNominatim)
+	}
+}
+
+// fiveDigitZip drops a ZIP+4 suffix.
+func fiveDigitZip(zip string) string {
+	zip = strings.TrimSpace(zip)
+	if len(zip) > 5 {
+		zip = zip[:5]
+	}
+	return zip
+}
+
+// offlineGeocoder places an address at its ZIP code's centroid using a
+// built-in table of the Portland metro ZIPs we list in. It needs no network
+// access, which makes it the stand-in for tests and local development.
+type offlineGeocoder struct{}
+
+func (offlineGeocoder) Name() string { return geocoderOffline }
+
+func (offlineGeocoder) Geocode(ctx context.Context, addr GeoAddress) (GeoPoint, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return GeoPoint{}, false, err
+	}
+	p, ok := zipCentroids[fiveDigitZip(addr.Zip)]
+	return p, ok, nil
+}
+
+// nominatimGeocoder looks addresses up with the Nominatim search API
+// (OpenStreetMap), either the public service or a self-hosted one. It places
+// full street addresses as well as the bare ZIP codes of near= searches.
+// Answers are cached, since public searches repeat the same few ZIPs and
+// the public service allows about one request a second.
+type nominatimGeocoder struct {
+	baseURL   string
+	userAgent string
+	client    *http.Client
+
+	mu    sync.Mutex
+	cache map[GeoAddress]nominatimResult
+}
+
+type nominatimResult struct {
+	point GeoPoint
+	ok    bool
+}
+
+// maxGeocodeCache bounds the cache; near= takes arbitrary input.
+const maxGeocodeCache = 1000
+
+func (*nominatimGeocoder) Name() string { return geocoderNominatim }
+
+func (g *nominatimGeocoder) Geocode(ctx context.Context, addr GeoAddress) (GeoPoint, bool, error) {
+	addr.Zip = fiveDigitZip(addr.Zip)
+	g.mu.Lock()
+	cached, hit := g.cache[addr]
+	g.mu.Unlock()
+	if hit {
+		return cached.point, cached.ok, nil
+	}
+
+	params := url.Values{"format": {"jsonv2"}, "limit": {"1"}, "countrycodes": {"us"}}
+	for key, value := range map[string]string{"street": addr.Line1, "city": addr.City, "state": addr.State, "postalcode": addr.Zip} {
+		if value = strings.TrimSpace(value); value != "" {
+			params.Set(key, value)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return GeoPoint{}, false, err
+	}
+	req.Header.Set("User-Agent", g.userAgent)
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return GeoPoint{}, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return GeoPoint{}, false, fmt.Errorf("nominatim: %s", resp.Status)
+	}
+
+	var places []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&places); err != nil {
+		return GeoPoint{}, false, fmt.Errorf("nominatim: %w", err)
+	}
+	var result nominatimResult
+	if len(places) > 0 {
+		lat, errLat := strconv.ParseFloat(places[0].Lat, 64)
+		lng, errLng := strconv.ParseFloat(places[0].Lon, 64)
+		if errLat != nil || errLng != nil {
+			return GeoPoint{}, false, fmt.Errorf("nominatim: bad coordinates %q, %q", places[0].Lat, places[0].Lon)
+		}
+		result = nominatimResult{point: GeoPoint{Lat: lat, Lng: lng}, ok: true}
+	}
+
+	g.mu.Lock()
+	if len(g.cache) >= maxGeocodeCache {
+		g.cache = map[GeoAddress]nominatimResult{}
+	}
+	g.cache[addr] = result
+	g.mu.Unlock()
+	return result.point, result.ok, nil
+}
+
+// zipCentroids are approximate ZIP code centroids.
+var zipCentroids = map[string]GeoPoint{
+	"97005": {45.4918, -122.8040}, // Beaverton
+	"97030": {45.5089, -122.4337}, // Gresham
+	"97034": {45.4087, -122.6849}, // Lake Oswego
+	"97035": {45.4158, -122.7242}, // Lake Oswego
+	"97123": {45.4435, -122.9744}, // Hillsboro
+	"97201": {45.5076, -122.6905},
+	"97202": {45.4847, -122.6366},
+	"97203": {45.6016, -122.7383},
+	"97204": {45.5183, -122.6746},
+	"97205": {45.5206, -122.6924},
+	"97206": {45.4824, -122.5976},
+	"97209": {45.5310, -122.6839},
+	"97210": {45.5433, -122.7270},
+	"97211": {45.5784, -122.6369},
+	"97212": {45.5444, -122.6435},
+	"97213": {45.5372, -122.5993},
+	"97214": {45.5145, -122.6427},
+	"97215": {45.5151, -122.6002},
+	"97217": {45.5876, -122.6908},
+	"97219": {45.4577, -122.7074},
+	"97220": {45.5502, -122.5580},
+	"97221": {45.4974, -122.7283},
+	"97223": {45.4401, -122.7741}, // Tigard
+	"97227": {45.5426, -122.6769},
+	"97232": {45.5289, -122.6440},
+	"97239": {45.4926, -122.6910},
+	"98660": {45.6410, -122.6842}, // Vancouver, WA
+}
+
+const earthRadiusMiles = 3958.8
+
+// haversineMiles is the great-circle distance between two points.
+func haversineMiles(a, b GeoPoint) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(b.Lat - a.Lat)
+	dLng := rad(b.Lng - a.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(a.Lat))*math.Cos(rad(b.Lat))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMiles * math.Asin(math.Sqrt(h))
+}
+
+// locateProperty fills in p's coordinates from its address when the request
+// did not supply them. A geocoder failure leaves them empty; the search
+// index job retries later.
+func locateProperty(ctx context.Context, p *Property) {
+	if p.Latitude != nil && p.Longitude != nil {
+		return
+	}
+	p.Latitude, p.Longitude = nil, nil
+	point, ok, err := geocoder.Geocode(ctx, GeoAddress{Line1: p.AddressLine1, City: p.City, State: p.State, Zip: p.Zip})
+	if err != nil {
+		log.Printf("Error geocoding %q: %v", p.Zip, err)
+		return
+	}
+	if ok {
+		p.Latitude, p.Longitude = &point.Lat, &point.Lng
+	}
+}
+
+// ============================================================================
+// SEARCH INDEX
+// ============================================================================
+
+// Weights per field: a match in the name counts for more than one buried in
+// the description.
+const (
+	searchWeightName        = 3
+	searchWeightAmenities   = 2
+	searchWeightDescription = 1
+
+	maxSearchTermLength = 64
+)
+
+var searchStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "at": true, "by": true,
+	"for": true, "from": true, "in": true, "is": true, "it": true, "of": true,
+	"on": true, "or": true, "the": true, "to": true, "with": true,
+}
+
+// jsonText encodes v the way MySQL keeps a JSON column: without
+// json.Marshal's HTML escaping, so "&" stays "&" rather than "\u0026".
+// Values written with it can be matched as text.
+func jsonText(v interface{}) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// searchTokens splits text into lowercase search terms, dropping stopwords
+// and folding simple plurals so "floors" finds "floor".
+func searchTokens(text string) []string {
+	var tokens []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if utf8.RuneCountInString(word) < 2 || searchStopwords[word] {
+			continue
+		}
+		switch {
+		case len(word) > 4 && strings.HasSuffix(word, "ies"):
+			word = strings.TrimSuffix(word, "ies") + "y"
+		case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+			word = strings.TrimSuffix(word, "s")
+		}
+		// Cut by characters, not bytes, so a multi-byte letter is never
+		// split. Terms match as prefixes, so a cut term still finds the word.
+		if runes := []rune(word); len(runes) > maxSearchTermLength {
+			word = string(runes[:maxSearchTermLength])
+		}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// TextSearch matches and ranks listings against the terms of a q search.
+// Production uses MySQL's FULLTEXT indexes; the test suite, which runs on
+// SQLite, substitutes its own implementation.
+type TextSearch interface {
+	// Index brings a property's entry up to date after its name,
+	// description or amenities change.
+	Index(ex sqlExecutor, propertyID int) error
+	// Backfill indexes properties that have no entry and reports how many.
+	Backfill(ctx context.Context) (int, error)
+	// Match returns a condition on properties that holds when every term
+	// matches, each as a prefix of a word.
+	Match(terms []string) (cond string, args []interface{})
+	// Rank returns an expression to sort matches by, best first.
+	Rank(terms []string) (expr string, args []interface{})
+}
+
+var textSearch TextSearch = mysqlFullText{}
+
+// indexPropertySearch updates a property's search entry; call it in the
+// transaction that writes the property.
+func indexPropertySearch(ex sqlExecutor, propertyID int) error {
+	return textSearch.Index(ex, propertyID)
+}
+
+// mysqlFullText searches the FULLTEXT indexes from migration 0022. MySQL
+// keeps them current on every write, so there is nothing to index.
+type mysqlFullText struct{}
+
+func (mysqlFullText) Index(sqlExecutor, int) error { return nil }
+
+func (mysqlFullText) Backfill(context.Context) (int, error) { return 0, nil }
+
+// Match requires every term in boolean mode. The trailing * makes each a
+// prefix and keeps MySQL from dropping terms that are short or stopwords.
+func (mysqlFullText) Match(terms []string) (string, []interface{}) {
+	words := make([]string, len(terms))
+	for i, term := range terms {
+		words[i] = "+" + term + "*"
+	}
+	return "MATCH(name, description, amenities_text) AGAINST (? IN BOOLEAN MODE)",
+		[]interface{}{strings.Join(words, " ")}
+}
+
+// Rank weighs relevance in the name and amenities above the overall score,
+// which is what a description match contributes.
+func (mysqlFullText) Rank(terms []string) (string, []interface{}) {
+	words := make([]string, len(terms))
+	for i, term := range terms {
+		words[i] = term + "*"
+	}
+	q := strings.Join(words, " ")
+	expr := fmt.Sprintf(`MATCH(name) AGAINST (? IN BOOLEAN MODE) * %d
+		+ MATCH(amenities_text) AGAINST (? IN BOOLEAN MODE) * %d
+		+ MATCH(name, description, amenities_text) AGAINST (? IN BOOLEAN MODE) * %d`,
+		searchWeightName-searchWeightDescription, searchWeightAmenities-searchWeightDescription, searchWeightDescription)
+	return expr, []interface{}{q, q, q}
+}
+
+// refreshSearchIndex backfills the text search index where it needs one,
+// retries geocoding properties without coordinates and slugs properties
+// from before slugs existed.
+func refreshSearchIndex(ctx context.Context) error {
+	if _, err := backfillPropertySlugs(); err != nil {
+		return err
+	}
+
+	indexed, err := textSearch.Backfill(ctx)
+	if err != nil {
+		return err
+	}
+
+	type pending struct {
+		id   int
+		addr GeoAddress
+	}
+	var missing []pending
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, address_line1, city, state, zip FROM properties
+		WHERE latitude IS NULL OR longitude IS NULL
+	`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.addr.Line1, &p.addr.City, &p.addr.State, &p.addr.Zip); err != nil {
+			rows.Close()
+			return err
+		}
+		missing = append(missing, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	located := 0
+	for _, p := range missing {
+		point, ok, err := geocoder.Geocode(ctx, p.addr)
+		if err != nil {
+			return fmt.Errorf("geocode property %d: %w", p.id, err)
+		}
+		if !ok {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, "UPDATE properties SET latitude = ?, longitude = ? WHERE id = ?", point.Lat, point.Lng, p.id); err != nil {
+			return err
+		}
+		located++
+	}
+
+	if indexed > 0 || located > 0 {
+		log.Printf("Search index: indexed %d properties, geocoded %d", indexed, located)
+	}
+	return nil
+}
+
+// ============================================================================
+// LISTING SEARCH
+// ============================================================================
+
+const (
+	defaultSearchRadiusMiles = 10
+	maxSearchRadiusMiles     = 100
+)
+
+// searchQueryError is a listing search rejected because of its parameters;
+// handlers report it as a 400.
+type searchQueryError string
+
+func (e searchQueryError) Error() string { return string(e) }
+
+// propertySearch is a parsed public listing query: SQL conditions plus the
+// full-text terms and radius, which need work beyond a WHERE clause.
+type propertySearch struct {
+	where  []string
+	args   []interface{}
+	terms  []string
+	origin *GeoPoint
+	radius float64
+}
+
+func (s *propertySearch) filter(cond string, args ...interface{}) {
+	s.where = append(s.where, cond)
+	s.args = append(s.args, args...)
+}
+
+// parsePropertySearch reads the public listing filters. The original filters
+// (available, beds, minRent, maxRent, search, type) ignore malformed values as
+// they always have; the newer ones reject them with a searchQueryError.
+func parsePropertySearch(ctx context.Context, q url.Values) (*propertySearch, error) {
+	s := &propertySearch{}
+	s.filter("listing_status = ?", listingListed)
+
+	switch q.Get("available") {
+	case "true":
+		s.filter("available = TRUE")
+	case "false":
+		s.filter("available = FALSE")
+	}
+	if beds, err := strconv.Atoi(q.Get("beds")); err == nil {
+		s.filter("bedrooms >= ?", beds)
+	}
+	if minRent, err := strconv.ParseFloat(q.Get("minRent"), 64); err == nil {
+		s.filter("monthly_rent >= ?", minRent)
+	}
+	if maxRent, err := strconv.ParseFloat(q.Get("maxRent"), 64); err == nil {
+		s.filter("monthly_rent <= ?", maxRent)
+	}
+	if search := q.Get("search"); search != "" {
+		pattern := "%" + search + "%"
+		s.filter("(name LIKE ? OR address_line1 LIKE ? OR city LIKE ?)", pattern, pattern, pattern)
+	}
+	if propType := q.Get("type"); propType != "" {
+		s.filter("property_type = ?", propType)
+	}
+
+	numeric := []struct {
+		param, cond string
+		integer     bool
+	}{
+		{"maxBeds", "bedrooms <= ?", true},
+		{"minBaths", "bathrooms >= ?", false},
+		{"minSqft", "square_feet >= ?", true},
+		{"maxSqft", "square_feet <= ?", true},
+	}
+	for _, n := range numeric {
+		raw := q.Get(n.param)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 || (n.integer && v != math.Trunc(v)) {
+			return nil, searchQueryError(fmt.Sprintf("%s must be a non-negative number", n.param))
+		}
+		s.filter(n.cond, v)
+	}
+
+	// Amenities are stored as a JSON array; match each requested one as a
+	// complete quoted element, case-insensitively.
+	for _, amenity := range splitList(q.Get("amenities")) {
+		quoted := jsonText(strings.ToLower(amenity))
+		s.filter("LOWER(amenities) LIKE ? ESCAPE '!'", "%"+escapeLike(quoted)+"%")
+	}
+
+	if raw := q.Get("availableBy"); raw != "" {
+		if _, err := time.Parse("2006-01-02", raw); err != nil {
+			return nil, searchQueryError("availableBy must be YYYY-MM-DD")
+		}
+		s.filter("(available_date IS NULL OR available_date <= ?)", raw)
+	}
+
+	switch q.Get("pets") {
+	case "":
+	case "cats":
+		s.filter("pet_policy IN ('cats', 'cats_and_dogs')")
+	case "dogs":
+		s.filter("pet_policy IN ('dogs', 'cats_and_dogs')")
+	default:
+		return nil, searchQueryError("pets must be cats or dogs")
+	}
+
+	s.terms = searchTokens(q.Get("q"))
+	if len(s.terms) > 0 {
+		cond, args := textSearch.Match(s.terms)
+		s.filter(cond, args...)
+	}
+
+	if err := s.parseRadius(ctx, q); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// parseRadius handles near=ZIP or lat/lng, with radius in miles. The SQL
+// condition is a bounding box; run trims it to the circle.
+func (s *propertySearch) parseRadius(ctx context.Context, q url.Values) error {
+	near, rawLat, rawLng := q.Get("near"), q.Get("lat"), q.Get("lng")
+	switch {
+	case near != "":
+		point, ok, err := geocoder.Geocode(ctx, GeoAddress{Zip: near})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return searchQueryError(fmt.Sprintf("Unknown location %q", near))
+		}
+		s.origin = &point
+	case rawLat != "" || rawLng != "":
+		lat, errLat := strconv.ParseFloat(rawLat, 64)
+		lng, errLng := strconv.ParseFloat(rawLng, 64)
+		if errLat != nil || errLng != nil || math.Abs(lat) > 90 || math.Abs(lng) > 180 {
+			return searchQueryError("lat and lng must both be valid coordinates")
+		}
+		s.origin = &GeoPoint{Lat: lat, Lng: lng}
+	default:
+		if q.Get("radius") != "" {
+			return searchQueryError("radius requires near or lat/lng")
+		}
+		return nil
+	}
+
+	s.radius = defaultSearchRadiusMiles
+	if raw := q.Get("radius"); raw != "" {
+		r, err := strconv.ParseFloat(raw, 64)
+		if err != nil || r <= 0 || r > maxSearchRadiusMiles {
+			return searchQueryError(fmt.Sprintf("radius must be between 0 and %d miles", maxSearchRadiusMiles))
+		}
+		s.radius = r
+	}
+
+	dLat := s.radius / 69.0
+	dLng := s.radius / (69.0 * math.Max(math.Cos(s.origin.Lat*math.Pi/180), 0.01))
+	s.filter("latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?",
+		s.origin.Lat-dLat, s.origin.Lat+dLat, s.origin.Lng-dLng, s.origin.Lng+dLng)
+	return nil
+}
+
+// run returns matching properties, best text match first when there is a
+// q, otherwise nearest first for radius searches, otherwise available and
+// cheapest first.
+func (s *propertySearch) run(ex sqlExecutor) ([]Property, error) {
+	query := `
+		SELECT ` + propertyColumns + `
+		FROM properties WHERE 1=1
+	`
+	for _, cond := range s.where {
+		query += " AND " + cond
+	}
+	args := append([]interface{}{}, s.args...)
+
+	query += " ORDER BY "
+	if len(s.terms) > 0 {
+		rank, rankArgs := textSearch.Rank(s.terms)
+		query += "(" + rank + ") DESC, "
+		args = append(args, rankArgs...)
+	}
+	query += "available DESC, monthly_rent ASC"
+
+	rows, err := ex.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	properties := []Property{}
+	for rows.Next() {
+		p, err := scanProperty(rows)
+		if err != nil {
+			log.Printf("Error scanning property: %v", err)
+			continue
+		}
+		if s.origin != nil {
+			if p.Latitude == nil || p.Longitude == nil {
+				continue
+			}
+			d := haversineMiles(*s.origin, GeoPoint{Lat: *p.Latitude, Lng: *p.Longitude})
+			if d > s.radius {
+				continue
+			}
+			d = math.Round(d*10) / 10
+			p.DistanceMiles = &d
+		}
+		properties = append(properties, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if s.origin != nil && len(s.terms) == 0 {
+		sort.SliceStable(properties, func(i, j int) bool {
+			return *properties[i].DistanceMiles < *properties[j].DistanceMiles
+		})
+	}
+	return properties, nil
+}
+
+// escapeLike escapes LIKE wildcards for use with ESCAPE '!'.
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// validatePropertyListing checks the search-related property fields and
+// returns a client-facing message, or "" when they are valid.
+func validatePropertyListing(p *Property) string {
+	if p.ListingStatus != "" && !validListingStatus(p.ListingStatus) {
+		return "Listing status must be draft, listed, unlisted, or archived"
+	}
+	if p.PetPolicy != nil {
+		switch *p.PetPolicy {
+		case "none", "cats", "dogs", "cats_and_dogs":
+		default:
+			return "Pet policy must be none, cats, dogs, or cats_and_dogs"
+		}
+	}
+	if (p.Latitude == nil) != (p.Longitude == nil) ||
+		(p.Latitude != nil && (math.Abs(*p.Latitude) > 90 || math.Abs(*p.Longitude) > 180)) {
+		return "Latitude and longitude must be given together and be valid coordinates"
+	}
+	if p.OwnerID != nil && *p.OwnerID != 0 && !ownerExists(*p.OwnerID) {
+		return "Owner not found"
+	}
+	return ""
+}
+
+func writeSearchError(w http.ResponseWriter, err error) {
+	var bad searchQueryError
+	if errors.As(err, &bad) {
+		jsonError(w, bad.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Error searching properties: %v", err)
+	jsonError(w, "Database error", http.StatusInternalServerError)
+}
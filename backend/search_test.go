@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// createListing creates a single-unit property from p, filling in the
+// required address fields that p leaves empty.
+func (e *testEnv) createListing(token string, p Property) Property {
+	e.t.Helper()
+	if p.AddressLine1 == "" {
+		p.AddressLine1 = "1 Test St"
+	}
+	if p.City == "" {
+		p.City = "Portland"
+	}
+	if p.State == "" {
+		p.State = "OR"
+	}
+	if p.Zip == "" {
+		p.Zip = "97201"
+	}
+	if p.MonthlyRent == 0 {
+		p.MonthlyRent = 1500
+	}
+	p.PropertyType, p.Available = "apartment", true
+	rec := e.do(http.MethodPost, "/api/admin/properties", token, p)
+	e.expect(rec, http.StatusCreated)
+	return decode[Property](e.t, rec)
+}
+
+func listingNames(props []Property) []string {
+	names := make([]string, len(props))
+	for i, p := range props {
+		names[i] = p.Name
+	}
+	return names
+}
+
+func (e *testEnv) searchListings(query string) []string {
+	e.t.Helper()
+	rec := e.do(http.MethodGet, "/api/properties?"+query, "", nil)
+	e.expect(rec, http.StatusOK)
+	return listingNames(decode[[]Property](e.t, rec))
+}
+
+func strPtr(s string) *string { return &s }
+
+// sqliteTermIndex stands in for MySQL FULLTEXT in tests: an inverted index
+// in property_search_terms (testdata/schema_sqlite.sql), with terms matched
+// as prefixes and ranked by field weight.
+type sqliteTermIndex struct{}
+
+func (sqliteTermIndex) Index(ex sqlExecutor, propertyID int) error {
+	var name string
+	var description, amenitiesJSON *string
+	err := ex.QueryRow("SELECT name, description, amenities FROM properties WHERE id = ?", propertyID).
+		Scan(&name, &description, &amenitiesJSON)
+	if err != nil {
+		return err
+	}
+
+	weights := map[string]int{}
+	add := func(text string, weight int) {
+		for _, tok := range searchTokens(text) {
+			weights[tok] += weight
+		}
+	}
+	add(name, searchWeightName)
+	if amenitiesJSON != nil {
+		var amenities []string
+		json.Unmarshal([]byte(*amenitiesJSON), &amenities)
+		add(strings.Join(amenities, " "), searchWeightAmenities)
+	}
+	if description != nil {
+		add(*description, searchWeightDescription)
+	}
+
+	if _, err := ex.Exec("DELETE FROM property_search_terms WHERE property_id = ?", propertyID); err != nil {
+		return err
+	}
+	for term, weight := range weights {
+		if _, err := ex.Exec("INSERT INTO property_search_terms (property_id, term, weight) VALUES (?, ?, ?)", propertyID, term, weight); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (idx sqliteTermIndex) Backfill(ctx context.Context) (int, error) {
+	var ids []int
+	rows, err := db.QueryContext(ctx, `
+		SELECT id FROM properties p
+		WHERE NOT EXISTS (SELECT 1 FROM property_search_terms t WHERE t.property_id = p.id)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		if err := idx.Index(db, id); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), nil
+}
+
+func (sqliteTermIndex) Match(terms []string) (string, []interface{}) {
+	conds := make([]string, len(terms))
+	args := make([]interface{}, len(terms))
+	for i, term := range terms {
+		conds[i] = "EXISTS (SELECT 1 FROM property_search_terms t WHERE t.property_id = properties.id AND t.term LIKE ? ESCAPE '!')"
+		args[i] = escapeLike(term) + "%"
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func (sqliteTermIndex) Rank(terms []string) (string, []interface{}) {
+	match := make([]string, len(terms))
+	args := make([]interface{}, len(terms))
+	for i, term := range terms {
+		match[i] = "t.term LIKE ? ESCAPE '!'"
+		args[i] = escapeLike(term) + "%"
+	}
+	return `SELECT COALESCE(SUM(t.weight), 0) FROM property_search_terms t
+		WHERE t.property_id = properties.id AND (` + strings.Join(match, " OR ") + `)`, args
+}
+
+func TestSearchTokens(t *testing.T) {
+	got := fmt.Sprint(searchTokens("The Lofts at Hardwood-Floors, with 2 Balconies & A/C"))
+	if want := "[loft hardwood floor balcony]"; got != want {
+		t.Fatalf("searchTokens = %s, want %s", got, want)
+	}
+
+	// Long terms are cut on a character boundary.
+	long := searchTokens(strings.Repeat("é", 100))
+	if len(long) != 1 || !utf8.ValidString(long[0]) || utf8.RuneCountInString(long[0]) != maxSearchTermLength {
+		t.Fatalf("unexpected long term %q", long)
+	}
+}
+
+func TestMySQLFullTextQuery(t *testing.T) {
+	cond, args := mysqlFullText{}.Match([]string{"hardwood", "floor"})
+	if !strings.Contains(cond, "IN BOOLEAN MODE") || fmt.Sprint(args) != "[+hardwood* +floor*]" {
+		t.Fatalf("Match = %q %v", cond, args)
+	}
+	expr, args := mysqlFullText{}.Rank([]string{"hardwood", "floor"})
+	if strings.Count(expr, "?") != len(args) || args[0] != "hardwood* floor*" {
+		t.Fatalf("Rank = %q %v", expr, args)
+	}
+}
+
+func TestNominatimGeocoder(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("User-Agent") != "listings-test (ops@example.com)" {
+			t.Errorf("User-Agent = %q", r.Header.Get("User-Agent"))
+		}
+		q := r.URL.Query()
+		switch {
+		case q.Get("postalcode") == "97214" && q.Get("street") == "":
+			fmt.Fprint(w, `[{"lat":"45.5145","lon":"-122.6427"}]`)
+		case q.Get("street") == "1 Main St" && q.Get("city") == "Portland":
+			fmt.Fprint(w, `[{"lat":"45.5183","lon":"-122.6746"}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer srv.Close()
+
+	if _, err := newGeocoder(GeocoderConfig{Provider: geocoderNominatim, URL: srv.URL}); err == nil {
+		t.Fatal("expected nominatim without a user agent to be rejected")
+	}
+	g, err := newGeocoder(GeocoderConfig{Provider: geocoderNominatim, URL: srv.URL, UserAgent: "listings-test (ops@example.com)", Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	p, ok, err := g.Geocode(ctx, GeoAddress{Line1: "1 Main St", City: "Portland", State: "OR", Zip: "97204-1234"})
+	if err != nil || !ok || p.Lat != 45.5183 || p.Lng != -122.6746 {
+		t.Fatalf("street address: %v %v %v", p, ok, err)
+	}
+	for i := 0; i < 2; i++ {
+		if p, ok, err := g.Geocode(ctx, GeoAddress{Zip: "97214"}); err != nil || !ok || p.Lat != 45.5145 {
+			t.Fatalf("ZIP: %v %v %v", p, ok, err)
+		}
+	}
+	if _, ok, err := g.Geocode(ctx, GeoAddress{Zip: "00000"}); err != nil || ok {
+		t.Fatalf("unknown ZIP: ok=%v err=%v", ok, err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected the repeated ZIP lookup to be cached, got %d calls", calls)
+	}
+}
+
+func TestListingFullTextSearch(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	e.createListing(token, Property{Name: "Hardwood Lofts", Description: strPtr("Bright corner unit.")})
+	e.createListing(token, Property{Name: "Pearl Flat", Description: strPtr("Original hardwood floors throughout."), Amenities: []string{"Dishwasher"}})
+	e.createListing(token, Property{Name: "Garden Cottage", Description: strPtr("Carpeted bedrooms and a fenced yard."), Amenities: []string{"Washer/Dryer"}})
+
+	// A name match outranks a description match.
+	if got := fmt.Sprint(e.searchListings("q=hardwood")); got != "[Hardwood Lofts Pearl Flat]" {
+		t.Fatalf("q=hardwood: got %s", got)
+	}
+	// Every term must match; terms match as prefixes, plurals fold.
+	if got := fmt.Sprint(e.searchListings("q=hardwood+floor")); got != "[Pearl Flat]" {
+		t.Fatalf("q=hardwood floor: got %s", got)
+	}
+	if got := fmt.Sprint(e.searchListings("q=dish")); got != "[Pearl Flat]" {
+		t.Fatalf("q=dish: got %s", got)
+	}
+	if got := e.searchListings("q=washer+yards"); len(got) != 1 || got[0] != "Garden Cottage" {
+		t.Fatalf("q=washer yards: got %v", got)
+	}
+
+	// Edits reindex.
+	all := decode[[]Property](t, e.do(http.MethodGet, "/api/admin/properties", token, nil))
+	for _, p := range all {
+		if p.Name == "Garden Cottage" {
+			p.Description = strPtr("Hardwood floors refinished.")
+			e.expect(e.do(http.MethodPut, fmt.Sprintf("/api/admin/properties/%d", p.ID), token, p), http.StatusOK)
+		}
+	}
+	if got := e.searchListings("q=carpet"); len(got) != 0 {
+		t.Fatalf("stale index: q=carpet matched %v", got)
+	}
+	if got := e.searchListings("q=hardwood+floors"); len(got) != 2 {
+		t.Fatalf("q=hardwood floors after edit: got %v", got)
+	}
+}
+
+// Terms that differ only by accent are distinct: a description using both
+// forms saves, and each form finds the listing.
+func TestSearchAccentedTerms(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	e.createListing(token, Property{Name: "Corner Unit", Description: strPtr("Café downstairs, another cafe on Straße Ave and Strasse Ct.")})
+
+	for _, q := range []string{"café", "cafe", "straße", "strasse"} {
+		if got := e.searchListings("q=" + q); len(got) != 1 {
+			t.Errorf("q=%s: got %v", q, got)
+		}
+	}
+}
+
+func TestListingFilters(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	sqft := func(n int) *int { return &n }
+	e.createListing(token, Property{Name: "Studio", Bedrooms: 0, Bathrooms: 1, SquareFeet: sqft(450),
+		Amenities: []string{"Dishwasher"}, AvailableDate: strPtr(day(60)), PetPolicy: strPtr("none")})
+	e.createListing(token, Property{Name: "Family", Bedrooms: 3, Bathrooms: 2.5, SquareFeet: sqft(1600),
+		Amenities: []string{"Dishwasher", "In-Unit Laundry"}, AvailableDate: strPtr(day(5)), PetPolicy: strPtr("cats_and_dogs")})
+	e.createListing(token, Property{Name: "Loft", Bedrooms: 1, Bathrooms: 1, SquareFeet: sqft(800),
+		Amenities: []string{"Laundry Room", "Washer & Dryer"}, PetPolicy: strPtr("cats")})
+
+	cases := []struct {
+		query string
+		want  string
+	}{
+		{"minBaths=2", "[Family]"},
+		{"minSqft=500&maxSqft=1000", "[Loft]"},
+		{"beds=1&maxBeds=2", "[Loft]"},
+		{"amenities=in-unit+laundry", "[Family]"},
+		// Whole amenities only: "Laundry" is not "Laundry Room".
+		{"amenities=laundry", "[]"},
+		{"amenities=Dishwasher,In-Unit+Laundry", "[Family]"},
+		{"amenities=washer+%26+dryer", "[Loft]"},
+		{"availableBy=" + day(30), "[Family Loft]"},
+		{"pets=cats", "[Family Loft]"},
+		{"pets=dogs", "[Family]"},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprint(e.searchListings(c.query)); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.query, got, c.want)
+		}
+	}
+
+	// Stored the way MySQL keeps a JSON column, so the filter above is
+	// matching the same text production compares against.
+	var stored string
+	if err := db.QueryRow("SELECT amenities FROM properties WHERE name = 'Loft'").Scan(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(stored, `\u0026`) {
+		t.Fatalf("amenities stored HTML-escaped: %s", stored)
+	}
+
+	for _, bad := range []string{"minBaths=lots", "minSqft=-1", "availableBy=soon", "pets=ferrets", "radius=5"} {
+		e.expect(e.do(http.MethodGet, "/api/properties?"+bad, "", nil), http.StatusBadRequest)
+	}
+	e.expect(e.do(http.MethodPost, "/api/admin/properties", token, Property{
+		Name: "Bad", AddressLine1: "1 St", City: "Portland", State: "OR", Zip: "97201", PetPolicy: strPtr("birds"),
+	}), http.StatusBadRequest)
+}
+
+func TestListingRadiusSearch(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	downtown := e.createListing(token, Property{Name: "Downtown", Zip: "97201"})
+	e.createListing(token, Property{Name: "Lake Oswego", Zip: "97034"})
+	e.createListing(token, Property{Name: "Vancouver", City: "Vancouver", State: "WA", Zip: "98660"})
+	e.createListing(token, Property{Name: "Hillsboro", Zip: "97123"})
+	lat, lng := 45.40, -122.68
+	e.createListing(token, Property{Name: "Pinned", Zip: "00000", Latitude: &lat, Longitude: &lng})
+	e.createListing(token, Property{Name: "Nowhere", Zip: "00000"})
+
+	if downtown.Latitude == nil || *downtown.Latitude != zipCentroids["97201"].Lat {
+		t.Fatalf("expected downtown geocoded to the 97201 centroid, got %v", downtown.Latitude)
+	}
+
+	if got := fmt.Sprint(e.searchListings("near=97201&radius=5")); got != "[Downtown]" {
+		t.Fatalf("near=97201 radius 5: got %s", got)
+	}
+	rec := e.do(http.MethodGet, "/api/properties?near=97201", "", nil)
+	e.expect(rec, http.StatusOK)
+	near := decode[[]Property](t, rec)
+	if got := fmt.Sprint(listingNames(near)); got != "[Downtown Lake Oswego Pinned Vancouver]" {
+		t.Fatalf("near=97201 default radius: got %s", got)
+	}
+	if near[0].DistanceMiles == nil || *near[0].DistanceMiles != 0 || *near[1].DistanceMiles < 6 || *near[1].DistanceMiles > 8 {
+		t.Fatalf("unexpected distances: %v, %v", near[0].DistanceMiles, near[1].DistanceMiles)
+	}
+	if got := e.searchListings("lat=45.44&lng=-122.98&radius=3"); fmt.Sprint(got) != "[Hillsboro]" {
+		t.Fatalf("lat/lng search: got %v", got)
+	}
+
+	e.expect(e.do(http.MethodGet, "/api/properties?near=12345", "", nil), http.StatusBadRequest)
+	e.expect(e.do(http.MethodGet, "/api/properties?lat=45.5", "", nil), http.StatusBadRequest)
+	e.expect(e.do(http.MethodGet, "/api/properties?near=97201&radius=500", "", nil), http.StatusBadRequest)
+
+	// Moving the property re-geocodes even though the client echoed the old
+	// coordinates back.
+	downtown.Zip = "97034"
+	rec = e.do(http.MethodPut, fmt.Sprintf("/api/admin/properties/%d", downtown.ID), token, downtown)
+	e.expect(rec, http.StatusOK)
+	if moved := decode[Property](t, rec); *moved.Latitude != zipCentroids["97034"].Lat {
+		t.Fatalf("expected re-geocoding to 97034, got %v", *moved.Latitude)
+	}
+}
+
+func TestRefreshSearchIndexBackfills(t *testing.T) {
+	e := newTestEnv(t)
+	// Rows written before the search migration have no terms or coordinates.
+	if _, err := db.Exec(`INSERT INTO properties (name, address_line1, city, state, zip, monthly_rent, description)
+		VALUES ('Legacy Duplex', '9 Old Rd', 'Portland', 'OR', '97214', 1200, 'Clawfoot tub')`); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.searchListings("q=clawfoot"); len(got) != 0 {
+		t.Fatalf("expected no match before the backfill, got %v", got)
+	}
+
+	if err := refreshSearchIndex(context.Background()); err != nil {
+		t.Fatalf("refreshSearchIndex: %v", err)
+	}
+	if got := fmt.Sprint(e.searchListings("q=clawfoot&near=97214&radius=1")); got != "[Legacy Duplex]" {
+		t.Fatalf("expected backfilled listing, got %s", got)
+	}
+}
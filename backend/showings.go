@@ -0,0 +1,587 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// MODELS - SHOWINGS
+// ============================================================================
+
+// ShowingSlot is a viewing window an admin has published for a property.
+// Booking is only filled in on admin endpoints.
+type ShowingSlot struct {
+	ID         int             `json:"id"`
+	PropertyID int             `json:"propertyId"`
+	StartsAt   time.Time       `json:"startsAt"`
+	EndsAt     time.Time       `json:"endsAt"`
+	Booked     bool            `json:"booked"`
+	Booking    *ShowingBooking `json:"booking,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	// Joined fields
+	PropertyName *string `json:"propertyName,omitempty"`
+}
+
+type ShowingBooking struct {
+	ID          int        `json:"id"`
+	SlotID      int        `json:"slotId"`
+	Name        string     `json:"name"`
+	Email       string     `json:"email"`
+	Phone       string     `json:"phone"`
+	Status      string     `json:"status"`
+	CancelledAt *time.Time `json:"cancelledAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	// Returned once, to the person who booked, for the cancellation link.
+	CancelToken string `json:"cancelToken,omitempty"`
+	// Joined fields
+	PropertyID   int       `json:"propertyId"`
+	PropertyName string    `json:"propertyName"`
+	StartsAt     time.Time `json:"startsAt"`
+	EndsAt       time.Time `json:"endsAt"`
+}
+
+// showingLimiter throttles public bookings per client IP, sharing the
+// contact form's limits.
+var showingLimiter = newRateLimiter()
+
+// maxShowingLength guards against typos like a slot ending next week.
+const maxShowingLength = 4 * time.Hour
+
+// ============================================================================
+// HANDLERS - PUBLIC SHOWINGS
+// ============================================================================
+
+func propertyShowingsHandler(w http.ResponseWriter, r *http.Request, propertyID int) {
+	switch r.Method {
+	case http.MethodGet:
+		getOpenShowingSlots(w, propertyID)
+	case http.MethodPost:
+		bookShowing(w, r, propertyID)
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getOpenShowingSlots lists future slots that nobody has booked.
+func getOpenShowingSlots(w http.ResponseWriter, propertyID int) {
+	var exists int
+	db.QueryRow("SELECT COUNT(*) FROM properties WHERE id = ?", propertyID).Scan(&exists)
+	if exists == 0 {
+		jsonError(w, "Property not found", http.StatusNotFound)
+		return
+	}
+
+	rows, err := db.Query(`
+		SELECT s.id, s.property_id, s.starts_at, s.ends_at, s.created_at
+		FROM showing_slots s
+		LEFT JOIN showing_bookings b ON b.active_slot_id = s.id
+		WHERE s.property_id = ? AND s.starts_at > ? AND b.id IS NULL
+		ORDER BY s.starts_at
+	`, propertyID, time.Now().UTC())
+	if err != nil {
+		log.Printf("Error querying showing slots: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	slots := []ShowingSlot{}
+	for rows.Next() {
+		var s ShowingSlot
+		if err := rows.Scan(&s.ID, &s.PropertyID, &s.StartsAt, &s.EndsAt, &s.CreatedAt); err != nil {
+			log.Printf("Error scanning showing slot: %v", err)
+			continue
+		}
+		slots = append(slots, s)
+	}
+
+	jsonResponse(w, slots, http.StatusOK)
+}
+
+func bookShowing(w http.ResponseWriter, r *http.Request, propertyID int) {
+	if ok, retry := showingLimiter.allow(clientIP(r), cfg.Contact.RateLimit, cfg.Contact.RateWindow, time.Now()); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+		jsonError(w, "Too many bookings, please try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	var body struct {
+		SlotID int    `json:"slotId"`
+		Name   string `json:"name"`
+		Email  string `json:"email"`
+		Phone  string `json:"phone"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	body.Email = strings.TrimSpace(body.Email)
+	body.Phone = strings.TrimSpace(body.Phone)
+	if body.SlotID == 0 || body.Name == "" || body.Email == "" || body.Phone == "" {
+		jsonError(w, "Slot, name, email, and phone are required", http.StatusBadRequest)
+		return
+	}
+	if _, err := mail.ParseAddress(body.Email); err != nil {
+		jsonError(w, "Invalid email address", http.StatusBadRequest)
+		return
+	}
+
+	var b ShowingBooking
+	err := db.QueryRow(`
+		SELECT s.property_id, p.name, s.starts_at, s.ends_at
+		FROM showing_slots s
+		JOIN properties p ON s.property_id = p.id
+		WHERE s.id = ? AND s.property_id = ?
+	`, body.SlotID, propertyID).Scan(&b.PropertyID, &b.PropertyName, &b.StartsAt, &b.EndsAt)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Showing slot not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !b.StartsAt.After(time.Now()) {
+		jsonError(w, "This showing has already started", http.StatusConflict)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var taken int
+	tx.QueryRow("SELECT COUNT(*) FROM showing_bookings WHERE active_slot_id = ?", body.SlotID).Scan(&taken)
+	if taken > 0 {
+		jsonError(w, "This showing slot is already booked", http.StatusConflict)
+		return
+	}
+
+	b.SlotID = body.SlotID
+	b.Name, b.Email, b.Phone = body.Name, body.Email, body.Phone
+	b.Status = "booked"
+	b.CancelToken = generateToken()
+
+	// The unique index on active_slot_id catches a concurrent booking that
+	// slipped past the check above.
+	result, err := tx.Exec(`
+		INSERT INTO showing_bookings (slot_id, active_slot_id, name, email, phone, status, cancel_token)
+		VALUES (?, ?, ?, ?, ?, 'booked', ?)
+	`, b.SlotID, b.SlotID, b.Name, b.Email, b.Phone, b.CancelToken)
+	if err != nil {
+		if isUniqueViolation(err) {
+			jsonError(w, "This showing slot is already booked", http.StatusConflict)
+			return
+		}
+		log.Printf("Error booking showing: %v", err)
+		jsonError(w, "Failed to book showing", http.StatusInternalServerError)
+		return
+	}
+	id, _ := result.LastInsertId()
+	b.ID = int(id)
+
+	// Bookings land in the lead inbox so follow-up happens in one place.
+	_, err = tx.Exec(`
+		INSERT INTO leads (property_id, name, email, phone, message, status, source, ip_address)
+		VALUES (?, ?, ?, ?, ?, 'showing_scheduled', 'showing', ?)
+	`, b.PropertyID, b.Name, b.Email, b.Phone,
+		"Booked a showing for "+b.StartsAt.UTC().Format("Mon Jan 2 2006 15:04 UTC"), clientIP(r))
+	if err != nil {
+		log.Printf("Error creating lead for showing: %v", err)
+		jsonError(w, "Failed to book showing", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		jsonError(w, "Failed to book showing", http.StatusInternalServerError)
+		return
+	}
+
+	b.CreatedAt = time.Now()
+	jsonResponse(w, b, http.StatusCreated)
+}
+
+// showingByTokenHandler serves the tokenized link sent to whoever booked:
+// GET /api/showings/:token shows the booking and
+// POST /api/showings/:token/cancel releases the slot.
+func showingByTokenHandler(w http.ResponseWriter, r *http.Request) {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/showings/"), "/")
+	token, action, _ := strings.Cut(rest, "/")
+	if token == "" {
+		jsonError(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+	case action == "cancel" && r.Method == http.MethodPost:
+		cancelShowingByToken(w, token)
+		return
+	case action == "" || action == "cancel":
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	default:
+		jsonError(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	b, err := loadShowingBooking("b.cancel_token = ?", token)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Booking not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, b, http.StatusOK)
+}
+
+func cancelShowingByToken(w http.ResponseWriter, token string) {
+	b, err := loadShowingBooking("b.cancel_token = ?", token)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Booking not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if b.Status == "cancelled" {
+		jsonError(w, "This booking is already cancelled", http.StatusConflict)
+		return
+	}
+
+	now := time.Now().UTC()
+	_, err = db.Exec(`
+		UPDATE showing_bookings SET status = 'cancelled', active_slot_id = NULL, cancelled_at = ?
+		WHERE id = ?
+	`, now, b.ID)
+	if err != nil {
+		log.Printf("Error cancelling showing: %v", err)
+		jsonError(w, "Failed to cancel booking", http.StatusInternalServerError)
+		return
+	}
+
+	b.Status = "cancelled"
+	b.CancelledAt = &now
+	jsonResponse(w, b, http.StatusOK)
+}
+
+// ============================================================================
+// HANDLERS - ADMIN SHOWINGS
+// ============================================================================
+
+func adminShowingsHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		getAdminShowings(w, r)
+	case http.MethodPost:
+		createShowingSlot(w, r)
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func adminShowingByIDHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+
+	id, err := extractID(r.URL.Path, "/api/admin/showings/")
+	if err != nil {
+		jsonError(w, "Invalid showing slot ID", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != http.MethodDelete {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	deleteShowingSlot(w, id)
+}
+
+// getAdminShowings lists slots from now on (or from ?from=YYYY-MM-DD), with
+// the current booking if any. ?booked=true limits it to booked slots.
+func getAdminShowings(w http.ResponseWriter, r *http.Request) {
+	from := time.Now().UTC()
+	if raw := r.URL.Query().Get("from"); raw != "" {
+		t, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			jsonError(w, "Invalid date format (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+
+	slots, err := queryShowingSlots(from, r.URL.Query(), r.URL.Query().Get("booked") == "true")
+	if err != nil {
+		if errors.Is(err, errBadOwnerID) {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error querying showings: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, slots, http.StatusOK)
+}
+
+func createShowingSlot(w http.ResponseWriter, r *http.Request) {
+	var s ShowingSlot
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		jsonError(w, "Invalid JSON (times use RFC 3339, e.g. 2026-05-01T17:00:00-07:00)", http.StatusBadRequest)
+		return
+	}
+	if s.PropertyID == 0 || s.StartsAt.IsZero() || s.EndsAt.IsZero() {
+		jsonError(w, "Property, start time, and end time are required", http.StatusBadRequest)
+		return
+	}
+	s.StartsAt = s.StartsAt.UTC()
+	s.EndsAt = s.EndsAt.UTC()
+	if !s.EndsAt.After(s.StartsAt) || s.EndsAt.Sub(s.StartsAt) > maxShowingLength {
+		jsonError(w, fmt.Sprintf("End time must be after start time and within %s", maxShowingLength), http.StatusBadRequest)
+		return
+	}
+	if !s.StartsAt.After(time.Now()) {
+		jsonError(w, "Showings must start in the future", http.StatusBadRequest)
+		return
+	}
+
+	var propertyName string
+	err := db.QueryRow("SELECT name FROM properties WHERE id = ?", s.PropertyID).Scan(&propertyName)
+	if err == sql.ErrNoRows {
+		jsonError(w, "Property not found", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	var overlap int
+	db.QueryRow(`
+		SELECT COUNT(*) FROM showing_slots
+		WHERE property_id = ? AND starts_at < ? AND ends_at > ?
+	`, s.PropertyID, s.EndsAt, s.StartsAt).Scan(&overlap)
+	if overlap > 0 {
+		jsonError(w, "This property already has a showing slot during this time", http.StatusConflict)
+		return
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO showing_slots (property_id, starts_at, ends_at) VALUES (?, ?, ?)
+	`, s.PropertyID, s.StartsAt, s.EndsAt)
+	if err != nil {
+		log.Printf("Error creating showing slot: %v", err)
+		jsonError(w, "Failed to create showing slot", http.StatusInternalServerError)
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	s.ID = int(id)
+	s.PropertyName = &propertyName
+	s.CreatedAt = time.Now()
+
+	jsonResponse(w, s, http.StatusCreated)
+}
+
+func deleteShowingSlot(w http.ResponseWriter, id int) {
+	var booked int
+	db.QueryRow("SELECT COUNT(*) FROM showing_bookings WHERE active_slot_id = ?", id).Scan(&booked)
+	if booked > 0 {
+		jsonError(w, "This slot is booked; ask the visitor to cancel or contact them first", http.StatusConflict)
+		return
+	}
+
+	result, err := db.Exec("DELETE FROM showing_slots WHERE id = ?", id)
+	if err != nil {
+		log.Printf("Error deleting showing slot: %v", err)
+		jsonError(w, "Failed to delete showing slot", http.StatusInternalServerError)
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		jsonError(w, "Showing slot not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminShowingsCalendarHandler exports upcoming booked showings as an
+// iCalendar feed for import into a calendar app.
+func adminShowingsCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	slots, err := queryShowingSlots(time.Now().UTC(), r.URL.Query(), true)
+	if err != nil {
+		if errors.Is(err, errBadOwnerID) {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error querying showings: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="showings.ics"`)
+	w.Write([]byte(showingsICalendar(slots, time.Now())))
+}
+
+// showingsICalendar renders booked slots as an RFC 5545 VCALENDAR.
+func showingsICalendar(slots []ShowingSlot, now time.Time) string {
+	const stamp = "20060102T150405Z"
+	var sb strings.Builder
+	line := func(s string) { sb.WriteString(s + "\r\n") }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Roses & Clovers Properties//Showings//EN")
+	line("CALSCALE:GREGORIAN")
+	for _, s := range slots {
+		if s.Booking == nil {
+			continue
+		}
+		property := ""
+		if s.PropertyName != nil {
+			property = *s.PropertyName
+		}
+		line("BEGIN:VEVENT")
+		line(fmt.Sprintf("UID:showing-%d@rosesandclovers", s.Booking.ID))
+		line("DTSTAMP:" + now.UTC().Format(stamp))
+		line("DTSTART:" + s.StartsAt.UTC().Format(stamp))
+		line("DTEND:" + s.EndsAt.UTC().Format(stamp))
+		line("SUMMARY:" + icalEscape("Showing: "+property+" with "+s.Booking.Name))
+		line("DESCRIPTION:" + icalEscape(s.Booking.Name+"\n"+s.Booking.Email+"\n"+s.Booking.Phone))
+		line("LOCATION:" + icalEscape(property))
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return sb.String()
+}
+
+func icalEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// ============================================================================
+// SCAN HELPERS - SHOWINGS
+// ============================================================================
+
+func queryShowingSlots(from time.Time, filters url.Values, bookedOnly bool) ([]ShowingSlot, error) {
+	query := `
+		SELECT s.id, s.property_id, s.starts_at, s.ends_at, s.created_at, p.name,
+			   b.id, b.name, b.email, b.phone, b.status, b.created_at
+		FROM showing_slots s
+		JOIN properties p ON s.property_id = p.id
+		LEFT JOIN showing_bookings b ON b.active_slot_id = s.id
+		WHERE s.starts_at >= ?
+	`
+	args := []interface{}{from}
+
+	if propertyID := filters.Get("propertyId"); propertyID != "" {
+		if id, err := strconv.Atoi(propertyID); err == nil {
+			query += " AND s.property_id = ?"
+			args = append(args, id)
+		}
+	}
+	clause, ownerArgs, err := ownerFilter(filters.Get("ownerId"), "s.property_id")
+	if err != nil {
+		return nil, err
+	}
+	query += clause
+	args = append(args, ownerArgs...)
+	if bookedOnly {
+		query += " AND b.id IS NOT NULL"
+	}
+
+	query += " ORDER BY s.starts_at"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slots := []ShowingSlot{}
+	for rows.Next() {
+		var s ShowingSlot
+		var propertyName string
+		var bookingID sql.NullInt64
+		var name, email, phone, status sql.NullString
+		var bookedAt sql.NullTime
+		err := rows.Scan(&s.ID, &s.PropertyID, &s.StartsAt, &s.EndsAt, &s.CreatedAt, &propertyName,
+			&bookingID, &name, &email, &phone, &status, &bookedAt)
+		if err != nil {
+			return nil, err
+		}
+		s.PropertyName = &propertyName
+		if bookingID.Valid {
+			s.Booked = true
+			s.Booking = &ShowingBooking{
+				ID:           int(bookingID.Int64),
+				SlotID:       s.ID,
+				Name:         name.String,
+				Email:        email.String,
+				Phone:        phone.String,
+				Status:       status.String,
+				CreatedAt:    bookedAt.Time,
+				PropertyID:   s.PropertyID,
+				PropertyName: propertyName,
+				StartsAt:     s.StartsAt,
+				EndsAt:       s.EndsAt,
+			}
+		}
+		slots = append(slots, s)
+	}
+	return slots, rows.Err()
+}
+
+// loadShowingBooking reads one booking matching where, without its token.
+func loadShowingBooking(where string, args ...interface{}) (ShowingBooking, error) {
+	var b ShowingBooking
+	var cancelledAt sql.NullTime
+	err := db.QueryRow(`
+		SELECT b.id, b.slot_id, b.name, b.email, b.phone, b.status, b.cancelled_at, b.created_at,
+			   s.property_id, p.name, s.starts_at, s.ends_at
+		FROM showing_bookings b
+		JOIN showing_slots s ON b.slot_id = s.id
+		JOIN properties p ON s.property_id = p.id
+		WHERE `+where, args...).Scan(&b.ID, &b.SlotID, &b.Name, &b.Email, &b.Phone, &b.Status, &cancelledAt, &b.CreatedAt,
+		&b.PropertyID, &b.PropertyName, &b.StartsAt, &b.EndsAt)
+	if cancelledAt.Valid {
+		b.CancelledAt = &cancelledAt.Time
+	}
+	return b, err
+}
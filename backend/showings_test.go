@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func (e *testEnv) createShowingSlot(token string, propertyID int, start time.Time) ShowingSlot {
+	e.t.Helper()
+	rec := e.do(http.MethodPost, "/api/admin/showings", token, map[string]interface{}{
+		"propertyId": propertyID,
+		"startsAt":   start.Format(time.RFC3339),
+		"endsAt":     start.Add(30 * time.Minute).Format(time.RFC3339),
+	})
+	e.expect(rec, http.StatusCreated)
+	return decode[ShowingSlot](e.t, rec)
+}
+
+func TestCreateShowingSlotValidation(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Slot Place", 1500)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+
+	e.expect(e.do(http.MethodPost, "/api/admin/showings", token, map[string]interface{}{
+		"propertyId": pid, "startsAt": start.Format(time.RFC3339), "endsAt": start.Add(-time.Minute).Format(time.RFC3339),
+	}), http.StatusBadRequest)
+	e.expect(e.do(http.MethodPost, "/api/admin/showings", token, map[string]interface{}{
+		"propertyId": pid, "startsAt": time.Now().Add(-time.Hour).Format(time.RFC3339), "endsAt": time.Now().Format(time.RFC3339),
+	}), http.StatusBadRequest)
+	e.expect(e.do(http.MethodPost, "/api/admin/showings", token, map[string]interface{}{
+		"propertyId": 999, "startsAt": start.Format(time.RFC3339), "endsAt": start.Add(time.Hour).Format(time.RFC3339),
+	}), http.StatusBadRequest)
+
+	e.createShowingSlot(token, pid, start)
+	e.expect(e.do(http.MethodPost, "/api/admin/showings", token, map[string]interface{}{
+		"propertyId": pid, "startsAt": start.Add(15 * time.Minute).Format(time.RFC3339), "endsAt": start.Add(time.Hour).Format(time.RFC3339),
+	}), http.StatusConflict)
+}
+
+func TestBookAndCancelShowing(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Viewing Place", 1500)
+	start := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	slot := e.createShowingSlot(token, pid, start)
+	e.createShowingSlot(token, pid, start.Add(time.Hour))
+	path := fmt.Sprintf("/api/properties/%d/showings", pid)
+
+	open := decode[[]ShowingSlot](t, e.do(http.MethodGet, path, "", nil))
+	if len(open) != 2 {
+		t.Fatalf("expected 2 open slots, got %d", len(open))
+	}
+
+	booking := map[string]interface{}{"slotId": slot.ID, "name": "Morgan", "email": "morgan@example.com", "phone": "555-0123"}
+	e.expect(e.do(http.MethodPost, path, "", map[string]interface{}{"slotId": slot.ID, "name": "No Phone", "email": "np@example.com"}), http.StatusBadRequest)
+	e.expect(e.do(http.MethodPost, fmt.Sprintf("/api/properties/%d/showings", pid+1), "", booking), http.StatusNotFound)
+
+	rec := e.do(http.MethodPost, path, "", booking)
+	e.expect(rec, http.StatusCreated)
+	booked := decode[ShowingBooking](t, rec)
+	if booked.CancelToken == "" || booked.PropertyName != "Viewing Place" || !booked.StartsAt.Equal(start) {
+		t.Fatalf("unexpected booking: %+v", booked)
+	}
+
+	// No double-booking, and the slot drops off the public list.
+	e.expect(e.do(http.MethodPost, path, "", booking), http.StatusConflict)
+	// A booking that races past the check is stopped by the unique index.
+	_, err := db.Exec(`INSERT INTO showing_bookings (slot_id, active_slot_id, name, email, phone, cancel_token)
+		VALUES (?, ?, 'Racer', 'racer@example.com', '555-0199', 'race')`, slot.ID, slot.ID)
+	if !isUniqueViolation(err) {
+		t.Fatalf("expected a unique violation, got %v", err)
+	}
+	if open := decode[[]ShowingSlot](t, e.do(http.MethodGet, path, "", nil)); len(open) != 1 {
+		t.Fatalf("expected 1 open slot after booking, got %d", len(open))
+	}
+
+	// Booked slots cannot be deleted out from under the visitor.
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/showings/%d", slot.ID), token, nil), http.StatusConflict)
+
+	// Bookings show up in the lead inbox.
+	leads := decode[[]Lead](t, e.do(http.MethodGet, "/api/admin/leads?status=showing_scheduled", token, nil))
+	if len(leads) != 1 || leads[0].Source != "showing" {
+		t.Fatalf("expected a showing lead, got %+v", leads)
+	}
+
+	link := "/api/showings/" + booked.CancelToken
+	e.expect(e.do(http.MethodGet, "/api/showings/not-a-token", "", nil), http.StatusNotFound)
+	if got := decode[ShowingBooking](t, e.do(http.MethodGet, link, "", nil)); got.Status != "booked" || got.CancelToken != "" {
+		t.Fatalf("unexpected booking lookup: %+v", got)
+	}
+	e.expect(e.do(http.MethodGet, link+"/cancel", "", nil), http.StatusMethodNotAllowed)
+
+	rec = e.do(http.MethodPost, link+"/cancel", "", nil)
+	e.expect(rec, http.StatusOK)
+	if decode[ShowingBooking](t, rec).Status != "cancelled" {
+		t.Fatal("expected cancelled booking")
+	}
+	e.expect(e.do(http.MethodPost, link+"/cancel", "", nil), http.StatusConflict)
+
+	// The slot is open again and can be rebooked.
+	e.expect(e.do(http.MethodPost, path, "", booking), http.StatusCreated)
+}
+
+func TestAdminShowingsListAndCalendar(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Calendar, Place", 1500)
+	start := time.Date(2099, 5, 1, 17, 0, 0, 0, time.UTC)
+	slot := e.createShowingSlot(token, pid, start)
+	e.createShowingSlot(token, pid, start.Add(time.Hour))
+
+	e.expect(e.do(http.MethodPost, fmt.Sprintf("/api/properties/%d/showings", pid), "", map[string]interface{}{
+		"slotId": slot.ID, "name": "Quinn", "email": "quinn@example.com", "phone": "555-0150",
+	}), http.StatusCreated)
+
+	all := decode[[]ShowingSlot](t, e.do(http.MethodGet, "/api/admin/showings", token, nil))
+	if len(all) != 2 || all[0].Booking == nil || all[0].Booking.Name != "Quinn" || all[1].Booked {
+		t.Fatalf("unexpected admin showings: %+v", all)
+	}
+	if booked := decode[[]ShowingSlot](t, e.do(http.MethodGet, "/api/admin/showings?booked=true", token, nil)); len(booked) != 1 {
+		t.Fatalf("expected 1 booked showing, got %d", len(booked))
+	}
+
+	rec := e.do(http.MethodGet, "/api/admin/showings.ics", token, nil)
+	e.expect(rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	ics := rec.Body.String()
+	for _, want := range []string{"BEGIN:VCALENDAR\r\n", "DTSTART:20990501T170000Z", "DTEND:20990501T173000Z", `SUMMARY:Showing: Calendar\, Place with Quinn`, "END:VCALENDAR\r\n"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("calendar missing %q:\n%s", want, ics)
+		}
+	}
+	if strings.Count(ics, "BEGIN:VEVENT") != 1 {
+		t.Fatalf("expected only booked slots in the calendar:\n%s", ics)
+	}
+
+	// Unbooked slots can be removed.
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/showings/%d", all[1].ID), token, nil), http.StatusNoContent)
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/showings/%d", all[1].ID), token, nil), http.StatusNotFound)
+}
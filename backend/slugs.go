@@ -0,0 +1,309 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// SLUGS
+// ============================================================================
+
+// maxSlugLength leaves room for a "-N" suffix in the 200-character column.
+const maxSlugLength = 80
+
+// slugify lowercases s and joins its ASCII letters and digits with hyphens:
+// "The Pearl Lofts, Portland" becomes "the-pearl-lofts-portland".
+func slugify(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		default:
+			hyphen = true
+		}
+		if b.Len() >= maxSlugLength {
+			break
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+	if slug == "" {
+		return "property"
+	}
+	// An all-digit slug would be read as a property ID.
+	if _, err := strconv.Atoi(slug); err == nil {
+		return "property-" + slug
+	}
+	return slug
+}
+
+// slugHasBase reports whether slug is base or base with a "-N" suffix added
+// to keep it unique.
+func slugHasBase(slug, base string) bool {
+	if slug == base {
+		return true
+	}
+	n, ok := strings.CutPrefix(slug, base+"-")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(n)
+	return err == nil
+}
+
+// syncPropertySlug gives a property a slug from its name and city. A rename
+// that changes the slug keeps the old one as a redirect, so shared links and
+// search engine entries still resolve.
+func syncPropertySlug(ex sqlExecutor, propertyID int) error {
+	var name, city string
+	var current sql.NullString
+	if err := ex.QueryRow("SELECT name, city, slug FROM properties WHERE id = ?", propertyID).Scan(&name, &city, &current); err != nil {
+		return err
+	}
+	base := slugify(name + " " + city)
+	if current.Valid && slugHasBase(current.String, base) {
+		return nil
+	}
+
+	// Slugs still redirecting to another property are not reused, or old
+	// links to it would land on this one.
+	slug := base
+	for n := 2; ; n++ {
+		var taken int
+		err := ex.QueryRow(`
+			SELECT (SELECT COUNT(*) FROM properties WHERE slug = ? AND id <> ?) +
+			       (SELECT COUNT(*) FROM property_slug_redirects WHERE slug = ? AND property_id <> ?)
+		`, slug, propertyID, slug, propertyID).Scan(&taken)
+		if err != nil {
+			return err
+		}
+		if taken == 0 {
+			break
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	// The new slug may be one this property used before.
+	if _, err := ex.Exec("DELETE FROM property_slug_redirects WHERE slug = ?", slug); err != nil {
+		return err
+	}
+	if _, err := ex.Exec("UPDATE properties SET slug = ? WHERE id = ?", slug, propertyID); err != nil {
+		return err
+	}
+	if current.Valid {
+		_, err := ex.Exec("INSERT INTO property_slug_redirects (slug, property_id) VALUES (?, ?)", current.String, propertyID)
+		return err
+	}
+	return nil
+}
+
+// resolvePropertySlug finds the property for a current or retired slug and
+// returns its current slug.
+func resolvePropertySlug(slug string) (id int, current string, err error) {
+	err = db.QueryRow("SELECT id, slug FROM properties WHERE slug = ?", slug).Scan(&id, &current)
+	if err != sql.ErrNoRows {
+		return id, current, err
+	}
+	err = db.QueryRow(`
+		SELECT p.id, p.slug FROM property_slug_redirects r
+		JOIN properties p ON p.id = r.property_id
+		WHERE r.slug = ?
+	`, slug).Scan(&id, &current)
+	return id, current, err
+}
+
+// redirectPropertySlug sends a request for a retired slug to the current
+// one. Anything but GET keeps its method and body with a 308.
+func redirectPropertySlug(w http.ResponseWriter, r *http.Request, slug, action string) {
+	target := "/api/properties/" + slug
+	if action != "" {
+		target += "/" + action
+	}
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	status := http.StatusMovedPermanently
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		status = http.StatusPermanentRedirect
+	}
+	http.Redirect(w, r, target, status)
+}
+
+// backfillPropertySlugs slugs properties created before slugs existed.
+func backfillPropertySlugs() (int, error) {
+	rows, err := db.Query("SELECT id FROM properties WHERE slug IS NULL")
+	if err != nil {
+		return 0, err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		if err := syncPropertySlug(db, id); err != nil {
+			return 0, fmt.Errorf("slug property %d: %w", id, err)
+		}
+	}
+	return len(ids), nil
+}
+
+// ============================================================================
+// HANDLERS - SITEMAP & STRUCTURED DATA
+// ============================================================================
+
+// SitemapEntry is one property page for the frontend's sitemap.
+type SitemapEntry struct {
+	ID        int       `json:"id"`
+	Slug      string    `json:"slug"`
+	URL       string    `json:"url"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// sitemapHandler serves GET /api/sitemap: every listed property's page.
+func sitemapHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rows, err := db.Query("SELECT id, slug, updated_at FROM properties WHERE slug IS NOT NULL AND listing_status = ? ORDER BY id", listingListed)
+	if err != nil {
+		log.Printf("Error querying sitemap: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	entries := []SitemapEntry{}
+	for rows.Next() {
+		var s SitemapEntry
+		if err := rows.Scan(&s.ID, &s.Slug, &s.UpdatedAt); err != nil {
+			log.Printf("Error scanning sitemap entry: %v", err)
+			continue
+		}
+		s.URL = propertyPageURL(s.Slug)
+		entries = append(entries, s)
+	}
+
+	jsonResponse(w, entries, http.StatusOK)
+}
+
+func propertyPageURL(slugOrID string) string {
+	return strings.TrimSuffix(cfg.Mail.SiteURL, "/") + "/properties/" + slugOrID
+}
+
+// propertyURL is the public detail page for p, by slug once it has one.
+func propertyURL(p Property) string {
+	if p.Slug != "" {
+		return propertyPageURL(p.Slug)
+	}
+	return propertyPageURL(strconv.Itoa(p.ID))
+}
+
+// propertyJSONLD describes a property as a schema.org Apartment (or House)
+// with its rent as an Offer, for embedding in the detail page.
+func propertyJSONLD(p Property) map[string]interface{} {
+	pageURL := propertyURL(p)
+
+	kind := "Apartment"
+	switch p.PropertyType {
+	case "house", "duplex", "townhouse":
+		kind = "House"
+	}
+
+	address := map[string]interface{}{
+		"@type":           "PostalAddress",
+		"streetAddress":   p.AddressLine1,
+		"addressLocality": p.City,
+		"addressRegion":   p.State,
+		"postalCode":      p.Zip,
+		"addressCountry":  "US",
+	}
+	if p.AddressLine2 != nil {
+		address["streetAddress"] = p.AddressLine1 + ", " + *p.AddressLine2
+	}
+
+	availability := "https://schema.org/OutOfStock"
+	if p.Available {
+		availability = "https://schema.org/InStock"
+	}
+	offer := map[string]interface{}{
+		"@type":         "Offer",
+		"url":           pageURL,
+		"price":         p.MonthlyRent,
+		"priceCurrency": "USD",
+		"availability":  availability,
+		"priceSpecification": map[string]interface{}{
+			"@type":         "UnitPriceSpecification",
+			"price":         p.MonthlyRent,
+			"priceCurrency": "USD",
+			"unitCode":      "MON",
+		},
+	}
+	if p.AvailableDate != nil {
+		offer["availabilityStarts"] = dateOnly(*p.AvailableDate)
+	}
+
+	ld := map[string]interface{}{
+		"@context":               "https://schema.org",
+		"@type":                  kind,
+		"@id":                    pageURL,
+		"name":                   p.Name,
+		"url":                    pageURL,
+		"address":                address,
+		"numberOfBedrooms":       p.Bedrooms,
+		"numberOfRooms":          p.Bedrooms,
+		"numberOfBathroomsTotal": p.Bathrooms,
+		"offers":                 offer,
+	}
+	if p.Description != nil {
+		ld["description"] = *p.Description
+	}
+	if p.Latitude != nil && p.Longitude != nil {
+		ld["geo"] = map[string]interface{}{"@type": "GeoCoordinates", "latitude": *p.Latitude, "longitude": *p.Longitude}
+	}
+	if p.SquareFeet != nil {
+		ld["floorSize"] = map[string]interface{}{"@type": "QuantitativeValue", "value": *p.SquareFeet, "unitCode": "FTK"}
+	}
+	if p.PetPolicy != nil {
+		ld["petsAllowed"] = *p.PetPolicy != "none"
+	}
+	if len(p.Amenities) > 0 {
+		features := make([]map[string]interface{}, len(p.Amenities))
+		for i, a := range p.Amenities {
+			features[i] = map[string]interface{}{"@type": "LocationFeatureSpecification", "name": a, "value": true}
+		}
+		ld["amenityFeature"] = features
+	}
+	var images []string
+	for _, img := range p.Images {
+		images = append(images, absoluteURL(img.URLs.Detail))
+	}
+	if len(images) == 0 && p.ImageURL != nil {
+		images = append(images, absoluteURL(*p.ImageURL))
+	}
+	if len(images) > 0 {
+		ld["image"] = images
+	}
+	return ld
+}
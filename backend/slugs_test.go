@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	for in, want := range map[string]string{
+		"The Pearl Lofts Portland":  "the-pearl-lofts-portland",
+		"  Café #4 -- N.W. 23rd!  ": "caf-4-n-w-23rd",
+		"1200 Beaverton":            "1200-beaverton",
+		"1200":                      "property-1200",
+		"???":                       "property",
+	} {
+		if got := slugify(in); got != want {
+			t.Errorf("slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPropertySlugs(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	first := e.createListing(token, Property{Name: "Alder Court"})
+	second := e.createListing(token, Property{Name: "Alder Court"})
+	if first.Slug != "alder-court-portland" || second.Slug != "alder-court-portland-2" {
+		t.Fatalf("expected unique slugs, got %q and %q", first.Slug, second.Slug)
+	}
+
+	rec := e.do(http.MethodGet, "/api/properties/alder-court-portland-2", "", nil)
+	e.expect(rec, http.StatusOK)
+	if p := decode[Property](t, rec); p.ID != second.ID {
+		t.Fatalf("slug resolved to property %d, want %d", p.ID, second.ID)
+	}
+
+	// Edits that keep the name and city keep the slug.
+	path := fmt.Sprintf("/api/admin/properties/%d", second.ID)
+	second.MonthlyRent = 1600
+	if p := decode[Property](t, e.do(http.MethodPut, path, token, second)); p.Slug != "alder-court-portland-2" {
+		t.Fatalf("expected the slug to survive an edit, got %q", p.Slug)
+	}
+
+	second.Name = "Birch Court"
+	renamed := decode[Property](t, e.do(http.MethodPut, path, token, second))
+	if renamed.Slug != "birch-court-portland" {
+		t.Fatalf("expected a new slug after the rename, got %q", renamed.Slug)
+	}
+	rec = e.do(http.MethodGet, "/api/properties/alder-court-portland-2/jsonld?x=1", "", nil)
+	e.expect(rec, http.StatusMovedPermanently)
+	if loc := rec.Header().Get("Location"); loc != "/api/properties/birch-court-portland/jsonld?x=1" {
+		t.Fatalf("unexpected redirect to %q", loc)
+	}
+
+	// A retired slug is not handed to another property.
+	third := e.createListing(token, Property{Name: "Alder Court"})
+	if third.Slug != "alder-court-portland-3" {
+		t.Fatalf("expected the retired slug to stay reserved, got %q", third.Slug)
+	}
+
+	// Renaming back reclaims it.
+	second.Name = "Alder Court"
+	if p := decode[Property](t, e.do(http.MethodPut, path, token, second)); p.Slug != "alder-court-portland-2" {
+		t.Fatalf("expected the old slug back, got %q", p.Slug)
+	}
+	e.expect(e.do(http.MethodGet, "/api/properties/birch-court-portland", "", nil), http.StatusMovedPermanently)
+	e.expect(e.do(http.MethodGet, "/api/properties/alder-court-portland-2", "", nil), http.StatusOK)
+	e.expect(e.do(http.MethodGet, "/api/properties/no-such-place", "", nil), http.StatusNotFound)
+
+	// An old slug of a property that is not listed is a plain 404; a redirect
+	// would reveal the current name.
+	second.ListingStatus = listingUnlisted
+	e.expect(e.do(http.MethodPut, path, token, second), http.StatusOK)
+	rec = e.do(http.MethodGet, "/api/properties/birch-court-portland", "", nil)
+	e.expect(rec, http.StatusNotFound)
+	if loc := rec.Header().Get("Location"); loc != "" {
+		t.Fatalf("unlisted property redirected to %q", loc)
+	}
+	second.ListingStatus = listingListed
+	e.expect(e.do(http.MethodPut, path, token, second), http.StatusOK)
+
+	// Rows from before slugs are filled in by the search-index job.
+	db.Exec("UPDATE properties SET slug = NULL WHERE id = ?", first.ID)
+	if err := refreshSearchIndex(context.Background()); err != nil {
+		t.Fatalf("refreshSearchIndex: %v", err)
+	}
+	if p := decode[Property](t, e.do(http.MethodGet, fmt.Sprintf("/api/properties/%d", first.ID), "", nil)); p.Slug != "alder-court-portland" {
+		t.Fatalf("expected the slug to be backfilled, got %q", p.Slug)
+	}
+
+	sitemap := decode[[]SitemapEntry](t, e.do(http.MethodGet, "/api/sitemap", "", nil))
+	if len(sitemap) != 3 || sitemap[0].URL != "http://localhost:3000/properties/alder-court-portland" {
+		t.Fatalf("unexpected sitemap: %+v", sitemap)
+	}
+}
+
+func TestPropertyJSONLD(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	sqft := 850
+	p := e.createListing(token, Property{
+		Name: "Linden Flats", Bedrooms: 2, Bathrooms: 1, MonthlyRent: 1850, SquareFeet: &sqft,
+		Amenities: []string{"Balcony"}, AvailableDate: strPtr(day(10)), PetPolicy: strPtr("none"),
+	})
+
+	rec := e.do(http.MethodGet, "/api/properties/"+p.Slug+"/jsonld", "", nil)
+	e.expect(rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/ld+json" {
+		t.Errorf("unexpected Content-Type %q", ct)
+	}
+	var ld struct {
+		Context  string `json:"@context"`
+		Type     string `json:"@type"`
+		URL      string `json:"url"`
+		Bedrooms int    `json:"numberOfBedrooms"`
+		Pets     bool   `json:"petsAllowed"`
+		Address  struct {
+			Type       string `json:"@type"`
+			PostalCode string `json:"postalCode"`
+		} `json:"address"`
+		Geo       *struct{ Latitude float64 } `json:"geo"`
+		FloorSize struct{ Value int }         `json:"floorSize"`
+		Offers    struct {
+			Type               string  `json:"@type"`
+			Price              float64 `json:"price"`
+			Currency           string  `json:"priceCurrency"`
+			Availability       string  `json:"availability"`
+			AvailabilityStarts string  `json:"availabilityStarts"`
+		} `json:"offers"`
+		Amenities []struct{ Name string } `json:"amenityFeature"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &ld); err != nil {
+		t.Fatalf("invalid JSON-LD: %v", err)
+	}
+	if ld.Context != "https://schema.org" || ld.Type != "Apartment" || ld.URL != "http://localhost:3000/properties/linden-flats-portland" ||
+		ld.Bedrooms != 2 || ld.Pets || ld.Address.Type != "PostalAddress" || ld.Address.PostalCode != "97201" ||
+		ld.Geo == nil || ld.FloorSize.Value != 850 || len(ld.Amenities) != 1 {
+		t.Fatalf("unexpected JSON-LD: %s", rec.Body.String())
+	}
+	if ld.Offers.Type != "Offer" || ld.Offers.Price != 1850 || ld.Offers.Currency != "USD" ||
+		ld.Offers.Availability != "https://schema.org/InStock" || ld.Offers.AvailabilityStarts != day(10) {
+		t.Fatalf("unexpected offer: %+v", ld.Offers)
+	}
+}
@@ -1,26 +0,0 @@
-CREATE TABLE IF NOT EXISTS athletes (
-  id INT AUTO_INCREMENT PRIMARY KEY,
-  name VARCHAR(100) NOT NULL,
-  grade INT NOT NULL,
-  personal_record_seconds INT NOT NULL,
-  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-);
-
-CREATE TABLE IF NOT EXISTS meets (
-  id INT AUTO_INCREMENT PRIMARY KEY,
-  name VARCHAR(150) NOT NULL,
-  meet_date DATE NOT NULL,
-  location VARCHAR(150) NOT NULL,
-  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-);
-
-CREATE TABLE IF NOT EXISTS results (
-  id INT AUTO_INCREMENT PRIMARY KEY,
-  athlete_id INT NOT NULL,
-  meet_id INT NOT NULL,
-  time_seconds INT NOT NULL,
-  place_overall INT NOT NULL,
-  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  CONSTRAINT fk_results_athlete FOREIGN KEY (athlete_id) REFERENCES athletes(id) ON DELETE CASCADE,
-  CONSTRAINT fk_results_meet FOREIGN KEY (meet_id) REFERENCES meets(id) ON DELETE CASCADE
-);
@@ -1,5 +1,5 @@
 -- Roses & Clovers Properties Seed Data
--- Run this after 001_create_tables.sql for development/testing
+-- Run this after `go run . migrate up` for development/testing
 
 -- Insert sample properties
 INSERT INTO properties (name, address_line1, address_line2, city, state, zip, property_type, bedrooms, bathrooms, square_feet, monthly_rent, deposit_amount, available, available_date, description, amenities) VALUES
@@ -19,6 +19,10 @@ INSERT INTO properties (name, address_line1, address_line2, city, state, zip, pr
 
 ('Ivy Terrace Apartment', '222 Ivy Street', 'Apt 8', 'Beaverton', 'OR', '97005', 'apartment', 1, 1.0, 650, 1150.00, 1150.00, TRUE, '2025-02-01', 'Clean and bright 1-bedroom near public transit. Perfect for commuters.', '["Near transit", "Laundry in building", "Bike storage", "Package lockers"]');
 
+-- Each sample property is a single-unit building
+INSERT INTO units (property_id, unit_number, bedrooms, bathrooms, square_feet, monthly_rent, deposit_amount, available, available_date)
+SELECT id, '1', bedrooms, bathrooms, square_feet, monthly_rent, deposit_amount, available, available_date FROM properties;
+
 -- Insert sample tenants
 INSERT INTO tenants (first_name, last_name, email, phone, date_of_birth, emergency_contact_name, emergency_contact_phone, notes) VALUES
 ('Sarah', 'Johnson', 'sarah.johnson@email.com', '503-555-0101', '1990-05-15', 'Mike Johnson', '503-555-0102', 'Excellent tenant, always pays on time.'),
@@ -45,6 +49,8 @@ INSERT INTO leases (property_id, tenant_id, start_date, end_date, monthly_rent,
 -- Upcoming lease for Clover Heights
 (1, 4, '2025-03-01', '2026-02-28', 1450.00, 1450.00, 'upcoming', 1, 'New tenant, moving from out of state.');
 
+UPDATE leases l JOIN units u ON u.property_id = l.property_id SET l.unit_id = u.id;
+
 -- Add some audit log entries
 INSERT INTO audit_log (action, entity_type, entity_id, details) VALUES
 ('create', 'property', 1, '{"name": "Clover Heights Apartment", "user": "admin"}'),
@@ -0,0 +1,305 @@
+-- SQLite mirror of the MySQL schema, used only by the Go test suite.
+-- Keep column names and order in sync with the migrations; types are
+-- loosened (ENUM -> TEXT, JSON -> TEXT) since SQLite does not enforce them.
+
+CREATE TABLE properties (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    address_line1 TEXT NOT NULL,
+    address_line2 TEXT DEFAULT NULL,
+    city TEXT NOT NULL,
+    state TEXT NOT NULL,
+    zip TEXT NOT NULL,
+    property_type TEXT NOT NULL DEFAULT 'apartment',
+    bedrooms INTEGER NOT NULL DEFAULT 1,
+    bathrooms REAL NOT NULL DEFAULT 1.0,
+    square_feet INTEGER DEFAULT NULL,
+    monthly_rent REAL NOT NULL,
+    deposit_amount REAL DEFAULT NULL,
+    available BOOLEAN NOT NULL DEFAULT TRUE,
+    available_date DATE DEFAULT NULL,
+    description TEXT,
+    amenities TEXT DEFAULT NULL,
+    image_url TEXT DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    latitude REAL DEFAULT NULL,
+    longitude REAL DEFAULT NULL,
+    pet_policy TEXT DEFAULT NULL,
+    syndicate BOOLEAN NOT NULL DEFAULT TRUE,
+    slug TEXT UNIQUE DEFAULT NULL,
+    listing_status TEXT NOT NULL DEFAULT 'listed',
+    owner_id INTEGER DEFAULT NULL REFERENCES owners(id)
+);
+
+CREATE TABLE tenants (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    email TEXT NOT NULL UNIQUE,
+    phone TEXT DEFAULT NULL,
+    date_of_birth DATE DEFAULT NULL,
+    emergency_contact_name TEXT DEFAULT NULL,
+    emergency_contact_phone TEXT DEFAULT NULL,
+    notes TEXT DEFAULT NULL,
+    password_hash TEXT DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE leases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE RESTRICT,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE RESTRICT,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    monthly_rent REAL NOT NULL,
+    deposit_amount REAL DEFAULT NULL,
+    status TEXT NOT NULL DEFAULT 'upcoming',
+    payment_due_day INTEGER NOT NULL DEFAULT 1,
+    notes TEXT DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    unit_id INTEGER DEFAULT NULL REFERENCES units(id) ON DELETE RESTRICT,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE maintenance_requests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE RESTRICT,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE RESTRICT,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT 'other',
+    priority TEXT NOT NULL DEFAULT 'medium',
+    status TEXT NOT NULL DEFAULT 'open',
+    admin_notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    unit_id INTEGER DEFAULT NULL REFERENCES units(id) ON DELETE RESTRICT
+);
+
+CREATE TABLE payments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    lease_id INTEGER NOT NULL REFERENCES leases(id) ON DELETE RESTRICT,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE RESTRICT,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE RESTRICT,
+    amount REAL NOT NULL,
+    payment_date DATE NOT NULL,
+    payment_type TEXT NOT NULL DEFAULT 'rent',
+    status TEXT NOT NULL DEFAULT 'completed',
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    unit_id INTEGER DEFAULT NULL REFERENCES units(id) ON DELETE RESTRICT,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE rental_applications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE RESTRICT,
+    status TEXT NOT NULL DEFAULT 'submitted',
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    phone TEXT NOT NULL,
+    date_of_birth DATE DEFAULT NULL,
+    current_address TEXT DEFAULT NULL,
+    monthly_income REAL NOT NULL DEFAULT 0,
+    employer_name TEXT DEFAULT NULL,
+    job_title TEXT DEFAULT NULL,
+    employment_start_date DATE DEFAULT NULL,
+    desired_move_in DATE NOT NULL,
+    message TEXT DEFAULT NULL,
+    admin_notes TEXT DEFAULT NULL,
+    tenant_id INTEGER DEFAULT NULL REFERENCES tenants(id) ON DELETE SET NULL,
+    lease_id INTEGER DEFAULT NULL REFERENCES leases(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    application_fee REAL NOT NULL DEFAULT 0,
+    fee_paid_at TIMESTAMP DEFAULT NULL,
+    fee_reference TEXT DEFAULT NULL,
+    screening_provider TEXT DEFAULT NULL,
+    screening_report_id TEXT DEFAULT NULL,
+    credit_score INTEGER DEFAULT NULL,
+    eviction_records INTEGER DEFAULT NULL,
+    criminal_records INTEGER DEFAULT NULL,
+    income_to_rent REAL DEFAULT NULL,
+    recommendation TEXT DEFAULT NULL,
+    recommendation_reasons TEXT DEFAULT NULL,
+    screened_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE application_co_applicants (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    application_id INTEGER NOT NULL REFERENCES rental_applications(id) ON DELETE CASCADE,
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    email TEXT DEFAULT NULL,
+    phone TEXT DEFAULT NULL,
+    relationship TEXT DEFAULT NULL,
+    monthly_income REAL NOT NULL DEFAULT 0
+);
+
+CREATE TABLE application_references (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    application_id INTEGER NOT NULL REFERENCES rental_applications(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    relationship TEXT DEFAULT NULL,
+    phone TEXT DEFAULT NULL,
+    email TEXT DEFAULT NULL
+);
+
+CREATE TABLE leads (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    property_id INTEGER DEFAULT NULL REFERENCES properties(id) ON DELETE SET NULL,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    phone TEXT DEFAULT NULL,
+    message TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'new',
+    source TEXT NOT NULL DEFAULT 'contact_form',
+    ip_address TEXT DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE lead_notes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    lead_id INTEGER NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+    note TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE showing_slots (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE showing_bookings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    slot_id INTEGER NOT NULL REFERENCES showing_slots(id) ON DELETE CASCADE,
+    active_slot_id INTEGER DEFAULT NULL UNIQUE,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    phone TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'booked',
+    cancel_token TEXT NOT NULL UNIQUE,
+    cancelled_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE property_images (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    storage_key TEXT NOT NULL UNIQUE,
+    original_ext TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    caption TEXT DEFAULT NULL,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    is_cover BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE units (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    unit_number TEXT NOT NULL,
+    bedrooms INTEGER NOT NULL DEFAULT 1,
+    bathrooms REAL NOT NULL DEFAULT 1.0,
+    square_feet INTEGER DEFAULT NULL,
+    monthly_rent REAL NOT NULL,
+    deposit_amount REAL DEFAULT NULL,
+    available BOOLEAN NOT NULL DEFAULT TRUE,
+    available_date DATE DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (property_id, unit_number)
+);
+
+-- MySQL searches FULLTEXT indexes on properties (migration 0022), which
+-- SQLite lacks; the tests' sqliteTermIndex searches this table instead.
+CREATE TABLE property_search_terms (
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    term TEXT NOT NULL,
+    weight INTEGER NOT NULL DEFAULT 1,
+    PRIMARY KEY (property_id, term)
+);
+
+CREATE TABLE saved_searches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    email TEXT NOT NULL,
+    filters TEXT NOT NULL,
+    token TEXT NOT NULL UNIQUE,
+    verified_at TIMESTAMP DEFAULT NULL,
+    last_sent_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    verification_sent_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE saved_search_matches (
+    saved_search_id INTEGER NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    notified_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (saved_search_id, property_id)
+);
+
+CREATE TABLE property_slug_redirects (
+    slug TEXT PRIMARY KEY,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE rent_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    effective_date DATE NOT NULL,
+    monthly_rent REAL NOT NULL,
+    previous_rent REAL DEFAULT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'applied',
+    applied_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE owners (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    company TEXT DEFAULT NULL,
+    email TEXT NOT NULL UNIQUE,
+    phone TEXT DEFAULT NULL,
+    mailing_address TEXT DEFAULT NULL,
+    payout_method TEXT NOT NULL DEFAULT 'check',
+    payout_account_name TEXT DEFAULT NULL,
+    payout_routing_number TEXT DEFAULT NULL,
+    payout_account_number TEXT DEFAULT NULL,
+    management_fee_percent REAL NOT NULL DEFAULT 0,
+    notes TEXT DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    password_hash TEXT DEFAULT NULL,
+    payout_account_last4 TEXT DEFAULT NULL
+);
+
+CREATE TABLE expenses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE RESTRICT,
+    maintenance_request_id INTEGER DEFAULT NULL REFERENCES maintenance_requests(id) ON DELETE RESTRICT,
+    category TEXT NOT NULL DEFAULT 'other',
+    vendor TEXT NOT NULL,
+    amount REAL NOT NULL,
+    expense_date DATE NOT NULL,
+    description TEXT DEFAULT NULL,
+    receipt_key TEXT DEFAULT NULL,
+    receipt_name TEXT DEFAULT NULL,
+    receipt_content_type TEXT DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
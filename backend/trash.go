@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// TRASH
+// ============================================================================
+
+// Tenants, leases and payments are soft deleted: deleted_at is set and the
+// row drops out of every other query until it is restored or purged.
+// Deleting a record takes its dependents with it (a tenant's leases and
+// payments, a lease's payments), stamped with the same time, so restoring the
+// record brings back exactly what was deleted with it.
+
+// TrashItem is a deleted record as listed in the admin trash.
+type TrashItem struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	Label     string    `json:"label"`
+	DeletedAt time.Time `json:"deletedAt"`
+	// PurgeAt is when the trash-purge job may remove the record for good.
+	PurgeAt time.Time `json:"purgeAt"`
+}
+
+// trashCascade lists, for each kind of record, the tables soft deleted with
+// it and the column pointing back at it. The record's own table comes last.
+var trashCascade = map[string][]struct{ table, column string }{
+	"tenant":  {{"payments", "tenant_id"}, {"leases", "tenant_id"}, {"tenants", "id"}},
+	"lease":   {{"payments", "lease_id"}, {"leases", "id"}},
+	"payment": {{"payments", "id"}},
+}
+
+// trashConflictError is a restore refused because of the record's
+// surroundings, reported as 409.
+type trashConflictError string
+
+func (e trashConflictError) Error() string { return string(e) }
+
+// softDelete moves a record and its dependents to the trash. It reports
+// false when the record does not exist or is already deleted.
+func softDelete(kind string, id int) (bool, error) {
+	steps := trashCascade[kind]
+	record := steps[len(steps)-1]
+	now := time.Now().UTC().Truncate(time.Second)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE "+record.table+" SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", now, id)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	for _, step := range steps[:len(steps)-1] {
+		if _, err := tx.Exec("UPDATE "+step.table+" SET deleted_at = ? WHERE "+step.column+" = ? AND deleted_at IS NULL", now, id); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
+
+// restoreFromTrash brings back a deleted record and whatever was deleted
+// along with it. It returns sql.ErrNoRows when the record is not in the
+// trash.
+func restoreFromTrash(kind string, id int) error {
+	steps := trashCascade[kind]
+	record := steps[len(steps)-1]
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var deleted bool
+	err = tx.QueryRow("SELECT deleted_at IS NOT NULL FROM "+record.table+" WHERE id = ?", id).Scan(&deleted)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return sql.ErrNoRows
+	}
+
+	// A record can't come back while what it belongs to is still deleted.
+	switch kind {
+	case "lease":
+		var tenantDeleted bool
+		if err := tx.QueryRow(`
+			SELECT t.deleted_at IS NOT NULL FROM leases l JOIN tenants t ON t.id = l.tenant_id WHERE l.id = ?
+		`, id).Scan(&tenantDeleted); err != nil {
+			return err
+		}
+		if tenantDeleted {
+			return trashConflictError("Restore the lease's tenant first")
+		}
+		var overlap int
+		if err := tx.QueryRow(`
+			SELECT COUNT(*) FROM leases l
+			JOIN leases o ON o.unit_id = l.unit_id AND o.id <> l.id
+			WHERE l.id = ? AND l.status IN ('active', 'upcoming')
+			AND o.status IN ('active', 'upcoming') AND o.deleted_at IS NULL
+			AND NOT (o.end_date < l.start_date OR o.start_date > l.end_date)
+		`, id).Scan(&overlap); err != nil {
+			return err
+		}
+		if overlap > 0 {
+			return trashConflictError("This unit now has another active or upcoming lease during this period")
+		}
+	case "payment":
+		var leaseDeleted bool
+		if err := tx.QueryRow(`
+			SELECT l.deleted_at IS NOT NULL FROM payments p JOIN leases l ON l.id = p.lease_id WHERE p.id = ?
+		`, id).Scan(&leaseDeleted); err != nil {
+			return err
+		}
+		if leaseDeleted {
+			return trashConflictError("Restore the payment's lease first")
+		}
+	}
+
+	// Dependents first, while the record still carries the deletion time
+	// they were stamped with.
+	for _, step := range steps[:len(steps)-1] {
+		if _, err := tx.Exec(`
+			UPDATE `+step.table+` SET deleted_at = NULL
+			WHERE `+step.column+` = ? AND deleted_at = (SELECT deleted_at FROM `+record.table+` WHERE id = ?)
+		`, id, id); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("UPDATE "+record.table+" SET deleted_at = NULL WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// purgeTrash permanently removes records deleted longer ago than
+// TRASH_RETENTION. Runs as a background job. Records something else still
+// points at (a tenant with maintenance requests, say) are kept.
+func purgeTrash(ctx context.Context) error {
+	cutoff := time.Now().Add(-cfg.Jobs.TrashRetention).UTC()
+	purges := []struct {
+		table string
+		query string
+	}{
+		{"payments", `DELETE FROM payments WHERE deleted_at < ?`},
+		{"leases", `DELETE FROM leases WHERE deleted_at < ?
+			AND NOT EXISTS (SELECT 1 FROM payments p WHERE p.lease_id = leases.id)`},
+		{"tenants", `DELETE FROM tenants WHERE deleted_at < ?
+			AND NOT EXISTS (SELECT 1 FROM leases l WHERE l.tenant_id = tenants.id)
+			AND NOT EXISTS (SELECT 1 FROM payments p WHERE p.tenant_id = tenants.id)
+			AND NOT EXISTS (SELECT 1 FROM maintenance_requests mr WHERE mr.tenant_id = tenants.id)`},
+	}
+
+	var removed []string
+	for _, purge := range purges {
+		result, err := db.ExecContext(ctx, purge.query, cutoff)
+		if err != nil {
+			return fmt.Errorf("purging %s: %w", purge.table, err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			removed = append(removed, fmt.Sprintf("%d %s", n, purge.table))
+		}
+	}
+	if len(removed) > 0 {
+		log.Printf("Trash purge: removed %s", strings.Join(removed, ", "))
+	}
+	return nil
+}
+
+// ============================================================================
+// HANDLERS - ADMIN TRASH
+// ============================================================================
+
+// adminTrashHandler serves GET /api/admin/trash, optionally narrowed with
+// ?type=tenant|lease|payment.
+func adminTrashHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	kind := r.URL.Query().Get("type")
+	if _, ok := trashCascade[kind]; kind != "" && !ok {
+		jsonError(w, "Type must be tenant, lease or payment", http.StatusBadRequest)
+		return
+	}
+
+	items, err := loadTrash(kind)
+	if err != nil {
+		log.Printf("Error loading trash: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, items, http.StatusOK)
+}
+
+// adminTrashItemHandler serves POST /api/admin/trash/:type/:id/restore and
+// answers with the restored record.
+func adminTrashItemHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/trash/"), "/"), "/")
+	if len(parts) != 3 || parts[2] != "restore" {
+		jsonError(w, "Not found", http.StatusNotFound)
+		return
+	}
+	kind := parts[0]
+	if _, ok := trashCascade[kind]; !ok {
+		jsonError(w, "Type must be tenant, lease or payment", http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		jsonError(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	if r.Method != http.MethodPost {
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var bad trashConflictError
+	err = restoreFromTrash(kind, id)
+	switch {
+	case err == sql.ErrNoRows:
+		jsonError(w, "Not found in the trash", http.StatusNotFound)
+		return
+	case errors.As(err, &bad):
+		jsonError(w, bad.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("Error restoring %s %d: %v", kind, id, err)
+		jsonError(w, "Failed to restore", http.StatusInternalServerError)
+		return
+	}
+
+	switch kind {
+	case "tenant":
+		getTenantByID(w, id)
+	case "lease":
+		var unitID sql.NullInt64
+		var status string
+		db.QueryRow("SELECT unit_id, status FROM leases WHERE id = ?", id).Scan(&unitID, &status)
+		if status == "active" && unitID.Valid {
+			setUnitAvailability(int(unitID.Int64), false)
+		}
+		getLeaseByID(w, id)
+	case "payment":
+		getAdminPaymentByID(w, id)
+	}
+}
+
+// loadTrash lists deleted records of one kind, or all kinds, most recently
+// deleted first.
+func loadTrash(kind string) ([]TrashItem, error) {
+	queries := map[string]string{
+		"tenant": `
+			SELECT id, CONCAT(first_name, ' ', last_name), deleted_at
+			FROM tenants WHERE deleted_at IS NOT NULL`,
+		"lease": `
+			SELECT l.id, CONCAT(p.name, ': ', t.first_name, ' ', t.last_name, ', ', l.start_date, ' to ', l.end_date), l.deleted_at
+			FROM leases l
+			JOIN properties p ON p.id = l.property_id
+			JOIN tenants t ON t.id = l.tenant_id
+			WHERE l.deleted_at IS NOT NULL`,
+		"payment": `
+			SELECT pay.id, CONCAT(pay.payment_type, ' payment from ', t.first_name, ' ', t.last_name, ' on ', pay.payment_date), pay.deleted_at
+			FROM payments pay
+			JOIN tenants t ON t.id = pay.tenant_id
+			WHERE pay.deleted_at IS NOT NULL`,
+	}
+
+	items := []TrashItem{}
+	for _, k := range []string{"tenant", "lease", "payment"} {
+		if kind != "" && kind != k {
+			continue
+		}
+		rows, err := db.Query(queries[k])
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			item := TrashItem{Type: k}
+			if err := rows.Scan(&item.ID, &item.Label, &item.DeletedAt); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			item.PurgeAt = item.DeletedAt.Add(cfg.Jobs.TrashRetention)
+			items = append(items, item)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return items, nil
+}
+
+// revokeTenantSessions logs a deleted tenant out of the portal.
+func revokeTenantSessions(tenantID int) {
+	tenantTokenMutex.Lock()
+	defer tenantTokenMutex.Unlock()
+	for token, session := range tenantTokenStore {
+		if session.TenantID == tenantID {
+			delete(tenantTokenStore, token)
+		}
+	}
+}
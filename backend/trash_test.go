@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTrashDeleteAndRestore(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	propertyID := e.createProperty(token, "Trash Flat", 1500)
+	tenantID := e.createTenant(token, "gone@example.com", "password123")
+	rec := e.createLease(token, propertyID, tenantID, day(-400), day(-35))
+	e.expect(rec, http.StatusCreated)
+	lease := decode[Lease](t, rec)
+	rec = e.do(http.MethodPost, "/api/admin/payments", token, Payment{LeaseID: lease.ID, Amount: 1500, PaymentDate: day(-40)})
+	e.expect(rec, http.StatusCreated)
+	payment := decode[Payment](t, rec)
+	tenantToken := e.tenantToken("gone@example.com", "password123")
+
+	// Deleting the tenant takes the lease and payment with it.
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/tenants/%d", tenantID), token, nil), http.StatusNoContent)
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/tenants/%d", tenantID), token, nil), http.StatusNotFound)
+	e.expect(e.do(http.MethodGet, fmt.Sprintf("/api/admin/tenants/%d", tenantID), token, nil), http.StatusNotFound)
+	e.expect(e.do(http.MethodGet, fmt.Sprintf("/api/admin/leases/%d", lease.ID), token, nil), http.StatusNotFound)
+	e.expect(e.do(http.MethodGet, fmt.Sprintf("/api/admin/payments/%d", payment.ID), token, nil), http.StatusNotFound)
+	if payments := decode[[]Payment](t, e.do(http.MethodGet, "/api/admin/payments", token, nil)); len(payments) != 0 {
+		t.Fatalf("expected trashed payments hidden from the list, got %+v", payments)
+	}
+	e.expect(e.do(http.MethodGet, "/api/tenant/me", tenantToken, nil), http.StatusUnauthorized)
+	e.expect(e.do(http.MethodPost, "/api/tenant/login", "", TenantLoginRequest{Email: "gone@example.com", Password: "password123"}), http.StatusUnauthorized)
+
+	rec = e.do(http.MethodGet, "/api/admin/trash", token, nil)
+	e.expect(rec, http.StatusOK)
+	items := decode[[]TrashItem](t, rec)
+	if len(items) != 3 {
+		t.Fatalf("expected the tenant, lease and payment in the trash, got %+v", items)
+	}
+	for _, item := range items {
+		if want := item.DeletedAt.Add(cfg.Jobs.TrashRetention); !item.PurgeAt.Equal(want) {
+			t.Fatalf("expected %s %d to be purged at %v, got %v", item.Type, item.ID, want, item.PurgeAt)
+		}
+	}
+	if tenants := decode[[]TrashItem](t, e.do(http.MethodGet, "/api/admin/trash?type=tenant", token, nil)); len(tenants) != 1 || tenants[0].Label != "Test gone" {
+		t.Fatalf("unexpected tenant trash %+v", tenants)
+	}
+	e.expect(e.do(http.MethodGet, "/api/admin/trash?type=property", token, nil), http.StatusBadRequest)
+
+	// A deleted email can't be reused; the tenant should be restored.
+	e.expect(e.do(http.MethodPost, "/api/admin/tenants", token, map[string]interface{}{
+		"firstName": "New", "lastName": "Tenant", "email": "gone@example.com", "password": "password123",
+	}), http.StatusConflict)
+
+	// Children can't come back before their parents.
+	e.expect(e.do(http.MethodPost, fmt.Sprintf("/api/admin/trash/payment/%d/restore", payment.ID), token, nil), http.StatusConflict)
+	e.expect(e.do(http.MethodPost, fmt.Sprintf("/api/admin/trash/lease/%d/restore", lease.ID), token, nil), http.StatusConflict)
+
+	rec = e.do(http.MethodPost, fmt.Sprintf("/api/admin/trash/tenant/%d/restore", tenantID), token, nil)
+	e.expect(rec, http.StatusOK)
+	if restored := decode[Tenant](t, rec); restored.ID != tenantID {
+		t.Fatalf("expected the restored tenant, got %+v", restored)
+	}
+	e.expect(e.do(http.MethodGet, fmt.Sprintf("/api/admin/leases/%d", lease.ID), token, nil), http.StatusOK)
+	e.expect(e.do(http.MethodGet, fmt.Sprintf("/api/admin/payments/%d", payment.ID), token, nil), http.StatusOK)
+	e.expect(e.do(http.MethodPost, fmt.Sprintf("/api/admin/trash/tenant/%d/restore", tenantID), token, nil), http.StatusNotFound)
+	if items := decode[[]TrashItem](t, e.do(http.MethodGet, "/api/admin/trash", token, nil)); len(items) != 0 {
+		t.Fatalf("expected an empty trash, got %+v", items)
+	}
+
+	// Deleting the payment alone leaves the lease; restoring brings it back.
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/payments/%d", payment.ID), token, nil), http.StatusNoContent)
+	e.expect(e.do(http.MethodGet, fmt.Sprintf("/api/admin/leases/%d", lease.ID), token, nil), http.StatusOK)
+	rec = e.do(http.MethodPost, fmt.Sprintf("/api/admin/trash/payment/%d/restore", payment.ID), token, nil)
+	e.expect(rec, http.StatusOK)
+	if restored := decode[Payment](t, rec); restored.Amount != 1500 {
+		t.Fatalf("unexpected restored payment %+v", restored)
+	}
+}
+
+func TestTrashRestoreSkipsSeparatelyDeletedRecords(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	propertyID := e.createProperty(token, "Trash Flat", 1500)
+	tenantID := e.createTenant(token, "twice@example.com", "password123")
+	rec := e.createLease(token, propertyID, tenantID, day(-400), day(-35))
+	e.expect(rec, http.StatusCreated)
+	lease := decode[Lease](t, rec)
+
+	// The lease was deleted before the tenant, so restoring the tenant
+	// leaves it in the trash.
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/leases/%d", lease.ID), token, nil), http.StatusNoContent)
+	if _, err := db.Exec("UPDATE leases SET deleted_at = ? WHERE id = ?", time.Now().UTC().Add(-time.Hour), lease.ID); err != nil {
+		t.Fatal(err)
+	}
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/tenants/%d", tenantID), token, nil), http.StatusNoContent)
+	e.expect(e.do(http.MethodPost, fmt.Sprintf("/api/admin/trash/tenant/%d/restore", tenantID), token, nil), http.StatusOK)
+	e.expect(e.do(http.MethodGet, fmt.Sprintf("/api/admin/leases/%d", lease.ID), token, nil), http.StatusNotFound)
+
+	// A lease that now overlaps a newer one stays in the trash.
+	e.expect(e.createLease(token, propertyID, tenantID, day(-10), day(300)), http.StatusCreated)
+	if _, err := db.Exec("UPDATE leases SET start_date = ?, end_date = ?, status = 'active' WHERE id = ?", day(-100), day(100), lease.ID); err != nil {
+		t.Fatal(err)
+	}
+	e.expect(e.do(http.MethodPost, fmt.Sprintf("/api/admin/trash/lease/%d/restore", lease.ID), token, nil), http.StatusConflict)
+	e.expect(e.do(http.MethodPost, "/api/admin/trash/lease/9999/restore", token, nil), http.StatusNotFound)
+	e.expect(e.do(http.MethodPost, "/api/admin/trash/unit/1/restore", token, nil), http.StatusBadRequest)
+}
+
+func TestPurgeTrash(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	propertyID := e.createProperty(token, "Trash Flat", 1500)
+	oldID := e.createTenant(token, "old@example.com", "password123")
+	rec := e.createLease(token, propertyID, oldID, day(-400), day(-35))
+	e.expect(rec, http.StatusCreated)
+	lease := decode[Lease](t, rec)
+	e.expect(e.do(http.MethodPost, "/api/admin/payments", token, Payment{LeaseID: lease.ID, Amount: 1500, PaymentDate: day(-40)}), http.StatusCreated)
+	recentID := e.createTenant(token, "recent@example.com", "password123")
+	keptID := e.createTenant(token, "kept@example.com", "password123")
+
+	for _, id := range []int{oldID, recentID} {
+		e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/tenants/%d", id), token, nil), http.StatusNoContent)
+	}
+	expired := time.Now().UTC().Add(-cfg.Jobs.TrashRetention - time.Hour)
+	for _, query := range []string{
+		"UPDATE tenants SET deleted_at = ? WHERE id = ?",
+		"UPDATE leases SET deleted_at = ? WHERE tenant_id = ?",
+		"UPDATE payments SET deleted_at = ? WHERE tenant_id = ?",
+	} {
+		if _, err := db.Exec(query, expired, oldID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := purgeTrash(context.Background()); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+
+	count := func(query string, args ...interface{}) int {
+		t.Helper()
+		var n int
+		if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if n := count("SELECT COUNT(*) FROM tenants WHERE id = ?", oldID); n != 0 {
+		t.Fatal("expected the expired tenant to be purged")
+	}
+	if n := count("SELECT COUNT(*) FROM leases") + count("SELECT COUNT(*) FROM payments"); n != 0 {
+		t.Fatalf("expected the expired lease and payment to be purged, %d rows left", n)
+	}
+	if n := count("SELECT COUNT(*) FROM tenants WHERE id IN (?, ?)", recentID, keptID); n != 2 {
+		t.Fatal("expected the recently deleted and live tenants to be kept")
+	}
+	e.expect(e.do(http.MethodPost, fmt.Sprintf("/api/admin/trash/tenant/%d/restore", recentID), token, nil), http.StatusOK)
+}
@@ -0,0 +1,542 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// MODELS - UNITS
+// ============================================================================
+
+// Unit is one rentable unit in a building. A property is the building; its
+// own bedrooms, rent and availability columns are a rollup of its units,
+// kept for listings and older clients.
+type Unit struct {
+	ID            int       `json:"id"`
+	PropertyID    int       `json:"propertyId"`
+	UnitNumber    string    `json:"unitNumber"`
+	Bedrooms      int       `json:"bedrooms"`
+	Bathrooms     float64   `json:"bathrooms"`
+	SquareFeet    *int      `json:"squareFeet,omitempty"`
+	MonthlyRent   float64   `json:"monthlyRent"`
+	DepositAmount *float64  `json:"depositAmount,omitempty"`
+	Available     bool      `json:"available"`
+	AvailableDate *string   `json:"availableDate,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	// Occupied is true while the unit has an active lease.
+	Occupied bool `json:"occupied"`
+}
+
+// BuildingOccupancy is the per-property occupancy rollup on the dashboard.
+type BuildingOccupancy struct {
+	PropertyID    int    `json:"propertyId"`
+	PropertyName  string `json:"propertyName"`
+	TotalUnits    int    `json:"totalUnits"`
+	OccupiedUnits int    `json:"occupiedUnits"`
+	VacantUnits   int    `json:"vacantUnits"`
+	// OccupancyRate is a percentage, 0 for a building with no units.
+	OccupancyRate float64 `json:"occupancyRate"`
+}
+
+// defaultUnitNumber labels the unit created for a single-unit property.
+const defaultUnitNumber = "1"
+
+// sqlExecutor is satisfied by both *sql.DB and *sql.Tx.
+type sqlExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// badUnitError is a unit lookup failure caused by the request rather than
+// the database; handlers report it as a 400.
+type badUnitError string
+
+func (e badUnitError) Error() string { return string(e) }
+
+// ============================================================================
+// HANDLERS - ADMIN UNITS
+// ============================================================================
+
+// propertyUnitsHandler serves /api/admin/properties/:id/units[/:unitId]; rest
+// is whatever follows "units".
+func propertyUnitsHandler(w http.ResponseWriter, r *http.Request, propertyID int, rest string) {
+	var exists int
+	db.QueryRow("SELECT COUNT(*) FROM properties WHERE id = ?", propertyID).Scan(&exists)
+	if exists == 0 {
+		jsonError(w, "Property not found", http.StatusNotFound)
+		return
+	}
+
+	if rest == "" {
+		switch r.Method {
+		case http.MethodGet:
+			units, err := loadUnits(db, propertyID)
+			if err != nil {
+				log.Printf("Error loading units: %v", err)
+				jsonError(w, "Database error", http.StatusInternalServerError)
+				return
+			}
+			jsonResponse(w, units, http.StatusOK)
+		case http.MethodPost:
+			createUnit(w, r, propertyID)
+		default:
+			jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	unitID, err := strconv.Atoi(rest)
+	if err != nil {
+		jsonError(w, "Invalid unit ID", http.StatusBadRequest)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		getUnitByID(w, propertyID, unitID)
+	case http.MethodPut:
+		updateUnit(w, r, propertyID, unitID)
+	case http.MethodDelete:
+		deleteUnit(w, propertyID, unitID)
+	default:
+		jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func getUnitByID(w http.ResponseWriter, propertyID, unitID int) {
+	u, err := loadUnit(db, unitID)
+	if err == sql.ErrNoRows || (err == nil && u.PropertyID != propertyID) {
+		jsonError(w, "Unit not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting unit: %v", err)
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, u, http.StatusOK)
+}
+
+func createUnit(w http.ResponseWriter, r *http.Request, propertyID int) {
+	var u Unit
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if msg := validateUnit(&u); msg != "" {
+		jsonError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if unitNumberTaken(propertyID, u.UnitNumber, 0) {
+		jsonError(w, "A unit with this number already exists in this property", http.StatusConflict)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	id, err := insertUnit(tx, propertyID, u)
+	if err == nil {
+		err = syncPropertyFromUnits(tx, propertyID)
+	}
+	if err == nil {
+		err = recordRentChange(tx, propertyID, "Unit added")
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("Error creating unit: %v", err)
+		jsonError(w, "Failed to create unit", http.StatusInternalServerError)
+		return
+	}
+
+	u, err = loadUnit(db, id)
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, u, http.StatusCreated)
+}
+
+func updateUnit(w http.ResponseWriter, r *http.Request, propertyID, unitID int) {
+	var u Unit
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		jsonError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if msg := validateUnit(&u); msg != "" {
+		jsonError(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	var exists int
+	db.QueryRow("SELECT COUNT(*) FROM units WHERE id = ? AND property_id = ?", unitID, propertyID).Scan(&exists)
+	if exists == 0 {
+		jsonError(w, "Unit not found", http.StatusNotFound)
+		return
+	}
+	if unitNumberTaken(propertyID, u.UnitNumber, unitID) {
+		jsonError(w, "A unit with this number already exists in this property", http.StatusConflict)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE units SET unit_number=?, bedrooms=?, bathrooms=?, square_feet=?, monthly_rent=?,
+			deposit_amount=?, available=?, available_date=?
+		WHERE id=?
+	`, u.UnitNumber, u.Bedrooms, u.Bathrooms, u.SquareFeet, u.MonthlyRent,
+		u.DepositAmount, u.Available, u.AvailableDate, unitID)
+	if err == nil {
+		err = syncPropertyFromUnits(tx, propertyID)
+	}
+	if err == nil {
+		err = recordRentChange(tx, propertyID, "Unit edited")
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("Error updating unit: %v", err)
+		jsonError(w, "Failed to update unit", http.StatusInternalServerError)
+		return
+	}
+
+	getUnitByID(w, propertyID, unitID)
+}
+
+// deleteUnit removes a unit that has never been leased. The last unit of a
+// building cannot be removed; delete the property instead.
+func deleteUnit(w http.ResponseWriter, propertyID, unitID int) {
+	var exists, unitCount, history int
+	db.QueryRow("SELECT COUNT(*) FROM units WHERE id = ? AND property_id = ?", unitID, propertyID).Scan(&exists)
+	if exists == 0 {
+		jsonError(w, "Unit not found", http.StatusNotFound)
+		return
+	}
+	db.QueryRow("SELECT COUNT(*) FROM units WHERE property_id = ?", propertyID).Scan(&unitCount)
+	if unitCount <= 1 {
+		jsonError(w, "A property must have at least one unit", http.StatusConflict)
+		return
+	}
+	db.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM leases WHERE unit_id = ?)
+			 + (SELECT COUNT(*) FROM maintenance_requests WHERE unit_id = ?)
+			 + (SELECT COUNT(*) FROM payments WHERE unit_id = ?)
+	`, unitID, unitID, unitID).Scan(&history)
+	if history > 0 {
+		jsonError(w, "Cannot delete a unit with leases, requests or payments", http.StatusConflict)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM units WHERE id = ?", unitID)
+	if err == nil {
+		err = syncPropertyFromUnits(tx, propertyID)
+	}
+	if err == nil {
+		err = recordRentChange(tx, propertyID, "Unit removed")
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("Error deleting unit: %v", err)
+		jsonError(w, "Failed to delete unit", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ============================================================================
+// UNIT HELPERS
+// ============================================================================
+
+// validateUnit normalises u and returns a client-facing message when it is
+// not acceptable.
+func validateUnit(u *Unit) string {
+	u.UnitNumber = strings.TrimSpace(u.UnitNumber)
+	if u.UnitNumber == "" {
+		return "Unit number is required"
+	}
+	if u.Bedrooms < 0 || u.Bathrooms < 0 || u.MonthlyRent < 0 {
+		return "Bedrooms, bathrooms, and rent cannot be negative"
+	}
+	return ""
+}
+
+func unitNumberTaken(propertyID int, unitNumber string, exceptID int) bool {
+	var count int
+	db.QueryRow("SELECT COUNT(*) FROM units WHERE property_id = ? AND unit_number = ? AND id != ?",
+		propertyID, unitNumber, exceptID).Scan(&count)
+	return count > 0
+}
+
+// unitFromProperty is the single unit a property gets when it is created
+// without an explicit unit list.
+func unitFromProperty(p Property) Unit {
+	return Unit{
+		UnitNumber:    defaultUnitNumber,
+		Bedrooms:      p.Bedrooms,
+		Bathrooms:     p.Bathrooms,
+		SquareFeet:    p.SquareFeet,
+		MonthlyRent:   p.MonthlyRent,
+		DepositAmount: p.DepositAmount,
+		Available:     p.Available,
+		AvailableDate: p.AvailableDate,
+	}
+}
+
+func insertUnit(ex sqlExecutor, propertyID int, u Unit) (int, error) {
+	result, err := ex.Exec(`
+		INSERT INTO units (property_id, unit_number, bedrooms, bathrooms, square_feet, monthly_rent,
+			deposit_amount, available, available_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, propertyID, u.UnitNumber, u.Bedrooms, u.Bathrooms, u.SquareFeet, u.MonthlyRent,
+		u.DepositAmount, u.Available, u.AvailableDate)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := result.LastInsertId()
+	return int(id), nil
+}
+
+// syncPropertyFromUnits recomputes the property's unit-level columns from its
+// units. When any unit is available the rollup describes the available ones,
+// so a listing advertises what can actually be rented: the lowest rent and
+// deposit, the largest layout, and the earliest available date. A building
+// with a single unit mirrors it exactly.
+func syncPropertyFromUnits(ex sqlExecutor, propertyID int) error {
+	var anyAvailable int
+	if err := ex.QueryRow("SELECT COUNT(*) FROM units WHERE property_id = ? AND available = TRUE", propertyID).Scan(&anyAvailable); err != nil {
+		return err
+	}
+
+	filter := ""
+	if anyAvailable > 0 {
+		filter = " AND available = TRUE"
+	}
+	var bedrooms sql.NullInt64
+	var bathrooms, rent, deposit sql.NullFloat64
+	var sqft sql.NullInt64
+	var availableDate sql.NullString
+	err := ex.QueryRow(`
+		SELECT MAX(bedrooms), MAX(bathrooms), MAX(square_feet), MIN(monthly_rent),
+			   MIN(deposit_amount), MIN(available_date)
+		FROM units WHERE property_id = ?`+filter, propertyID).
+		Scan(&bedrooms, &bathrooms, &sqft, &rent, &deposit, &availableDate)
+	if err != nil {
+		return err
+	}
+	if !rent.Valid {
+		// No units left; leave the last known values in place.
+		return nil
+	}
+
+	var date interface{}
+	if availableDate.Valid {
+		date = dateOnly(availableDate.String)
+	}
+	_, err = ex.Exec(`
+		UPDATE properties SET bedrooms=?, bathrooms=?, square_feet=?, monthly_rent=?,
+			deposit_amount=?, available=?, available_date=?
+		WHERE id=?
+	`, bedrooms.Int64, bathrooms.Float64, sqft, rent.Float64, deposit, anyAvailable > 0, date, propertyID)
+	return err
+}
+
+// setUnitAvailability flips a unit's availability when a lease starts or is
+// removed, then refreshes the building rollup.
+func setUnitAvailability(unitID int, available bool) {
+	var propertyID int
+	if err := db.QueryRow("SELECT property_id FROM units WHERE id = ?", unitID).Scan(&propertyID); err != nil {
+		return
+	}
+	db.Exec("UPDATE units SET available = ? WHERE id = ?", available, unitID)
+	if err := syncPropertyFromUnits(db, propertyID); err != nil {
+		log.Printf("Error syncing property %d from units: %v", propertyID, err)
+		return
+	}
+	if err := recordRentChange(db, propertyID, "Unit availability changed"); err != nil {
+		log.Printf("Error recording rent change for property %d: %v", propertyID, err)
+	}
+}
+
+// resolveUnit works out which unit a lease belongs to. unitID wins when
+// given; otherwise a single-unit property implies its only unit. Mistakes in
+// the request come back as badUnitError.
+func resolveUnit(propertyID int, unitID *int) (Unit, error) {
+	if unitID != nil && *unitID != 0 {
+		u, err := loadUnit(db, *unitID)
+		if err == sql.ErrNoRows {
+			return u, badUnitError("Unit not found")
+		}
+		if err != nil {
+			return u, err
+		}
+		if propertyID != 0 && u.PropertyID != propertyID {
+			return u, badUnitError("Unit does not belong to this property")
+		}
+		return u, nil
+	}
+
+	units, err := loadUnits(db, propertyID)
+	if err != nil {
+		return Unit{}, err
+	}
+	switch len(units) {
+	case 0:
+		return Unit{}, badUnitError("Property not found")
+	case 1:
+		return units[0], nil
+	default:
+		return Unit{}, badUnitError("Unit is required for properties with more than one unit")
+	}
+}
+
+// writeUnitError reports a resolveUnit failure.
+func writeUnitError(w http.ResponseWriter, err error) {
+	var bad badUnitError
+	if errors.As(err, &bad) {
+		jsonError(w, bad.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Error resolving unit: %v", err)
+	jsonError(w, "Database error", http.StatusInternalServerError)
+}
+
+// loadBuildingOccupancy returns per-property unit occupancy, by name,
+// optionally for one owner's properties only.
+func loadBuildingOccupancy(ownerID string) ([]BuildingOccupancy, error) {
+	clause, args, err := ownerFilter(ownerID, "p.id")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`
+		SELECT p.id, p.name, COUNT(u.id),
+			   COALESCE(SUM(CASE WHEN EXISTS (
+				   SELECT 1 FROM leases l WHERE l.unit_id = u.id AND l.status = 'active' AND l.deleted_at IS NULL
+			   ) THEN 1 ELSE 0 END), 0)
+		FROM properties p
+		LEFT JOIN units u ON u.property_id = p.id
+		WHERE 1=1`+clause+`
+		GROUP BY p.id, p.name
+		ORDER BY p.name, p.id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buildings := []BuildingOccupancy{}
+	for rows.Next() {
+		var b BuildingOccupancy
+		if err := rows.Scan(&b.PropertyID, &b.PropertyName, &b.TotalUnits, &b.OccupiedUnits); err != nil {
+			return nil, err
+		}
+		b.VacantUnits = b.TotalUnits - b.OccupiedUnits
+		if b.TotalUnits > 0 {
+			b.OccupancyRate = math.Round(float64(b.OccupiedUnits)/float64(b.TotalUnits)*1000) / 10
+		}
+		buildings = append(buildings, b)
+	}
+	return buildings, rows.Err()
+}
+
+// ============================================================================
+// SCAN HELPERS - UNITS
+// ============================================================================
+
+const unitColumns = `
+	u.id, u.property_id, u.unit_number, u.bedrooms, u.bathrooms, u.square_feet, u.monthly_rent,
+	u.deposit_amount, u.available, u.available_date, u.created_at, u.updated_at,
+	EXISTS (SELECT 1 FROM leases l WHERE l.unit_id = u.id AND l.status = 'active' AND l.deleted_at IS NULL) AS occupied`
+
+func loadUnit(ex sqlExecutor, id int) (Unit, error) {
+	rows, err := ex.Query(`SELECT `+unitColumns+` FROM units u WHERE u.id = ?`, id)
+	if err != nil {
+		return Unit{}, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return Unit{}, err
+		}
+		return Unit{}, sql.ErrNoRows
+	}
+	return scanUnit(rows)
+}
+
+// loadUnits returns a building's units ordered by unit number.
+func loadUnits(ex sqlExecutor, propertyID int) ([]Unit, error) {
+	rows, err := ex.Query(`SELECT `+unitColumns+`
+		FROM units u WHERE u.property_id = ?
+		ORDER BY u.unit_number, u.id
+	`, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	units := []Unit{}
+	for rows.Next() {
+		u, err := scanUnit(rows)
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, u)
+	}
+	return units, rows.Err()
+}
+
+func scanUnit(rows *sql.Rows) (Unit, error) {
+	var u Unit
+	var squareFeet sql.NullInt64
+	var deposit sql.NullFloat64
+	var availableDate sql.NullString
+
+	err := rows.Scan(&u.ID, &u.PropertyID, &u.UnitNumber, &u.Bedrooms, &u.Bathrooms, &squareFeet,
+		&u.MonthlyRent, &deposit, &u.Available, &availableDate, &u.CreatedAt, &u.UpdatedAt, &u.Occupied)
+	if err != nil {
+		return u, err
+	}
+
+	u.SquareFeet = nullIntPtr(squareFeet)
+	if deposit.Valid {
+		u.DepositAmount = &deposit.Float64
+	}
+	if availableDate.Valid {
+		date := dateOnly(availableDate.String)
+		u.AvailableDate = &date
+	}
+
+	return u, nil
+}
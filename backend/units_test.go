@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// createBuilding creates a property with the given units and returns it.
+func (e *testEnv) createBuilding(token, name string, units []Unit) Property {
+	e.t.Helper()
+	rec := e.do(http.MethodPost, "/api/admin/properties", token, Property{
+		Name:         name,
+		AddressLine1: "10 Fourplex Way",
+		City:         "Portland",
+		State:        "OR",
+		Zip:          "97201",
+		PropertyType: "apartment",
+		Units:        units,
+	})
+	e.expect(rec, http.StatusCreated)
+	return decode[Property](e.t, rec)
+}
+
+func fourplexUnits() []Unit {
+	return []Unit{
+		{UnitNumber: "A", Bedrooms: 1, Bathrooms: 1, MonthlyRent: 1100, Available: true},
+		{UnitNumber: "B", Bedrooms: 2, Bathrooms: 1, MonthlyRent: 1400, Available: true},
+		{UnitNumber: "C", Bedrooms: 3, Bathrooms: 2, MonthlyRent: 1900, Available: false},
+	}
+}
+
+func TestSingleUnitPropertyMirrorsUnit(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	pid := e.createProperty(token, "Cottage", 1500)
+	path := fmt.Sprintf("/api/admin/properties/%d", pid)
+
+	p := decode[Property](t, e.do(http.MethodGet, path, token, nil))
+	if len(p.Units) != 1 || p.Units[0].UnitNumber != defaultUnitNumber || p.Units[0].MonthlyRent != 1500 || p.Units[0].Bedrooms != 2 {
+		t.Fatalf("expected one default unit, got %+v", p.Units)
+	}
+
+	// Editing the property edits its only unit.
+	p.MonthlyRent = 1600
+	p.Available = false
+	e.expect(e.do(http.MethodPut, path, token, p), http.StatusOK)
+	units := decode[[]Unit](t, e.do(http.MethodGet, path+"/units", token, nil))
+	if units[0].MonthlyRent != 1600 || units[0].Available {
+		t.Fatalf("unit not updated through property: %+v", units[0])
+	}
+}
+
+func TestMultiUnitRollup(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+
+	e.expect(e.do(http.MethodPost, "/api/admin/properties", token, Property{
+		Name: "Dup", AddressLine1: "1 St", City: "Portland", State: "OR", Zip: "97201",
+		Units: []Unit{{UnitNumber: "A", MonthlyRent: 1}, {UnitNumber: "A", MonthlyRent: 2}},
+	}), http.StatusBadRequest)
+
+	p := e.createBuilding(token, "Fourplex", fourplexUnits())
+	if len(p.Units) != 3 {
+		t.Fatalf("expected 3 units, got %d", len(p.Units))
+	}
+	// The listing describes the available units: cheapest rent, largest layout.
+	if !p.Available || p.MonthlyRent != 1100 || p.Bedrooms != 2 {
+		t.Fatalf("unexpected rollup: available=%v rent=%v beds=%d", p.Available, p.MonthlyRent, p.Bedrooms)
+	}
+
+	base := fmt.Sprintf("/api/admin/properties/%d/units", p.ID)
+	e.expect(e.do(http.MethodPost, base, token, Unit{UnitNumber: "B", MonthlyRent: 1000}), http.StatusConflict)
+	e.expect(e.do(http.MethodPost, base, token, Unit{MonthlyRent: 1000}), http.StatusBadRequest)
+	rec := e.do(http.MethodPost, base, token, Unit{UnitNumber: "D", Bedrooms: 2, Bathrooms: 1, MonthlyRent: 950, Available: true})
+	e.expect(rec, http.StatusCreated)
+	d := decode[Unit](t, rec)
+
+	got := decode[Property](t, e.do(http.MethodGet, fmt.Sprintf("/api/properties/%d", p.ID), "", nil))
+	if got.MonthlyRent != 950 || len(got.Units) != 4 {
+		t.Fatalf("expected rent from 950 across 4 units, got %v across %d", got.MonthlyRent, len(got.Units))
+	}
+
+	d.Available = false
+	e.expect(e.do(http.MethodPut, fmt.Sprintf("%s/%d", base, d.ID), token, d), http.StatusOK)
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("%s/%d", base, d.ID), token, nil), http.StatusNoContent)
+	e.expect(e.do(http.MethodGet, fmt.Sprintf("%s/%d", base, d.ID), token, nil), http.StatusNotFound)
+
+	got = decode[Property](t, e.do(http.MethodGet, fmt.Sprintf("/api/properties/%d", p.ID), "", nil))
+	if got.MonthlyRent != 1100 {
+		t.Fatalf("expected rollup back to 1100, got %v", got.MonthlyRent)
+	}
+}
+
+func TestLeasesAttachToUnits(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.adminToken()
+	p := e.createBuilding(token, "Fourplex", fourplexUnits())
+	unitA, unitB := p.Units[0], p.Units[1]
+	t1 := e.createTenant(token, "a@example.com", "a-secret")
+	t2 := e.createTenant(token, "b@example.com", "")
+
+	lease := func(unitID, tenantID int) map[string]interface{} {
+		return map[string]interface{}{
+			"propertyId": p.ID, "unitId": unitID, "tenantId": tenantID,
+			"startDate": day(-10), "endDate": day(300), "monthlyRent": 1100,
+		}
+	}
+
+	// A multi-unit building needs the unit spelled out.
+	e.expect(e.createLease(token, p.ID, t1, day(-10), day(300)), http.StatusBadRequest)
+	other := e.createProperty(token, "Elsewhere", 1000)
+	e.expect(e.do(http.MethodPost, "/api/admin/leases", token, map[string]interface{}{
+		"propertyId": other, "unitId": unitA.ID, "tenantId": t1, "startDate": day(-10), "endDate": day(300),
+	}), http.StatusBadRequest)
+
+	rec := e.do(http.MethodPost, "/api/admin/leases", token, lease(unitA.ID, t1))
+	e.expect(rec, http.StatusCreated)
+	l := decode[Lease](t, rec)
+	if l.UnitID == nil || *l.UnitID != unitA.ID {
+		t.Fatalf("expected lease on unit %d, got %v", unitA.ID, l.UnitID)
+	}
+
+	// Another unit in the same building can be leased for the same period.
+	e.expect(e.do(http.MethodPost, "/api/admin/leases", token, lease(unitA.ID, t2)), http.StatusConflict)
+	e.expect(e.do(http.MethodPost, "/api/admin/leases", token, lease(unitB.ID, t2)), http.StatusCreated)
+
+	// Both available units are now let, so the building is off the market.
+	got := decode[Property](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/properties/%d", p.ID), token, nil))
+	if got.Available || !got.Units[0].Occupied || got.Units[0].Available {
+		t.Fatalf("expected building unavailable with unit A occupied: %+v", got)
+	}
+
+	leases := decode[[]Lease](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/leases?unitId=%d", unitA.ID), token, nil))
+	if len(leases) != 1 || leases[0].UnitNumber == nil || *leases[0].UnitNumber != "A" {
+		t.Fatalf("unexpected unit lease list: %+v", leases)
+	}
+
+	// Payments and tenant requests inherit the lease's unit.
+	rec = e.do(http.MethodPost, "/api/admin/payments", token, Payment{LeaseID: l.ID, Amount: 1100, PaymentDate: day(0)})
+	e.expect(rec, http.StatusCreated)
+	if pay := decode[Payment](t, rec); pay.UnitID == nil || *pay.UnitID != unitA.ID {
+		t.Fatalf("payment not attached to unit: %+v", pay)
+	}
+	tenant := e.tenantToken("a@example.com", "a-secret")
+	e.expect(e.do(http.MethodPost, "/api/tenant/requests", tenant, map[string]string{"title": "Leak", "description": "Sink"}), http.StatusCreated)
+	requests := decode[[]MaintenanceRequest](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/requests?unitId=%d", unitA.ID), token, nil))
+	if len(requests) != 1 || requests[0].UnitNumber == nil || *requests[0].UnitNumber != "A" {
+		t.Fatalf("request not attached to unit: %+v", requests)
+	}
+
+	// Units with history, and a building's last unit, stay put.
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/properties/%d/units/%d", p.ID, unitA.ID), token, nil), http.StatusConflict)
+	otherUnits := decode[[]Unit](t, e.do(http.MethodGet, fmt.Sprintf("/api/admin/properties/%d/units", other), token, nil))
+	e.expect(e.do(http.MethodDelete, fmt.Sprintf("/api/admin/properties/%d/units/%d", other, otherUnits[0].ID), token, nil), http.StatusConflict)
+
+	stats := decode[DashboardStats](t, e.do(http.MethodGet, "/api/admin/dashboard/stats", token, nil))
+	for _, b := range stats.Buildings {
+		if b.PropertyID == p.ID && (b.TotalUnits != 3 || b.OccupiedUnits != 2 || b.OccupancyRate != 66.7) {
+			t.Fatalf("unexpected building occupancy: %+v", b)
+		}
+	}
+	if stats.TotalUnits != 4 || stats.OccupiedUnits != 2 {
+		t.Fatalf("unexpected unit totals: %d/%d", stats.OccupiedUnits, stats.TotalUnits)
+	}
+}
@@ -3,11 +3,12 @@
 import { useState } from 'react'
 import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query'
 import { fetchAdminProperties, createProperty, updateProperty, deleteProperty } from '@/lib/api'
-import { Property, PropertyCreate } from '@/data/types'
+import { ListingStatus, Property, PropertyCreate } from '@/data/types'
 import { formatCurrency } from '@/lib/format'
 import { useEscapeKey } from '@/hooks/useEscapeKey'
 
 const propertyTypes = ['apartment', 'house', 'duplex', 'condo', 'townhouse', 'studio']
+const listingStatuses: ListingStatus[] = ['draft', 'listed', 'unlisted', 'archived']
 
 export default function AdminPropertiesPage() {
   const queryClient = useQueryClient()
@@ -15,10 +16,12 @@ export default function AdminPropertiesPage() {
   const [editingProperty, setEditingProperty] = useState<Property | null>(null)
   const [deleteConfirm, setDeleteConfirm] = useState<number | null>(null)
   const [error, setError] = useState('')
+  // Empty shows everything but archived properties
+  const [statusFilter, setStatusFilter] = useState<ListingStatus | 'all' | ''>('')
 
   const { data: properties, isLoading, isError, error: queryError } = useQuery({
-    queryKey: ['admin-properties'],
-    queryFn: fetchAdminProperties,
+    queryKey: ['admin-properties', statusFilter],
+    queryFn: () => fetchAdminProperties(statusFilter || undefined),
     retry: false,
   })
 
@@ -80,9 +83,23 @@ export default function AdminPropertiesPage() {
       <div className="bg-white rounded-xl shadow-sm overflow-hidden border border-stone-200">
         {/* Header */}
         <div className="px-6 py-4 border-b border-stone-200 flex items-center justify-between">
-          <p className="text-sm text-stone-500">
-            {properties?.length || 0} {(properties?.length || 0) === 1 ? 'property' : 'properties'}
-          </p>
+          <div className="flex items-center gap-4">
+            <p className="text-sm text-stone-500">
+              {properties?.length || 0} {(properties?.length || 0) === 1 ? 'property' : 'properties'}
+            </p>
+            <select
+              value={statusFilter}
+              onChange={(e) => setStatusFilter(e.target.value as ListingStatus | 'all' | '')}
+              aria-label="Filter by listing status"
+              className="px-3 py-1.5 text-sm border border-stone-300 rounded-lg focus:ring-2 focus:ring-clover-500 focus:border-clover-500"
+            >
+              <option value="">Active</option>
+              {listingStatuses.map((status) => (
+                <option key={status} value={status}>{status.charAt(0).toUpperCase() + status.slice(1)}</option>
+              ))}
+              <option value="all">All</option>
+            </select>
+          </div>
           <button
             onClick={handleAdd}
             className="px-4 py-2 bg-clover-600 hover:bg-clover-700 text-white font-medium rounded-lg transition-colors"
@@ -142,6 +159,11 @@ export default function AdminPropertiesPage() {
                       }`}>
                         {property.available ? 'Available' : 'Leased'}
                       </span>
+                      {property.listingStatus && property.listingStatus !== 'listed' && (
+                        <span className="ml-2 px-2 py-1 text-xs font-medium rounded-full bg-amber-100 text-amber-800 capitalize">
+                          {property.listingStatus}
+                        </span>
+                      )}
                     </td>
                     <td className="py-3 px-6">
                       <div className="flex gap-2">
@@ -152,13 +174,15 @@ export default function AdminPropertiesPage() {
                         >
                           Edit
                         </button>
-                        <button
-                          onClick={() => setDeleteConfirm(property.id)}
-                          aria-label={`Delete ${property.name}`}
-                          className="px-3 py-1 text-sm font-medium text-red-700 bg-red-50 hover:bg-red-100 rounded transition-colors"
-                        >
-                          Delete
-                        </button>
+                        {property.listingStatus !== 'archived' && (
+                          <button
+                            onClick={() => setDeleteConfirm(property.id)}
+                            aria-label={`Archive ${property.name}`}
+                            className="px-3 py-1 text-sm font-medium text-red-700 bg-red-50 hover:bg-red-100 rounded transition-colors"
+                          >
+                            Archive
+                          </button>
+                        )}
                       </div>
                     </td>
                   </tr>
@@ -193,10 +217,10 @@ export default function AdminPropertiesPage() {
         >
           <div className="bg-white rounded-xl shadow-xl max-w-md w-full p-6 border border-stone-200">
             <h3 id="delete-property-title" className="text-lg font-semibold text-stone-900 mb-2">
-              Confirm Delete
+              Archive Property
             </h3>
             <p className="text-stone-600 mb-6">
-              Are you sure you want to delete this property? This action cannot be undone.
+              The property will be removed from the public site. Its leases and payments are kept, and you can restore it by changing its listing status.
             </p>
             <div className="flex justify-end gap-3">
               <button
@@ -212,7 +236,7 @@ export default function AdminPropertiesPage() {
                 disabled={deleteMutation.isPending}
                 className="px-4 py-2 text-sm font-medium text-white bg-red-600 hover:bg-red-700 rounded-lg transition-colors disabled:opacity-50"
               >
-                {deleteMutation.isPending ? 'Deleting...' : 'Delete Property'}
+                {deleteMutation.isPending ? 'Archiving...' : 'Archive Property'}
               </button>
             </div>
           </div>
@@ -246,6 +270,7 @@ function PropertyModal({ property, onSave, onClose, isLoading }: PropertyModalPr
     monthlyRent: property?.monthlyRent || 0,
     depositAmount: property?.depositAmount || undefined,
     available: property?.available ?? true,
+    listingStatus: property?.listingStatus ?? 'listed',
   })
 
   const [amenityInput, setAmenityInput] = useState('')
@@ -425,6 +450,18 @@ function PropertyModal({ property, onSave, onClose, isLoading }: PropertyModalPr
                 <span className="text-sm font-medium text-stone-700">Available for rent</span>
               </label>
             </div>
+
+            <div>
+              <label htmlFor="prop-listing-status" className={labelClass}>Listing Status</label>
+              <select id="prop-listing-status" value={formData.listingStatus}
+                onChange={(e) => setFormData({ ...formData, listingStatus: e.target.value as ListingStatus })}
+                className={inputClass}>
+                {listingStatuses.map((status) => (
+                  <option key={status} value={status}>{status.charAt(0).toUpperCase() + status.slice(1)}</option>
+                ))}
+              </select>
+              <p className="mt-1 text-xs text-stone-500">Only listed properties appear on the public site.</p>
+            </div>
           </div>
 
           <div className="flex justify-end gap-3 pt-4 border-t border-stone-200">
@@ -3,7 +3,7 @@
 import { use } from 'react'
 import Link from 'next/link'
 import { useQuery } from '@tanstack/react-query'
-import { fetchProperty } from '@/lib/api'
+import { fetchProperty, fetchPropertyJsonLd } from '@/lib/api'
 import { siteConfig } from '@/data/site'
 import { formatCurrency } from '@/lib/format'
 
@@ -12,13 +12,18 @@ interface PropertyDetailPageProps {
 }
 
 export default function PropertyDetailPage({ params }: PropertyDetailPageProps) {
+  // The route segment is a slug, or a numeric ID for links from before slugs.
   const { id } = use(params)
-  const propertyId = parseInt(id, 10)
 
   const { data: property, isLoading, error } = useQuery({
-    queryKey: ['property', propertyId],
-    queryFn: () => fetchProperty(propertyId),
-    enabled: !isNaN(propertyId),
+    queryKey: ['property', id],
+    queryFn: () => fetchProperty(id),
+  })
+
+  const { data: jsonLd } = useQuery({
+    queryKey: ['property-jsonld', id],
+    queryFn: () => fetchPropertyJsonLd(id),
+    enabled: !!property,
   })
 
   const formatPropertyType = (type: string) => {
@@ -63,6 +68,13 @@ export default function PropertyDetailPage({ params }: PropertyDetailPageProps)
 
   return (
     <div className="min-h-screen bg-stone-100">
+      {jsonLd && (
+        <script
+          type="application/ld+json"
+          dangerouslySetInnerHTML={{ __html: JSON.stringify(jsonLd).replace(/</g, '\\u003c') }}
+        />
+      )}
+
       {/* Breadcrumb */}
       <div className="bg-white border-b border-stone-200">
         <div className="max-w-4xl mx-auto px-4 sm:px-6 lg:px-8 py-4">
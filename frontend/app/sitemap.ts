@@ -1,6 +1,9 @@
 import { MetadataRoute } from 'next'
+import { fetchSitemapEntries } from '@/lib/api'
 
-export default function sitemap(): MetadataRoute.Sitemap {
+export const revalidate = 3600
+
+export default async function sitemap(): Promise<MetadataRoute.Sitemap> {
   const baseUrl = 'https://rosesandclovers.com'
 
   const staticPages = [
@@ -15,5 +18,18 @@ export default function sitemap(): MetadataRoute.Sitemap {
     priority: route === '' ? 1 : 0.8,
   }))
 
-  return staticPages
+  // Property pages by slug; if the API is unreachable at build time the
+  // static pages are still published.
+  const propertyPages = await fetchSitemapEntries()
+    .then((entries) =>
+      entries.map((entry) => ({
+        url: `${baseUrl}/properties/${entry.slug}`,
+        lastModified: new Date(entry.updatedAt),
+        changeFrequency: 'daily' as const,
+        priority: 0.7,
+      }))
+    )
+    .catch(() => [])
+
+  return [...staticPages, ...propertyPages]
 }
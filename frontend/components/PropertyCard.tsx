@@ -21,7 +21,7 @@ export function PropertyCard({ property, showStatus = true }: PropertyCardProps)
 
   return (
     <Link
-      href={`/properties/${property.id}`}
+      href={`/properties/${property.slug ?? property.id}`}
       className="group block bg-white rounded-xl border border-stone-200 overflow-hidden shadow-sm hover:shadow-lg transition-all hover:border-clover-300"
     >
       {/* Content */}
@@ -20,10 +20,23 @@ export interface Property {
   description?: string | null
   amenities?: string[]
   imageUrl?: string | null
+  slug?: string
+  listingStatus?: ListingStatus
+  ownerId?: number
   createdAt?: string
   updatedAt?: string
 }
 
+// Public visibility, separate from available (whether the property is let)
+export type ListingStatus = 'draft' | 'listed' | 'unlisted' | 'archived'
+
+export interface SitemapEntry {
+  id: number
+  slug: string
+  url: string
+  updatedAt: string
+}
+
 export interface PropertyCreate {
   name: string
   addressLine1: string
@@ -38,10 +51,31 @@ export interface PropertyCreate {
   monthlyRent: number
   depositAmount?: number | null
   available: boolean
+  listingStatus?: ListingStatus
   description?: string | null
   amenities?: string[]
 }
 
+// Owner (landlord) types
+export interface Owner {
+  id: number
+  name: string
+  company?: string | null
+  email: string
+  phone?: string | null
+  mailingAddress?: string | null
+  payoutMethod: 'ach' | 'check'
+  payoutAccountName?: string | null
+  payoutRoutingNumber?: string | null
+  payoutAccountLast4?: string | null
+  managementFeePercent: number
+  notes?: string | null
+  portalAccess: boolean
+  propertyCount: number
+  createdAt?: string
+  updatedAt?: string
+}
+
 // Tenant types
 export interface Tenant {
   id: number
@@ -131,6 +165,25 @@ export interface Payment {
   propertyName?: string
 }
 
+// Expense types
+export type ExpenseCategory = 'repairs' | 'taxes' | 'insurance' | 'utilities' | 'hoa' | 'other'
+
+export interface Expense {
+  id: number
+  propertyId: number
+  maintenanceRequestId?: number | null
+  category: ExpenseCategory
+  vendor: string
+  amount: number
+  expenseDate: string
+  description?: string | null
+  receiptUrl?: string | null
+  receiptName?: string | null
+  createdAt?: string
+  updatedAt?: string
+  propertyName?: string
+}
+
 // API Response types
 export interface ApiError {
   error: string
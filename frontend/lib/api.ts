@@ -1,12 +1,14 @@
 import {
   Property,
   PropertyCreate,
+  ListingStatus,
   Tenant,
   TenantCreate,
   Lease,
   LeaseCreate,
   MaintenanceRequest,
   Payment,
+  SitemapEntry,
   ApiError,
 } from '@/data/types'
 
@@ -158,16 +160,27 @@ export async function fetchProperties(filters?: PropertyFilters): Promise<Proper
   return publicFetch<Property[]>(`/api/properties${qs ? `?${qs}` : ''}`)
 }
 
-export async function fetchProperty(id: number): Promise<Property> {
-  return publicFetch<Property>(`/api/properties/${id}`)
+/** Fetches a property by ID or slug; retired slugs redirect to the current one. */
+export async function fetchProperty(idOrSlug: number | string): Promise<Property> {
+  return publicFetch<Property>(`/api/properties/${idOrSlug}`)
+}
+
+/** schema.org Apartment/Offer structured data for a property's detail page. */
+export async function fetchPropertyJsonLd(idOrSlug: number | string): Promise<Record<string, unknown>> {
+  return publicFetch<Record<string, unknown>>(`/api/properties/${idOrSlug}/jsonld`)
+}
+
+export async function fetchSitemapEntries(): Promise<SitemapEntry[]> {
+  return publicFetch<SitemapEntry[]>('/api/sitemap')
 }
 
 // ============================================================================
 // ADMIN PROPERTIES (auth required — all go through authFetch)
 // ============================================================================
 
-export async function fetchAdminProperties(): Promise<Property[]> {
-  return authFetch<Property[]>('/api/admin/properties')
+/** Lists properties; archived ones only when asked for with status. */
+export async function fetchAdminProperties(status?: ListingStatus | 'all'): Promise<Property[]> {
+  return authFetch<Property[]>(`/api/admin/properties${status ? `?status=${status}` : ''}`)
 }
 
 export async function createProperty(data: PropertyCreate): Promise<Property> {